@@ -4,5 +4,5 @@ import (
 	"embed"
 )
 
-//go:embed "configs"
+//go:embed "configs" "migrations"
 var EmbeddedFiles embed.FS
@@ -0,0 +1,32 @@
+DO $$ BEGIN
+	IF to_regtype('user_roles') IS NULL THEN
+	CREATE TYPE user_roles AS ENUM('admin', 'mod', 'registered');
+	END IF;
+END $$;
+
+CREATE TABLE IF NOT EXISTS users (
+    id				   serial PRIMARY KEY,
+    encrypted_password varchar(500) NOT NULL,
+    username 		   varchar(21) NOT NULL UNIQUE,
+    email 			   varchar(55) NOT NULL UNIQUE,
+	user_role     	   user_roles,
+    created_at 		   timestamp,
+    updated_at 		   timestamp
+);
+
+CREATE TABLE IF NOT EXISTS posts (
+    id 				   serial PRIMARY KEY,
+    title 			   varchar(255),
+	body 			   varchar,
+    created_at 		   timestamp,
+    updated_at 		   timestamp
+);
+
+CREATE TABLE IF NOT EXISTS comments (
+    id 				   serial PRIMARY KEY,
+	author 			   varchar references users(username),
+	user_id 		   int references users(id),
+	post_id 		   int references posts(id),
+    text 			   varchar(255),
+    created_at 		   timestamp
+);
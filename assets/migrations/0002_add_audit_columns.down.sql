@@ -0,0 +1,22 @@
+ALTER TABLE comments
+    DROP COLUMN status,
+    DROP COLUMN deleted_at,
+    DROP COLUMN created_by,
+    DROP COLUMN updated_by,
+    DROP COLUMN deleted_by;
+
+ALTER TABLE posts
+    DROP COLUMN status,
+    DROP COLUMN deleted_at,
+    DROP COLUMN created_by,
+    DROP COLUMN updated_by,
+    DROP COLUMN deleted_by;
+
+ALTER TABLE users
+    DROP COLUMN status,
+    DROP COLUMN deleted_at,
+    DROP COLUMN created_by,
+    DROP COLUMN updated_by,
+    DROP COLUMN deleted_by;
+
+DROP TYPE IF EXISTS statuses;
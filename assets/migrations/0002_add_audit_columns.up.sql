@@ -0,0 +1,26 @@
+DO $$ BEGIN
+	IF to_regtype('statuses') IS NULL THEN
+	CREATE TYPE statuses AS ENUM('active', 'passive');
+	END IF;
+END $$;
+
+ALTER TABLE users
+    ADD COLUMN status 		   statuses NOT NULL DEFAULT 'active',
+    ADD COLUMN deleted_at 	   timestamp,
+    ADD COLUMN created_by 	   varchar(21) NOT NULL DEFAULT '',
+    ADD COLUMN updated_by 	   varchar(21) NOT NULL DEFAULT '',
+    ADD COLUMN deleted_by 	   varchar(21) NOT NULL DEFAULT '';
+
+ALTER TABLE posts
+    ADD COLUMN status 		   statuses NOT NULL DEFAULT 'active',
+    ADD COLUMN deleted_at 	   timestamp,
+    ADD COLUMN created_by 	   varchar(21) NOT NULL DEFAULT '',
+    ADD COLUMN updated_by 	   varchar(21) NOT NULL DEFAULT '',
+    ADD COLUMN deleted_by 	   varchar(21) NOT NULL DEFAULT '';
+
+ALTER TABLE comments
+    ADD COLUMN status 		   statuses NOT NULL DEFAULT 'active',
+    ADD COLUMN deleted_at 	   timestamp,
+    ADD COLUMN created_by 	   varchar(21) NOT NULL DEFAULT '',
+    ADD COLUMN updated_by 	   varchar(21) NOT NULL DEFAULT '',
+    ADD COLUMN deleted_by 	   varchar(21) NOT NULL DEFAULT '';
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS comments_deleted_at_idx;
+DROP INDEX IF EXISTS posts_deleted_at_idx;
+DROP INDEX IF EXISTS users_deleted_at_idx;
+
+ALTER TABLE comments
+    DROP CONSTRAINT IF EXISTS comments_author_fkey,
+    DROP CONSTRAINT IF EXISTS comments_user_id_fkey,
+    DROP CONSTRAINT IF EXISTS comments_post_id_fkey;
+
+ALTER TABLE comments
+    ADD CONSTRAINT comments_author_fkey FOREIGN KEY (author) REFERENCES users(username),
+    ADD CONSTRAINT comments_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id),
+    ADD CONSTRAINT comments_post_id_fkey FOREIGN KEY (post_id) REFERENCES posts(id);
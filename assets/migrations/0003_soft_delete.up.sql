@@ -0,0 +1,15 @@
+-- Purging a user or post permanently removes the comments that reference it,
+-- and renaming a user keeps the denormalised comment author in sync.
+ALTER TABLE comments
+    DROP CONSTRAINT IF EXISTS comments_author_fkey,
+    DROP CONSTRAINT IF EXISTS comments_user_id_fkey,
+    DROP CONSTRAINT IF EXISTS comments_post_id_fkey;
+
+ALTER TABLE comments
+    ADD CONSTRAINT comments_author_fkey FOREIGN KEY (author) REFERENCES users(username) ON UPDATE CASCADE ON DELETE CASCADE,
+    ADD CONSTRAINT comments_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    ADD CONSTRAINT comments_post_id_fkey FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS users_deleted_at_idx ON users (deleted_at);
+CREATE INDEX IF NOT EXISTS posts_deleted_at_idx ON posts (deleted_at);
+CREATE INDEX IF NOT EXISTS comments_deleted_at_idx ON comments (deleted_at);
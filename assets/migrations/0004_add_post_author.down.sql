@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS posts_user_id_idx;
+
+ALTER TABLE posts
+    DROP COLUMN user_id;
@@ -0,0 +1,8 @@
+ALTER TABLE posts
+    ADD COLUMN user_id 		   int REFERENCES users(id) ON DELETE SET NULL;
+
+-- Posts created after the audit columns were introduced know their creator.
+UPDATE posts SET user_id = created_by::int
+WHERE created_by ~ '^[0-9]+$' AND EXISTS (SELECT 1 FROM users WHERE users.id = posts.created_by::int);
+
+CREATE INDEX IF NOT EXISTS posts_user_id_idx ON posts (user_id);
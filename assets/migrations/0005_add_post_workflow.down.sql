@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS posts_state_publish_at_idx;
+
+ALTER TABLE posts
+    DROP COLUMN state,
+    DROP COLUMN publish_at;
+
+DROP TYPE IF EXISTS post_states;
@@ -0,0 +1,17 @@
+DO $$ BEGIN
+	IF to_regtype('post_states') IS NULL THEN
+	CREATE TYPE post_states AS ENUM('draft', 'scheduled', 'published', 'archived');
+	END IF;
+END $$;
+
+-- Existing posts were public, so they start out published.
+ALTER TABLE posts
+    ADD COLUMN state 		   post_states NOT NULL DEFAULT 'published',
+    ADD COLUMN publish_at 	   timestamp;
+
+UPDATE posts SET publish_at = created_at;
+
+ALTER TABLE posts
+    ALTER COLUMN state SET DEFAULT 'draft';
+
+CREATE INDEX IF NOT EXISTS posts_state_publish_at_idx ON posts (state, publish_at);
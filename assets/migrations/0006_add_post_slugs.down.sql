@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS post_slugs;
+
+ALTER TABLE posts
+    DROP CONSTRAINT IF EXISTS posts_slug_key,
+    DROP COLUMN slug;
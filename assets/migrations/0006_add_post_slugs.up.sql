@@ -0,0 +1,20 @@
+ALTER TABLE posts
+    ADD COLUMN slug 		   varchar(255);
+
+-- Existing posts get a slug from their title, suffixed with the id to keep it unique.
+UPDATE posts SET slug = COALESCE(
+    NULLIF(trim(BOTH '-' FROM regexp_replace(lower(translate(title, 'çğıİöşüÇĞÖŞÜ', 'cgiiosucgosu')), '[^a-z0-9]+', '-', 'g')), ''),
+    'post') || '-' || id;
+
+ALTER TABLE posts
+    ALTER COLUMN slug SET NOT NULL,
+    ADD CONSTRAINT posts_slug_key UNIQUE (slug);
+
+-- Slugs a post used to have, kept so old links can be redirected.
+CREATE TABLE IF NOT EXISTS post_slugs (
+    slug 			   varchar(255) PRIMARY KEY,
+    post_id 		   int NOT NULL references posts(id) ON DELETE CASCADE,
+    created_at 		   timestamp
+);
+
+CREATE INDEX IF NOT EXISTS post_slugs_post_id_idx ON post_slugs (post_id);
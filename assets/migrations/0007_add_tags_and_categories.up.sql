@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS tags (
+    id 				   serial PRIMARY KEY,
+    name 			   varchar(50) NOT NULL,
+    slug 			   varchar(60) NOT NULL UNIQUE,
+    created_at 		   timestamp,
+    updated_at 		   timestamp,
+    created_by 		   varchar(21) NOT NULL DEFAULT '',
+    updated_by 		   varchar(21) NOT NULL DEFAULT ''
+);
+
+-- Deleting a category lifts its children one level up.
+CREATE TABLE IF NOT EXISTS categories (
+    id 				   serial PRIMARY KEY,
+    parent_id 		   int references categories(id) ON DELETE SET NULL,
+    name 			   varchar(50) NOT NULL,
+    slug 			   varchar(60) NOT NULL UNIQUE,
+    created_at 		   timestamp,
+    updated_at 		   timestamp,
+    created_by 		   varchar(21) NOT NULL DEFAULT '',
+    updated_by 		   varchar(21) NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS categories_parent_id_idx ON categories (parent_id);
+
+CREATE TABLE IF NOT EXISTS post_tags (
+    post_id 		   int NOT NULL references posts(id) ON DELETE CASCADE,
+    tag_id 			   int NOT NULL references tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (post_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS post_tags_tag_id_idx ON post_tags (tag_id);
+
+CREATE TABLE IF NOT EXISTS post_categories (
+    post_id 		   int NOT NULL references posts(id) ON DELETE CASCADE,
+    category_id 	   int NOT NULL references categories(id) ON DELETE CASCADE,
+    PRIMARY KEY (post_id, category_id)
+);
+
+CREATE INDEX IF NOT EXISTS post_categories_category_id_idx ON post_categories (category_id);
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS comments_search_vector_idx;
+DROP INDEX IF EXISTS posts_search_vector_idx;
+
+ALTER TABLE comments
+    DROP COLUMN search_vector,
+    DROP COLUMN search_config;
+
+ALTER TABLE posts
+    DROP COLUMN search_vector,
+    DROP COLUMN search_config;
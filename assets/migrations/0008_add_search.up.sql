@@ -0,0 +1,17 @@
+-- search_config is the text search configuration the vectors are built with;
+-- the app moves every row to its configured language on startup.
+ALTER TABLE posts
+    ADD COLUMN search_config   regconfig NOT NULL DEFAULT 'simple',
+    ADD COLUMN search_vector   tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector(search_config, coalesce(title, '')), 'A') ||
+        setweight(to_tsvector(search_config, coalesce(body, '')), 'B')
+    ) STORED;
+
+ALTER TABLE comments
+    ADD COLUMN search_config   regconfig NOT NULL DEFAULT 'simple',
+    ADD COLUMN search_vector   tsvector GENERATED ALWAYS AS (
+        to_tsvector(search_config, coalesce(text, ''))
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS posts_search_vector_idx ON posts USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS comments_search_vector_idx ON comments USING GIN (search_vector);
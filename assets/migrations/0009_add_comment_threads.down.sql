@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS comments_parent_id_idx;
+
+ALTER TABLE comments
+    DROP COLUMN depth,
+    DROP COLUMN parent_id;
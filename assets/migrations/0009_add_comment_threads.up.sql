@@ -0,0 +1,6 @@
+-- Replies point at their parent comment; depth is 0 for top level comments.
+ALTER TABLE comments
+    ADD COLUMN parent_id 	   int references comments(id) ON DELETE CASCADE,
+    ADD COLUMN depth 		   int NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS comments_parent_id_idx ON comments (parent_id);
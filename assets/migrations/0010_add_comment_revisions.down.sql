@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS comment_revisions;
+
+ALTER TABLE comments
+    DROP COLUMN edited,
+    DROP COLUMN updated_at;
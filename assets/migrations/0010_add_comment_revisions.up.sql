@@ -0,0 +1,20 @@
+ALTER TABLE comments
+    ADD COLUMN updated_at 	   timestamp,
+    ADD COLUMN edited 		   boolean NOT NULL DEFAULT false;
+
+UPDATE comments SET updated_at = COALESCE(created_at, now());
+
+ALTER TABLE comments
+    ALTER COLUMN updated_at SET DEFAULT now(),
+    ALTER COLUMN updated_at SET NOT NULL;
+
+-- Every edit stores the text the comment had before it.
+CREATE TABLE IF NOT EXISTS comment_revisions (
+    id 				   serial PRIMARY KEY,
+    comment_id 		   int NOT NULL references comments(id) ON DELETE CASCADE,
+    text 			   varchar(255) NOT NULL,
+    created_at 		   timestamp NOT NULL,
+    created_by 		   varchar(21) NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS comment_revisions_comment_id_idx ON comment_revisions (comment_id);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS comments_state_idx;
+
+ALTER TABLE comments
+    DROP COLUMN moderated_by,
+    DROP COLUMN moderated_at,
+    DROP COLUMN state;
+
+DROP TYPE IF EXISTS comment_states;
@@ -0,0 +1,16 @@
+DO $$ BEGIN
+	IF to_regtype('comment_states') IS NULL THEN
+	CREATE TYPE comment_states AS ENUM('pending', 'approved', 'rejected', 'spam');
+	END IF;
+END $$;
+
+-- Existing comments were live, so they start out approved.
+ALTER TABLE comments
+    ADD COLUMN state 		   comment_states NOT NULL DEFAULT 'approved',
+    ADD COLUMN moderated_at    timestamp,
+    ADD COLUMN moderated_by    varchar(21) NOT NULL DEFAULT '';
+
+ALTER TABLE comments
+    ALTER COLUMN state SET DEFAULT 'pending';
+
+CREATE INDEX IF NOT EXISTS comments_state_idx ON comments (state);
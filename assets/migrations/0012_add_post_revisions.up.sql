@@ -0,0 +1,11 @@
+-- Every edit of a post stores the title and body it had before.
+CREATE TABLE IF NOT EXISTS post_revisions (
+    id 				   serial PRIMARY KEY,
+    post_id 		   int NOT NULL references posts(id) ON DELETE CASCADE,
+    number 			   int NOT NULL,
+    title 			   varchar(255) NOT NULL,
+    body 			   varchar NOT NULL DEFAULT '',
+    created_at 		   timestamp NOT NULL,
+    created_by 		   varchar(21) NOT NULL DEFAULT '',
+    UNIQUE (post_id, number)
+);
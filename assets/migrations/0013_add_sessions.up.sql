@@ -0,0 +1,22 @@
+-- A session is a refresh token family: every token rotated from one login.
+CREATE TABLE IF NOT EXISTS sessions (
+    id 				   varchar(32) PRIMARY KEY,
+    user_id 		   int NOT NULL references users(id) ON DELETE CASCADE,
+    created_at 		   timestamp NOT NULL,
+    revoked_at 		   timestamp
+);
+
+CREATE INDEX IF NOT EXISTS sessions_user_id_idx ON sessions (user_id);
+
+-- Refresh tokens are opaque; only their sha256 is stored.
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id 				   serial PRIMARY KEY,
+    session_id 		   varchar(32) NOT NULL references sessions(id) ON DELETE CASCADE,
+    user_id 		   int NOT NULL references users(id) ON DELETE CASCADE,
+    token_hash 		   varchar(64) NOT NULL UNIQUE,
+    created_at 		   timestamp NOT NULL,
+    expires_at 		   timestamp NOT NULL,
+    used_at 		   timestamp
+);
+
+CREATE INDEX IF NOT EXISTS refresh_tokens_session_id_idx ON refresh_tokens (session_id);
@@ -0,0 +1,13 @@
+-- Single use tokens mailed to users, such as password reset links. Only their
+-- sha256 is stored.
+CREATE TABLE IF NOT EXISTS user_tokens (
+    id 				   serial PRIMARY KEY,
+    user_id 		   int NOT NULL references users(id) ON DELETE CASCADE,
+    purpose 		   varchar(32) NOT NULL,
+    token_hash 		   varchar(64) NOT NULL UNIQUE,
+    created_at 		   timestamp NOT NULL,
+    expires_at 		   timestamp NOT NULL,
+    used_at 		   timestamp
+);
+
+CREATE INDEX IF NOT EXISTS user_tokens_user_id_idx ON user_tokens (user_id, purpose);
@@ -0,0 +1,11 @@
+-- Failed logins counted per account (the email tried) and per client IP, so
+-- both can be locked out for a while.
+CREATE TABLE IF NOT EXISTS login_attempts (
+    id 				   serial PRIMARY KEY,
+    kind 			   varchar(16) NOT NULL,
+    key 			   varchar(255) NOT NULL,
+    failures 		   int NOT NULL DEFAULT 0,
+    last_failed_at 	   timestamp NOT NULL,
+    locked_until 	   timestamp,
+    UNIQUE (kind, key)
+);
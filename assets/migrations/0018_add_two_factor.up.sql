@@ -0,0 +1,18 @@
+-- TOTP two-factor authentication. The secret is kept as is, since codes are
+-- computed from it; it only counts once totp_enabled_at is set.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_secret text NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_enabled_at timestamp;
+-- totp_last_step is the time step of the last accepted code, so it can not be replayed.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_last_step bigint NOT NULL DEFAULT 0;
+
+-- One time recovery codes for users who lost their authenticator. Only their
+-- sha256 is stored.
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    id 				   serial PRIMARY KEY,
+    user_id 		   int NOT NULL references users(id) ON DELETE CASCADE,
+    code_hash 		   varchar(64) NOT NULL,
+    created_at 		   timestamp NOT NULL,
+    used_at 		   timestamp
+);
+
+CREATE INDEX IF NOT EXISTS recovery_codes_user_id_idx ON recovery_codes (user_id);
@@ -0,0 +1,16 @@
+-- Personal API keys. Only the sha256 of a key is stored; prefix is its start,
+-- kept so users can tell their keys apart.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id 				   serial PRIMARY KEY,
+    user_id 		   int NOT NULL references users(id) ON DELETE CASCADE,
+    name 			   varchar(64) NOT NULL,
+    prefix 			   varchar(16) NOT NULL,
+    key_hash 		   varchar(64) NOT NULL UNIQUE,
+    scopes 			   text[] NOT NULL DEFAULT '{}',
+    created_at 		   timestamp NOT NULL,
+    expires_at 		   timestamp NOT NULL,
+    last_used_at 	   timestamp,
+    revoked_at 		   timestamp
+);
+
+CREATE INDEX IF NOT EXISTS api_keys_user_id_idx ON api_keys (user_id);
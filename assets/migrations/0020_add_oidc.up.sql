@@ -0,0 +1,26 @@
+-- Identities users have at OpenID Connect providers, by the subject the
+-- provider knows them by.
+CREATE TABLE IF NOT EXISTS user_identities (
+    id 				   serial PRIMARY KEY,
+    user_id 		   int NOT NULL references users(id) ON DELETE CASCADE,
+    provider 		   varchar(32) NOT NULL,
+    subject 		   varchar(255) NOT NULL,
+    email 			   varchar(255) NOT NULL DEFAULT '',
+    created_at 		   timestamp NOT NULL,
+    UNIQUE (provider, subject)
+);
+
+CREATE INDEX IF NOT EXISTS user_identities_user_id_idx ON user_identities (user_id);
+
+-- Sign ins waiting for the provider to send the user back. Only the sha256 of
+-- the state is stored; nonce and code_verifier finish the sign in.
+CREATE TABLE IF NOT EXISTS oidc_logins (
+    id 				   serial PRIMARY KEY,
+    state_hash 		   varchar(64) NOT NULL UNIQUE,
+    provider 		   varchar(32) NOT NULL,
+    nonce 			   varchar(64) NOT NULL,
+    code_verifier 	   varchar(128) NOT NULL,
+    created_at 		   timestamp NOT NULL,
+    expires_at 		   timestamp NOT NULL,
+    used_at 		   timestamp
+);
@@ -0,0 +1,11 @@
+DO $$ BEGIN
+	IF to_regtype('user_roles') IS NULL THEN
+	CREATE TYPE user_roles AS ENUM('admin', 'mod', 'registered');
+	END IF;
+END $$;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_user_role_fkey;
+UPDATE users SET user_role = 'registered' WHERE user_role NOT IN ('admin', 'mod', 'registered');
+ALTER TABLE users ALTER COLUMN user_role TYPE user_roles USING user_role::user_roles;
+
+DROP TABLE IF EXISTS roles;
@@ -0,0 +1,21 @@
+-- Roles are named permission sets. admin, mod and registered are built in;
+-- admins can change what mod and registered may do and add roles of their own.
+CREATE TABLE IF NOT EXISTS roles (
+    name 			   varchar(32) PRIMARY KEY,
+    permissions 	   text[] NOT NULL DEFAULT '{}',
+    created_at 		   timestamp NOT NULL,
+    updated_at 		   timestamp NOT NULL,
+    created_by 		   varchar(21) NOT NULL DEFAULT '',
+    updated_by 		   varchar(21) NOT NULL DEFAULT ''
+);
+
+INSERT INTO roles (name, permissions, created_at, updated_at) VALUES
+    ('admin', '{post:create,post:update,post:update-any,post:read-unpublished,post:delete,comment:create,comment:moderate,comment:delete,tag:manage,category:manage,user:read,user:manage,role:manage}', now(), now()),
+    ('mod', '{post:create,post:update,post:read-unpublished,comment:create,comment:moderate,tag:manage,category:manage,user:read}', now(), now()),
+    ('registered', '{comment:create}', now(), now())
+ON CONFLICT (name) DO NOTHING;
+
+ALTER TABLE users ALTER COLUMN user_role TYPE varchar(32) USING user_role::text;
+ALTER TABLE users ADD CONSTRAINT users_user_role_fkey FOREIGN KEY (user_role) REFERENCES roles (name);
+
+DROP TYPE IF EXISTS user_roles;
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/database"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/config"
+)
+
+const usage = `usage: migrate <command>
+
+commands:
+  up        apply every pending migration
+  down [n]  roll back the last n migrations (default 1)
+  status    list applied and pending migrations`
+
+func main() {
+	flag.Usage = func() { fmt.Println(usage) }
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		flag.Usage()
+
+		return
+	}
+
+	// Initialize application configs.
+	cfg := config.Init()
+
+	// Create a Postgres store without running InitDB, which would migrate up.
+	store := database.NewPostgresStore(database.WithUser(cfg.DB.User), database.WithName(cfg.DB.Name), database.WithPassword(cfg.DB.Password))
+
+	m, err := database.NewMigrator(store.GetInstance())
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	ctx := context.Background()
+
+	switch flag.Arg(0) {
+	case "up":
+		err = m.Up(ctx)
+	case "down":
+		n := 1
+
+		if flag.NArg() > 1 {
+			n, err = strconv.Atoi(flag.Arg(1))
+			if err != nil || n < 1 {
+				log.Fatalf("invalid step count: %s", flag.Arg(1))
+			}
+		}
+
+		err = m.Down(ctx, n)
+	case "status":
+		applied, aErr := m.Applied(ctx)
+		if aErr != nil {
+			log.Fatal(aErr.Error())
+		}
+
+		for _, a := range applied {
+			fmt.Printf("applied  %04d_%s  %s\n", a.Version, a.Name, a.AppliedAt.Format("2006-01-02 15:04:05"))
+		}
+
+		for _, p := range m.Pending(applied) {
+			fmt.Printf("pending  %04d_%s\n", p.Version, p.Name)
+		}
+	default:
+		flag.Usage()
+
+		return
+	}
+
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+}
@@ -1,18 +1,30 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 
+	postgresadapter "github.com/MehmetTalhaSeker/mts-blog-api/internal/adapter/postgres"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/database"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/rbac"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/config"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/jwtauth"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/mailer"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/oidc"
 )
 
 type application struct {
 	config *config.Config
 	db     *sql.DB
 	rbac   rbac.RBAC
+	signer *jwtauth.Signer
+	mailer mailer.Mailer
 }
 
 func main() {
@@ -25,15 +37,135 @@ func main() {
 	// Initialize the Postgres store.
 	store.InitDB()
 
-	// Initialize Role based access control.
-	rb := rbac.New()
+	// Build the search vectors in the configured language.
+	if err := postgresadapter.NewSearchRepository(store.GetInstance(), cfg.Search.Language).Reindex(); err != nil {
+		log.Fatal(err)
+	}
+
+	// Initialize Role based access control over the roles stored in the database.
+	rb := rbac.New(
+		rbac.WithStaffTwoFactor(cfg.Auth.RequireTwoFactor),
+		rbac.WithRoles(postgresadapter.NewRoleRepository(store.GetInstance())),
+	)
+
+	// Load the keys access tokens are signed with.
+	signer, err := newSigner(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Set up outgoing mail.
+	m, err := newMailer(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	app := &application{
 		config: cfg,
 		db:     store.GetInstance(),
 		rbac:   rb,
+		signer: signer,
+		mailer: m,
 	}
 
+	// Stop the server and the background jobs on SIGINT or SIGTERM.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Publish scheduled posts in the background.
+	var jobs sync.WaitGroup
+
+	jobs.Add(1)
+
+	go func() {
+		defer jobs.Done()
+
+		app.publishScheduledPosts(ctx)
+	}()
+
 	log.Printf("starting server on %s:%s (version %s)", cfg.Rest.Host, cfg.Rest.Port, cfg.Rest.Version)
-	app.start()
+	app.start(ctx)
+
+	// Close the database only once the requests and the jobs are done with it.
+	jobs.Wait()
+
+	if err := app.db.Close(); err != nil {
+		log.Print(err)
+	}
+}
+
+// newSigner builds the token signer from the configured keys, falling back to
+// an HS256 key over the JWT secret.
+func newSigner(cfg *config.Config) (*jwtauth.Signer, error) {
+	var keys []*jwtauth.Key
+
+	for _, kc := range cfg.JWT.Keys {
+		material := []byte(kc.Secret)
+
+		if kc.File != "" {
+			b, err := os.ReadFile(kc.File)
+			if err != nil {
+				return nil, err
+			}
+
+			material = b
+		}
+
+		k, err := jwtauth.NewKey(kc.ID, kc.Algorithm, material)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, k)
+	}
+
+	if len(keys) == 0 {
+		k, err := jwtauth.NewKey("default", jwtauth.HS256, []byte(cfg.JWT.Secret))
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, k)
+	}
+
+	return jwtauth.New(cfg.JWT.Issuer, cfg.JWT.Audience, cfg.JWT.Exp, keys...)
+}
+
+// newMailer returns the configured mailer; the log driver writes mails to a
+// file, or stdout, instead of sending them.
+func newMailer(cfg *config.Config) (mailer.Mailer, error) {
+	switch cfg.Mail.Driver {
+	case "smtp":
+		return mailer.NewSMTP(cfg.Mail.Host, cfg.Mail.Port, cfg.Mail.Username, cfg.Mail.Password, cfg.Mail.From), nil
+	case "log", "":
+		if cfg.Mail.File == "" {
+			return mailer.NewLog(os.Stdout, cfg.Mail.From), nil
+		}
+
+		f, err := os.OpenFile(cfg.Mail.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, err
+		}
+
+		return mailer.NewLog(f, cfg.Mail.From), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", cfg.Mail.Driver)
+	}
+}
+
+// oidcProviders returns the configured OpenID Connect providers by name.
+func oidcProviders(cfg *config.Config) map[string]oidc.Config {
+	providers := make(map[string]oidc.Config, len(cfg.OIDC.Providers))
+
+	for name, p := range cfg.OIDC.Providers {
+		providers[name] = oidc.Config{
+			Issuer:       p.Issuer,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			RedirectURL:  p.RedirectURL,
+			Scopes:       p.Scopes,
+		}
+	}
+
+	return providers
 }
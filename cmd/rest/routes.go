@@ -1,23 +1,49 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 
 	postgresadapter "github.com/MehmetTalhaSeker/mts-blog-api/internal/adapter/postgres"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/throttle"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/validatorutils"
 	"github.com/MehmetTalhaSeker/mts-blog-api/pkg/auth"
+	"github.com/MehmetTalhaSeker/mts-blog-api/pkg/category"
 	"github.com/MehmetTalhaSeker/mts-blog-api/pkg/comment"
+	"github.com/MehmetTalhaSeker/mts-blog-api/pkg/moderation"
 	"github.com/MehmetTalhaSeker/mts-blog-api/pkg/post"
+	"github.com/MehmetTalhaSeker/mts-blog-api/pkg/role"
+	"github.com/MehmetTalhaSeker/mts-blog-api/pkg/search"
+	"github.com/MehmetTalhaSeker/mts-blog-api/pkg/tag"
 	"github.com/MehmetTalhaSeker/mts-blog-api/pkg/user"
 )
 
-func (app *application) start() {
+// shutdownTimeout is how long in-flight requests get to finish on shutdown.
+const shutdownTimeout = 10 * time.Second
+
+// start serves the API until ctx is cancelled, then shuts the server down and
+// returns once the in-flight requests have finished or shutdownTimeout passed.
+func (app *application) start(ctx context.Context) {
 	e := echo.New()
+
+	ipExtractor, err := newIPExtractor(app.config.Rest.TrustedProxies)
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+
+	// Lockouts are keyed on the client IP, so it must not come from headers
+	// clients can set themselves.
+	e.IPExtractor = ipExtractor
+
 	e.Use(
 		// middleware.Recover(), // Recover from all panics to always have your server up
 		middleware.Logger(),    // Log everything to stdout
@@ -38,40 +64,175 @@ func (app *application) start() {
 	ur := postgresadapter.NewUserRepository(app.db)
 	pr := postgresadapter.NewPostRepository(app.db)
 	cr := postgresadapter.NewCommentRepository(app.db)
+	tr := postgresadapter.NewTagRepository(app.db)
+	car := postgresadapter.NewCategoryRepository(app.db)
+	sr := postgresadapter.NewSessionRepository(app.db)
+	utr := postgresadapter.NewUserTokenRepository(app.db)
+	lar := postgresadapter.NewLoginAttemptRepository(app.db)
+	rcr := postgresadapter.NewRecoveryCodeRepository(app.db)
+	akr := postgresadapter.NewAPIKeyRepository(app.db)
+	ir := postgresadapter.NewIdentityRepository(app.db)
+	rr := postgresadapter.NewRoleRepository(app.db)
+	lockout := app.config.Auth.Lockout
+	accountLockout := throttle.Policy{Threshold: lockout.Threshold, Base: lockout.Base, Max: lockout.Max, Window: lockout.Window}
+	ipLockout := throttle.Policy{Threshold: lockout.IPThreshold, Base: lockout.Base, Max: lockout.Max, Window: lockout.Window}
+	authn := auth.NewAuthenticator(app.signer, ur, sr, akr)
 
 	// auth router initialization.
 	authRouter := &auth.Router{
-		RouterGroup:    routerGroup,
-		UserRepository: ur,
+		Authenticate:           authn.Authenticate(),
+		RBAC:                   app.rbac,
+		RouterGroup:            routerGroup,
+		WellKnownGroup:         e.Group("/.well-known"),
+		UserRepository:         ur,
+		SessionRepository:      sr,
+		UserTokenRepository:    utr,
+		LoginAttemptRepository: lar,
+		RecoveryCodeRepository: rcr,
+		IdentityRepository:     ir,
+		APIKeyRepository:       akr,
+		Signer:                 app.signer,
+		Mailer:                 app.mailer,
+		Config: auth.Config{
+			RefreshTTL:     app.config.JWT.RefreshTTL,
+			ResetTTL:       app.config.Auth.ResetTTL,
+			ResetURL:       app.config.Auth.ResetURL,
+			VerifyTTL:      app.config.Auth.VerifyTTL,
+			VerifyURL:      app.config.Auth.VerifyURL,
+			ResendCooldown: app.config.Auth.ResendCooldown,
+			AccountLockout: accountLockout,
+			IPLockout:      ipLockout,
+			ChallengeTTL:   app.config.Auth.ChallengeTTL,
+			TOTPIssuer:     app.config.Auth.TOTPIssuer,
+			OIDCProviders:  oidcProviders(app.config),
+			OIDCLoginTTL:   app.config.OIDC.LoginTTL,
+		},
 	}
 	authRouter.New()
 
 	// user router initialization.
 	userRouter := &user.Router{
-		Authenticate:   app.authenticate(),
-		RBAC:           app.rbac,
-		RouterGroup:    routerGroup,
-		UserRepository: ur,
+		Authenticate:      authn.Authenticate(),
+		RBAC:              app.rbac,
+		RouterGroup:       routerGroup,
+		UserRepository:    ur,
+		SessionRepository: sr,
+		APIKeyRepository:  akr,
+		Verifier:          auth.NewVerifier(utr, app.mailer, app.config.Auth.VerifyTTL, app.config.Auth.VerifyURL),
+		Lockout:           auth.NewLockout(lar, accountLockout, ipLockout),
 	}
 	userRouter.New()
 
 	// post router initialization.
 	postRouter := &post.Router{
-		Authenticate:   app.authenticate(),
-		RBAC:           app.rbac,
-		RouterGroup:    routerGroup,
-		PostRepository: pr,
+		Authenticate:         authn.Authenticate(),
+		OptionalAuthenticate: authn.OptionalAuthenticate(),
+		RBAC:                 app.rbac,
+		RouterGroup:          routerGroup,
+		PostRepository:       pr,
+		TagRepository:        tr,
+		CategoryRepository:   car,
 	}
 	postRouter.New()
 
+	// tag router initialization.
+	tagRouter := &tag.Router{
+		Authenticate:  authn.Authenticate(),
+		RBAC:          app.rbac,
+		RouterGroup:   routerGroup,
+		TagRepository: tr,
+	}
+	tagRouter.New()
+
+	// category router initialization.
+	categoryRouter := &category.Router{
+		Authenticate:       authn.Authenticate(),
+		RBAC:               app.rbac,
+		RouterGroup:        routerGroup,
+		CategoryRepository: car,
+	}
+	categoryRouter.New()
+
 	// comment router initialization.
 	commentRouter := &comment.Router{
-		Authenticate:      app.authenticate(),
+		Authenticate:         authn.Authenticate(),
+		OptionalAuthenticate: authn.OptionalAuthenticate(),
+		RBAC:                 app.rbac,
+		RouterGroup:          routerGroup,
+		CommentRepository:    cr,
+		PostRepository:       pr,
+		MaxDepth:             app.config.Comment.MaxDepth,
+		Moderation:           types.ModerationPolicy(app.config.Comment.Moderation),
+		TrustAfter:           app.config.Comment.TrustAfter,
+		RequireVerified:      app.config.Auth.RequireVerified,
+	}
+	commentRouter.New()
+
+	// moderation router initialization.
+	moderationRouter := &moderation.Router{
+		Authenticate:      authn.Authenticate(),
 		RBAC:              app.rbac,
 		RouterGroup:       routerGroup,
 		CommentRepository: cr,
 	}
-	commentRouter.New()
+	moderationRouter.New()
+
+	// role router initialization.
+	roleRouter := &role.Router{
+		Authenticate:   authn.Authenticate(),
+		RBAC:           app.rbac,
+		RouterGroup:    routerGroup,
+		RoleRepository: rr,
+	}
+	roleRouter.New()
+
+	// search router initialization.
+	searchRouter := &search.Router{
+		RouterGroup:      routerGroup,
+		SearchRepository: postgresadapter.NewSearchRepository(app.db, app.config.Search.Language),
+	}
+	searchRouter.New()
+
+	// Start returns as soon as Shutdown begins, so wait for it to drain the requests.
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		if err := e.Shutdown(shutdownCtx); err != nil {
+			e.Logger.Error(err)
+		}
+	}()
+
+	if err := e.Start(fmt.Sprintf(":%s", app.config.Rest.Port)); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		e.Logger.Fatal(err)
+	}
+
+	<-done
+}
+
+// newIPExtractor reads the client IP from X-Forwarded-For on requests sent by
+// one of the proxies, given as CIDRs, and takes the peer address otherwise.
+func newIPExtractor(proxies []string) (echo.IPExtractor, error) {
+	if len(proxies) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+
+	opts := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+
+	for _, p := range proxies {
+		_, ipNet, err := net.ParseCIDR(p)
+		if err != nil {
+			return nil, fmt.Errorf("trusted proxy %q: %w", p, err)
+		}
+
+		opts = append(opts, echo.TrustIPRange(ipNet))
+	}
 
-	e.Logger.Fatal(e.Start(fmt.Sprintf(":%s", app.config.Rest.Port)))
+	return echo.ExtractIPFromXFFHeader(opts...), nil
 }
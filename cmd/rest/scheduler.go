@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	postgresadapter "github.com/MehmetTalhaSeker/mts-blog-api/internal/adapter/postgres"
+)
+
+const defaultSchedulerInterval = time.Minute
+
+// publishScheduledPosts flips scheduled posts to published once their publish
+// date has passed, checking every interval until ctx is cancelled.
+func (app *application) publishScheduledPosts(ctx context.Context) {
+	interval := app.config.Scheduler.Interval
+	if interval <= 0 {
+		interval = defaultSchedulerInterval
+	}
+
+	pr := postgresadapter.NewPostRepository(app.db)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := pr.PublishDue(time.Now())
+			if err != nil {
+				log.Printf("scheduler: publishing due posts failed: %v", err)
+
+				continue
+			}
+
+			if n > 0 {
+				log.Printf("scheduler: published %d scheduled post(s)", n)
+			}
+		}
+	}
+}
@@ -2,9 +2,12 @@ package e2e_test
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
@@ -15,6 +18,9 @@ import (
 	"github.com/MehmetTalhaSeker/mts-blog-api/e2e"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/jwtauth"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/oidc"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/totp"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/apputils"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
@@ -231,7 +237,14 @@ var _ = Describe("authentication", Ordered, func() {
 				it:       "should fail",
 				json:     `{ "email": "samil@samilov.com", "password": "12341234" }`,
 				wantCode: http.StatusBadRequest,
-				wantErr:  errorutils.New(errorutils.ErrEmailNotFound, nil),
+				wantErr:  errorutils.New(errorutils.ErrLoginFailed, nil),
+			},
+			{
+				when:     "wrong password",
+				it:       "should fail like a non existing user",
+				json:     fmt.Sprintf(`{ "email": "%s", "password": "43214321" }`, user.Email),
+				wantCode: http.StatusBadRequest,
+				wantErr:  errorutils.New(errorutils.ErrLoginFailed, nil),
 			},
 		}
 
@@ -276,4 +289,670 @@ var _ = Describe("authentication", Ordered, func() {
 			})
 		}
 	})
+
+	Context("sessions", func() {
+		login := func() *dto.WithTokenResponse {
+			code, body, _, err := e2e.Post(ctx, "/auth/login", []byte(fmt.Sprintf(`{ "email": "%s", "password": "12341234" }`, user.Email)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+
+			got := new(dto.WithTokenResponse)
+			Expect(json.Unmarshal(body, got)).To(Succeed())
+			Expect(got.RefreshToken).ToNot(BeEmpty())
+
+			return got
+		}
+
+		refresh := func(token string) (int, *dto.WithTokenResponse) {
+			code, body, _, err := e2e.Post(ctx, "/auth/refresh", []byte(fmt.Sprintf(`{ "refreshToken": "%s" }`, token)))
+			Expect(err).ToNot(HaveOccurred())
+
+			got := new(dto.WithTokenResponse)
+			if code == http.StatusOK {
+				Expect(json.Unmarshal(body, got)).To(Succeed())
+			}
+
+			return code, got
+		}
+
+		// me calls an authenticated route with the access token.
+		me := func(token string) (int, *errorutils.APIError) {
+			code, body, _, err := e2e.Get(ctx, "/users/me", map[string]string{"Authorization": "Bearer " + token})
+			Expect(err).ToNot(HaveOccurred())
+
+			got := new(errorutils.APIError)
+			if code != http.StatusOK {
+				Expect(json.Unmarshal(body, got)).To(Succeed())
+			}
+
+			return code, got
+		}
+
+		expectRejected := func(token string, wantErr error) {
+			code, got := me(token)
+			Expect(code).To(Equal(http.StatusUnauthorized))
+
+			if diff := cmp.Diff(errorutils.New(wantErr, nil), got); diff != "" {
+				Expect(diff).To(BeEmpty())
+			}
+		}
+
+		It("should rotate the refresh token", func() {
+			first := login()
+
+			code, second := refresh(first.RefreshToken)
+			Expect(code).To(Equal(http.StatusOK))
+			Expect(second.Token).ToNot(BeEmpty())
+			Expect(second.RefreshToken).ToNot(Equal(first.RefreshToken))
+		})
+
+		It("should revoke the session when a refresh token is reused", func() {
+			first := login()
+
+			code, second := refresh(first.RefreshToken)
+			Expect(code).To(Equal(http.StatusOK))
+
+			code, _ = refresh(first.RefreshToken)
+			Expect(code).To(Equal(http.StatusUnauthorized))
+
+			code, _ = refresh(second.RefreshToken)
+			Expect(code).To(Equal(http.StatusUnauthorized))
+		})
+
+		It("should fail with an unknown refresh token", func() {
+			code, _ := refresh("unknown")
+			Expect(code).To(Equal(http.StatusUnauthorized))
+		})
+
+		It("should revoke the session on logout", func() {
+			first := login()
+			second := login()
+
+			code, _ := me(first.Token)
+			Expect(code).To(Equal(http.StatusOK))
+
+			code, _, _, err := e2e.Post(ctx, "/auth/logout", []byte(fmt.Sprintf(`{ "refreshToken": "%s" }`, first.RefreshToken)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusNoContent))
+
+			code, _ = refresh(first.RefreshToken)
+			Expect(code).To(Equal(http.StatusUnauthorized))
+
+			// The access token of the session dies with it, other sessions live on.
+			expectRejected(first.Token, errorutils.ErrRevokedToken)
+
+			code, _ = me(second.Token)
+			Expect(code).To(Equal(http.StatusOK))
+		})
+
+		It("should revoke every session on logout-all", func() {
+			first := login()
+			second := login()
+
+			code, _, _, err := e2e.Post(ctx, "/auth/logout-all", nil, map[string]string{"Authorization": "Bearer " + first.Token})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusNoContent))
+
+			code, _ = refresh(first.RefreshToken)
+			Expect(code).To(Equal(http.StatusUnauthorized))
+
+			code, _ = refresh(second.RefreshToken)
+			Expect(code).To(Equal(http.StatusUnauthorized))
+
+			expectRejected(first.Token, errorutils.ErrRevokedToken)
+			expectRejected(second.Token, errorutils.ErrRevokedToken)
+		})
+
+		It("should stop the access tokens of a suspended user", func() {
+			first := login()
+
+			code, _ := me(first.Token)
+			Expect(code).To(Equal(http.StatusOK))
+
+			e2e.AuthMidUser(e, e2e.CreateUserModel(58, types.Admin))
+			defer e2e.ClearAuthMidUser(e)
+
+			code, _, _, err := e2e.Put(ctx, fmt.Sprintf("/users/%d/status", user.ID), []byte(`{ "status": "passive", "reason": "spam" }`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+
+			expectRejected(first.Token, errorutils.ErrUserDisabled)
+		})
+	})
+
+	Context("tokens", func() {
+		It("should issue tokens with registered claims and a kid", func() {
+			code, body, _, err := e2e.Post(ctx, "/auth/login", []byte(fmt.Sprintf(`{ "email": "%s", "password": "12341234" }`, user.Email)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+
+			got := new(dto.WithTokenResponse)
+			Expect(json.Unmarshal(body, got)).To(Succeed())
+
+			claims, err := signer.Verify(got.Token)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*claims).To(Equal(got.Claims))
+
+			header, err := base64.RawURLEncoding.DecodeString(strings.Split(got.Token, ".")[0])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(header)).To(ContainSubstring(`"kid":"e2e"`))
+
+			payload, err := base64.RawURLEncoding.DecodeString(strings.Split(got.Token, ".")[1])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(payload)).To(And(
+				ContainSubstring(`"iss":"mts-blog-api"`),
+				ContainSubstring(`"aud":"mts-blog-api"`),
+				ContainSubstring(`"exp":`),
+				ContainSubstring(`"iat":`),
+			))
+		})
+
+		It("should publish the public keys", func() {
+			resp, err := http.Get("http://localhost:8080/.well-known/jwks.json")
+			Expect(err).ToNot(HaveOccurred())
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			got := new(jwtauth.JWKS)
+			Expect(json.NewDecoder(resp.Body).Decode(got)).To(Succeed())
+			Expect(got.Keys).To(HaveLen(1))
+			Expect(got.Keys[0].Kid).To(Equal("e2e"))
+			Expect(got.Keys[0].Alg).To(Equal(jwtauth.EdDSA))
+		})
+	})
+
+	Context("password reset", func() {
+		forgot := func(email string) {
+			code, _, _, err := e2e.Post(ctx, "/auth/password/forgot", []byte(fmt.Sprintf(`{ "email": "%s" }`, email)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusAccepted))
+		}
+
+		reset := func(token, password string) int {
+			code, _, _, err := e2e.Post(ctx, "/auth/password/reset", []byte(fmt.Sprintf(`{ "token": "%s", "password": "%s" }`, token, password)))
+			Expect(err).ToNot(HaveOccurred())
+
+			return code
+		}
+
+		login := func(password string) int {
+			code, _, _, err := e2e.Post(ctx, "/auth/login", []byte(fmt.Sprintf(`{ "email": "%s", "password": "%s" }`, user.Email, password)))
+			Expect(err).ToNot(HaveOccurred())
+
+			return code
+		}
+
+		BeforeEach(func() {
+			outbox.Reset()
+		})
+
+		It("should reset the password with the mailed token once", func() {
+			code, body, _, err := e2e.Post(ctx, "/auth/login", []byte(fmt.Sprintf(`{ "email": "%s", "password": "12341234" }`, user.Email)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+
+			session := new(dto.WithTokenResponse)
+			Expect(json.Unmarshal(body, session)).To(Succeed())
+
+			code, body, _, err = e2e.Post(ctx, "/users/me/api-keys", []byte(`{ "name": "ci", "scopes": ["posts:read"], "expiresAt": "2099-01-01T00:00:00Z" }`),
+				map[string]string{"Authorization": "Bearer " + session.Token})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusCreated))
+
+			key := new(dto.APIKeyCreateResponse)
+			Expect(json.Unmarshal(body, key)).To(Succeed())
+
+			// The link is mailed in the background.
+			forgot(user.Email)
+			Eventually(outbox.String).Should(ContainSubstring("To: " + user.Email))
+
+			m := regexp.MustCompile(`token=([A-Za-z0-9_-]+)`).FindStringSubmatch(outbox.String())
+			Expect(m).To(HaveLen(2))
+
+			Expect(reset(m[1], "43214321")).To(Equal(http.StatusNoContent))
+			Expect(login("12341234")).To(Equal(http.StatusBadRequest))
+			Expect(login("43214321")).To(Equal(http.StatusOK))
+
+			Expect(reset(m[1], "56785678")).To(Equal(http.StatusUnauthorized))
+
+			code, _, _, err = e2e.Post(ctx, "/auth/refresh", []byte(fmt.Sprintf(`{ "refreshToken": "%s" }`, session.RefreshToken)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusUnauthorized))
+
+			// A key made before the reset does not outlive it.
+			code, _, _, err = e2e.Get(ctx, "/posts", map[string]string{"Authorization": "Bearer " + key.Key})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusUnauthorized))
+		})
+
+		It("should not reveal unknown emails", func() {
+			forgot("nobody@example.com")
+			Consistently(outbox.String, "200ms").Should(BeEmpty())
+		})
+
+		It("should fail with an unknown token", func() {
+			Expect(reset("unknown", "43214321")).To(Equal(http.StatusUnauthorized))
+		})
+	})
+
+	Context("email verification", func() {
+		register := func(username string) *dto.WithTokenResponse {
+			code, body, _, err := e2e.Post(ctx, "/auth/register", []byte(fmt.Sprintf(`{ "username": "%s", "email": "%s@example.com", "termsOfService": true, "password": "12341234" }`, username, username)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusCreated))
+
+			got := new(dto.WithTokenResponse)
+			Expect(json.Unmarshal(body, got)).To(Succeed())
+
+			return got
+		}
+
+		BeforeEach(func() {
+			outbox.Reset()
+		})
+
+		AfterEach(func() {
+			e2e.ClearAuthMidUser(e)
+		})
+
+		It("should verify the email with the mailed token once", func() {
+			got := register("veli")
+			Expect(got.EmailVerified).To(BeFalse())
+
+			m := regexp.MustCompile(`/v1/auth/verify\?token=([A-Za-z0-9_-]+)`).FindStringSubmatch(outbox.String())
+			Expect(m).To(HaveLen(2))
+
+			code, _, _, err := e2e.Get(ctx, "/auth/verify?token="+m[1])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusNoContent))
+
+			code, _, _, err = e2e.Get(ctx, "/auth/verify?token="+m[1])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusUnauthorized))
+
+			code, body, _, err := e2e.Post(ctx, "/auth/login", []byte(`{ "email": "veli@example.com", "password": "12341234" }`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+
+			login := new(dto.WithTokenResponse)
+			Expect(json.Unmarshal(body, login)).To(Succeed())
+			Expect(login.EmailVerified).To(BeTrue())
+		})
+
+		It("should hold back resends during the cooldown", func() {
+			got := register("deli")
+
+			e2e.AuthMidUser(e, &model.User{BaseModel: model.BaseModel{ID: got.UID}, Role: types.Registered})
+
+			code, body, _, err := e2e.Post(ctx, "/auth/verify/resend", nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusTooManyRequests))
+
+			apiErr := new(errorutils.APIError)
+			Expect(json.Unmarshal(body, apiErr)).To(Succeed())
+			Expect(apiErr.Code).To(Equal(errorutils.ErrCodeVerificationCooldown))
+		})
+
+		It("should not resend to verified users", func() {
+			e2e.AuthMidUser(e, user)
+
+			code, _, _, err := e2e.Post(ctx, "/auth/verify/resend", nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusConflict))
+		})
+	})
+
+	Context("lockout", func() {
+		AfterEach(func() {
+			e2e.ClearAuthMidUser(e)
+		})
+
+		login := func(email, password string) (int, *errorutils.APIError) {
+			code, body, _, err := e2e.Post(ctx, "/auth/login", []byte(fmt.Sprintf(`{ "email": "%s", "password": "%s" }`, email, password)))
+			Expect(err).ToNot(HaveOccurred())
+
+			got := new(errorutils.APIError)
+			if code != http.StatusOK {
+				Expect(json.Unmarshal(body, got)).To(Succeed())
+			}
+
+			return code, got
+		}
+
+		It("should lock out an account after repeated failures", func() {
+			for i := 0; i < 3; i++ {
+				code, got := login(user.Email, "43214321")
+				Expect(code).To(Equal(http.StatusBadRequest))
+				Expect(got.Code).To(Equal(errorutils.ErrCodeLoginFailed))
+			}
+
+			code, got := login(user.Email, "12341234")
+			Expect(code).To(Equal(http.StatusTooManyRequests))
+			Expect(got.Code).To(Equal(errorutils.ErrCodeLoginLocked))
+		})
+
+		It("should lock out unknown emails alike", func() {
+			for i := 0; i < 3; i++ {
+				code, got := login("nobody@example.com", "43214321")
+				Expect(code).To(Equal(http.StatusBadRequest))
+				Expect(got.Code).To(Equal(errorutils.ErrCodeLoginFailed))
+			}
+
+			code, _ := login("nobody@example.com", "43214321")
+			Expect(code).To(Equal(http.StatusTooManyRequests))
+		})
+
+		It("should not take the locked out IP from headers the client sets", func() {
+			spoofed := map[string]string{"X-Forwarded-For": "203.0.113.7", "X-Real-IP": "203.0.113.8"}
+
+			code, _, _, err := e2e.Post(ctx, "/auth/login",
+				[]byte(fmt.Sprintf(`{ "email": "%s", "password": "43214321" }`, user.Email)), spoofed)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusBadRequest))
+
+			rows, err := store.GetInstance().Query("SELECT key FROM login_attempts WHERE kind = $1", types.LoginIP)
+			Expect(err).ToNot(HaveOccurred())
+			defer rows.Close()
+
+			var keys []string
+
+			for rows.Next() {
+				var key string
+				Expect(rows.Scan(&key)).To(Succeed())
+
+				keys = append(keys, key)
+			}
+
+			Expect(keys).ToNot(BeEmpty())
+			Expect(keys).To(HaveEach(Not(HavePrefix("203.0.113."))))
+		})
+
+		It("should forget failures after a successful login", func() {
+			for i := 0; i < 2; i++ {
+				code, _ := login(user.Email, "43214321")
+				Expect(code).To(Equal(http.StatusBadRequest))
+			}
+
+			code, _ := login(user.Email, "12341234")
+			Expect(code).To(Equal(http.StatusOK))
+
+			code, _ = login(user.Email, "43214321")
+			Expect(code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("should let an admin list and lift lockouts", func() {
+			for i := 0; i < 3; i++ {
+				login(user.Email, "43214321")
+			}
+
+			e2e.AuthMidUser(e, user)
+
+			code, _, _, err := e2e.Get(ctx, "/auth/lockouts")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusUnauthorized))
+
+			e2e.AuthMidUser(e, e2e.CreateUserModel(58, types.Admin))
+
+			code, body, _, err := e2e.Get(ctx, "/auth/lockouts")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+
+			var got []dto.LoginAttemptResponse
+			Expect(json.Unmarshal(body, &got)).To(Succeed())
+			Expect(got).To(HaveLen(1))
+			Expect(got[0].Kind).To(Equal(types.LoginAccount))
+			Expect(got[0].Key).To(Equal(user.Email))
+			Expect(got[0].LockedUntil).ToNot(BeNil())
+
+			code, _, _, err = e2e.Delete(ctx, fmt.Sprintf("/auth/lockouts/%d", got[0].ID))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusNoContent))
+
+			code, _ = login(user.Email, "12341234")
+			Expect(code).To(Equal(http.StatusOK))
+
+			code, _, _, err = e2e.Delete(ctx, fmt.Sprintf("/auth/lockouts/%d", got[0].ID))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("two-factor", func() {
+		AfterEach(func() {
+			e2e.ClearAuthMidUser(e)
+		})
+
+		login := func() *dto.LoginResponse {
+			code, body, _, err := e2e.Post(ctx, "/auth/login", []byte(fmt.Sprintf(`{ "email": "%s", "password": "12341234" }`, user.Email)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+
+			got := new(dto.LoginResponse)
+			Expect(json.Unmarshal(body, got)).To(Succeed())
+
+			return got
+		}
+
+		secondStep := func(challenge, field, value string) (int, []byte) {
+			code, body, _, err := e2e.Post(ctx, "/auth/login/2fa", []byte(fmt.Sprintf(`{ "challengeToken": "%s", "%s": "%s" }`, challenge, field, value)))
+			Expect(err).ToNot(HaveOccurred())
+
+			return code, body
+		}
+
+		// confirmedStep is the time step of the code enable confirmed with.
+		var confirmedStep int64
+
+		// enable enrolls the user and returns their secret and recovery codes.
+		enable := func() (string, []string) {
+			e2e.AuthMidUser(e, user)
+
+			code, body, _, err := e2e.Post(ctx, "/auth/2fa/enroll", nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+
+			enrolled := new(dto.TwoFactorEnrollResponse)
+			Expect(json.Unmarshal(body, enrolled)).To(Succeed())
+			Expect(enrolled.URI).To(HavePrefix("otpauth://totp/"))
+			Expect(enrolled.URI).To(ContainSubstring("secret=" + enrolled.Secret))
+
+			confirmedStep = totp.Step(time.Now())
+
+			otp, err := totp.Code(enrolled.Secret, confirmedStep)
+			Expect(err).ToNot(HaveOccurred())
+
+			code, body, _, err = e2e.Post(ctx, "/auth/2fa/confirm", []byte(fmt.Sprintf(`{ "code": "%s" }`, otp)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+
+			codes := new(dto.RecoveryCodesResponse)
+			Expect(json.Unmarshal(body, codes)).To(Succeed())
+			Expect(codes.RecoveryCodes).To(HaveLen(10))
+
+			e2e.ClearAuthMidUser(e)
+
+			return enrolled.Secret, codes.RecoveryCodes
+		}
+
+		It("should log in without a second factor until it is confirmed", func() {
+			e2e.AuthMidUser(e, user)
+
+			code, _, _, err := e2e.Post(ctx, "/auth/2fa/enroll", nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+
+			code, _, _, err = e2e.Post(ctx, "/auth/2fa/confirm", []byte(`{ "code": "000000" }`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusUnauthorized))
+
+			got := login()
+			Expect(got.ChallengeToken).To(BeEmpty())
+			Expect(got.WithTokenResponse).ToNot(BeNil())
+			Expect(got.Token).ToNot(BeEmpty())
+		})
+
+		It("should ask for a code at login once enabled", func() {
+			secret, _ := enable()
+
+			got := login()
+			Expect(got.ChallengeToken).ToNot(BeEmpty())
+			Expect(got.WithTokenResponse).To(BeNil())
+
+			// The code of the confirmation was spent already.
+			used, err := totp.Code(secret, confirmedStep)
+			Expect(err).ToNot(HaveOccurred())
+
+			code, body := secondStep(got.ChallengeToken, "code", used)
+			Expect(code).To(Equal(http.StatusUnauthorized))
+
+			apiErr := new(errorutils.APIError)
+			Expect(json.Unmarshal(body, apiErr)).To(Succeed())
+			Expect(apiErr.Code).To(Equal(errorutils.ErrCodeInvalidTwoFactorCode))
+
+			next, err := totp.Code(secret, confirmedStep+1)
+			Expect(err).ToNot(HaveOccurred())
+
+			// The challenge is single use too.
+			code, _ = secondStep(got.ChallengeToken, "code", next)
+			Expect(code).To(Equal(http.StatusUnauthorized))
+
+			code, body = secondStep(login().ChallengeToken, "code", next)
+			Expect(code).To(Equal(http.StatusOK))
+
+			session := new(dto.WithTokenResponse)
+			Expect(json.Unmarshal(body, session)).To(Succeed())
+			Expect(session.Token).ToNot(BeEmpty())
+			Expect(session.TwoFactor).To(BeTrue())
+		})
+
+		It("should take a recovery code once", func() {
+			_, codes := enable()
+
+			code, _ := secondStep(login().ChallengeToken, "recoveryCode", strings.ToUpper(codes[0]))
+			Expect(code).To(Equal(http.StatusOK))
+
+			code, _ = secondStep(login().ChallengeToken, "recoveryCode", codes[0])
+			Expect(code).To(Equal(http.StatusUnauthorized))
+		})
+
+		It("should not enroll twice", func() {
+			enable()
+
+			e2e.AuthMidUser(e, user)
+
+			code, _, _, err := e2e.Post(ctx, "/auth/2fa/enroll", nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusConflict))
+		})
+
+		It("should turn off with the password and a recovery code", func() {
+			_, codes := enable()
+
+			e2e.AuthMidUser(e, user)
+
+			code, _, _, err := e2e.Post(ctx, "/auth/2fa/disable", []byte(fmt.Sprintf(`{ "password": "12341234", "recoveryCode": "%s" }`, codes[1])))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusNoContent))
+
+			Expect(login().ChallengeToken).To(BeEmpty())
+		})
+
+		It("should count wrong codes of a signed in user towards the lockout", func() {
+			secret, codes := enable()
+
+			e2e.AuthMidUser(e, user)
+
+			code, _, _, err := e2e.Post(ctx, "/auth/2fa/recovery-codes", []byte(`{ "code": "000000" }`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusUnauthorized))
+
+			for i := 0; i < 2; i++ {
+				code, _, _, err = e2e.Post(ctx, "/auth/2fa/disable", []byte(`{ "password": "12341234", "recoveryCode": "aaaa-aaaa" }`))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(code).To(Equal(http.StatusUnauthorized))
+			}
+
+			next, err := totp.Code(secret, confirmedStep+1)
+			Expect(err).ToNot(HaveOccurred())
+
+			code, body, _, err := e2e.Post(ctx, "/auth/2fa/recovery-codes", []byte(fmt.Sprintf(`{ "code": "%s" }`, next)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusTooManyRequests))
+
+			apiErr := new(errorutils.APIError)
+			Expect(json.Unmarshal(body, apiErr)).To(Succeed())
+			Expect(apiErr.Code).To(Equal(errorutils.ErrCodeLoginLocked))
+
+			code, _, _, err = e2e.Post(ctx, "/auth/2fa/disable", []byte(fmt.Sprintf(`{ "password": "12341234", "recoveryCode": "%s" }`, codes[0])))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusTooManyRequests))
+		})
+	})
+
+	Context("oidc", func() {
+		// signIn signs in at the stub provider as u; the client follows the
+		// redirects through the provider back to the callback.
+		signIn := func(u oidc.Identity) (int, []byte) {
+			oidcProvider.SetUser(u)
+
+			code, body, _, err := e2e.Get(ctx, "/auth/oidc/stub")
+			Expect(err).ToNot(HaveOccurred())
+
+			return code, body
+		}
+
+		It("should create a verified user on the first sign in", func() {
+			code, body := signIn(oidc.Identity{Subject: "new", Email: "oidc@example.com", EmailVerified: true, PreferredUsername: "oidc user"})
+			Expect(code).To(Equal(http.StatusOK))
+
+			got := new(dto.LoginResponse)
+			Expect(json.Unmarshal(body, got)).To(Succeed())
+			Expect(got.Token).ToNot(BeEmpty())
+			Expect(got.Email).To(Equal("oidc@example.com"))
+			Expect(got.Username).To(Equal("oidcuser"))
+			Expect(got.EmailVerified).To(BeTrue())
+
+			code, body = signIn(oidc.Identity{Subject: "new", Email: "changed@example.com", EmailVerified: true})
+			Expect(code).To(Equal(http.StatusOK))
+
+			again := new(dto.LoginResponse)
+			Expect(json.Unmarshal(body, again)).To(Succeed())
+			Expect(again.UID).To(Equal(got.UID))
+		})
+
+		It("should link the user with the verified email", func() {
+			code, body := signIn(oidc.Identity{Subject: "linked", Email: user.Email, EmailVerified: true})
+			Expect(code).To(Equal(http.StatusOK))
+
+			got := new(dto.LoginResponse)
+			Expect(json.Unmarshal(body, got)).To(Succeed())
+			Expect(got.UID).To(Equal(user.ID))
+		})
+
+		It("should not link an unverified email", func() {
+			code, _ := signIn(oidc.Identity{Subject: "unverified", Email: user.Email})
+			Expect(code).To(Equal(http.StatusForbidden))
+
+			code, _, _, err := e2e.Post(ctx, "/auth/register", []byte(`{ "username": "pending", "email": "pending@example.com", "termsOfService": true, "password": "12341234" }`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusCreated))
+
+			code, body := signIn(oidc.Identity{Subject: "pending", Email: "pending@example.com", EmailVerified: true})
+			Expect(code).To(Equal(http.StatusConflict))
+
+			apiErr := new(errorutils.APIError)
+			Expect(json.Unmarshal(body, apiErr)).To(Succeed())
+			Expect(apiErr.Code).To(Equal(errorutils.ErrCodeOIDCAccountUnverified))
+		})
+
+		It("should reject unknown providers and states", func() {
+			code, _, _, err := e2e.Get(ctx, "/auth/oidc/unknown")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusNotFound))
+
+			code, _, _, err = e2e.Get(ctx, "/auth/oidc/stub/callback?code=code&state=unknown")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusBadRequest))
+		})
+	})
 })
@@ -0,0 +1,187 @@
+package e2e_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/google/go-cmp/cmp"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/e2e"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/pagination"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/apputils"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/testutils"
+)
+
+var _ = Describe("categories", Ordered, func() {
+	ctx := context.Background()
+
+	modUser := e2e.CreateUserModel(63, types.Mod)
+
+	var users []*model.User
+	users = append(users, modUser)
+
+	// backend > databases, frontend has no children.
+	backend := e2e.CreateCategoryModel(0, nil)
+	databases := e2e.CreateCategoryModel(1, backend)
+	frontend := e2e.CreateCategoryModel(2, nil)
+	categories := []*model.Category{backend, databases, frontend}
+
+	posts := e2e.CreatePostModels(3)
+
+	BeforeAll(func() {
+		testutils.InsertUsers(apputils.ToSliceOfAny(users), store.GetInstance())
+	})
+
+	AfterAll(func() {
+		testutils.DeleteUsers(store.GetInstance())
+	})
+
+	BeforeEach(func() {
+		testutils.InsertCategories(categories, store.GetInstance())
+		testutils.InsertPosts(apputils.ToSliceOfAny(posts), store.GetInstance())
+
+		// Each post is filed under the category with the same index.
+		for i, c := range categories {
+			_, err := store.GetInstance().Exec("INSERT INTO post_categories (post_id, category_id) VALUES ($1, $2)", posts[i].ID, c.ID)
+			Expect(err).ToNot(HaveOccurred())
+		}
+	})
+
+	AfterEach(func() {
+		testutils.DeletePosts(store.GetInstance())
+		testutils.DeleteCategories(store.GetInstance())
+	})
+
+	Context("update", func() {
+		testCases := []struct {
+			when     string
+			it       string
+			id       uint64
+			json     string
+			wantCode int
+			wantErr  *errorutils.APIError
+		}{
+			{
+				when:     "moved under another top level category",
+				it:       "should success",
+				id:       frontend.ID,
+				json:     fmt.Sprintf(`{ "name": "Frontend", "parentId": %d }`, backend.ID),
+				wantCode: http.StatusOK,
+			},
+			{
+				when:     "nested under itself",
+				it:       "should fail",
+				id:       backend.ID,
+				json:     fmt.Sprintf(`{ "name": "Backend", "parentId": %d }`, backend.ID),
+				wantCode: http.StatusBadRequest,
+				wantErr:  errorutils.New(errorutils.ErrCategoryParent, nil),
+			},
+			{
+				when:     "nested under its own child",
+				it:       "should fail",
+				id:       backend.ID,
+				json:     fmt.Sprintf(`{ "name": "Backend", "parentId": %d }`, databases.ID),
+				wantCode: http.StatusBadRequest,
+				wantErr:  errorutils.New(errorutils.ErrCategoryParent, nil),
+			},
+			{
+				when:     "parent does not exist",
+				it:       "should fail",
+				id:       frontend.ID,
+				json:     `{ "name": "Frontend", "parentId": 20000 }`,
+				wantCode: http.StatusNotFound,
+				wantErr:  errorutils.New(errorutils.ErrCategoryNotFound, nil),
+			},
+		}
+
+		for _, tc := range testCases {
+			tc := tc
+			When(tc.when, func() {
+				AfterEach(func() {
+					e2e.ClearAuthMidUser(e)
+				})
+				It(tc.it, func() {
+					e2e.AuthMidUser(e, modUser)
+
+					code, body, _, err := e2e.Put(ctx, "/categories/"+strconv.FormatUint(tc.id, 10), []byte(tc.json))
+					Expect(err).ToNot(HaveOccurred())
+					Expect(code).To(Equal(tc.wantCode))
+
+					if tc.wantErr != nil {
+						got := new(errorutils.APIError)
+						err = json.Unmarshal(body, got)
+						Expect(err).ToNot(HaveOccurred())
+
+						if diff := cmp.Diff(tc.wantErr, got); diff != "" {
+							Expect(diff).To(BeEmpty())
+						}
+					}
+				})
+			})
+		}
+	})
+
+	Context("posts by category", func() {
+		testCases := []struct {
+			when      string
+			it        string
+			path      string
+			wantIDs   []uint64
+			wantTotal string
+		}{
+			{
+				when:      "category has subcategories",
+				it:        "should include the posts of its subcategories",
+				path:      "/posts?category=category-0&sort=createdAt,asc",
+				wantIDs:   []uint64{posts[0].ID, posts[1].ID},
+				wantTotal: "2",
+			},
+			{
+				when:      "leaf category",
+				it:        "should only return its own posts",
+				path:      "/posts?category=category-1",
+				wantIDs:   []uint64{posts[1].ID},
+				wantTotal: "1",
+			},
+			{
+				when:      "paged",
+				it:        "should keep the total count",
+				path:      "/posts?category=category-0&sort=createdAt,desc&size=1",
+				wantIDs:   []uint64{posts[1].ID},
+				wantTotal: "2",
+			},
+		}
+
+		for _, tc := range testCases {
+			tc := tc
+			When(tc.when, func() {
+				It(tc.it, func() {
+					code, body, header, err := e2e.Get(ctx, tc.path)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(code).To(Equal(http.StatusOK))
+					Expect(header.Get(pagination.HeaderXTotalCount)).To(Equal(tc.wantTotal))
+
+					var got []dto.PostResponse
+					err = json.Unmarshal(body, &got)
+					Expect(err).ToNot(HaveOccurred())
+
+					var ids []uint64
+					for _, p := range got {
+						ids = append(ids, p.ID)
+					}
+
+					Expect(ids).To(Equal(tc.wantIDs))
+				})
+			})
+		}
+	})
+})
@@ -0,0 +1,390 @@
+package e2e_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/google/go-cmp/cmp"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/e2e"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/pagination"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/apputils"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/testutils"
+)
+
+var _ = Describe("comments", Ordered, func() {
+	ctx := context.Background()
+
+	user := e2e.CreateUserModel(65, types.Registered)
+	stranger := e2e.CreateUserModel(66, types.Registered)
+	mod := e2e.CreateUserModel(67, types.Mod)
+	unverified := e2e.CreateUserModel(68, types.Registered)
+	unverified.EmailVerifiedAt = nil
+
+	var users []*model.User
+	users = append(users, user, stranger, mod, unverified)
+
+	posts := e2e.CreatePostModels(2)
+
+	// root > reply > nested reply on posts[0], plus a second root and a comment on posts[1].
+	root := e2e.CreateCommentModel(0, posts[0], user)
+	reply := e2e.CreateCommentModel(1, posts[0], user)
+	reply.ParentID = &root.ID
+	reply.Depth = 1
+	nested := e2e.CreateCommentModel(2, posts[0], user)
+	nested.ParentID = &reply.ID
+	nested.Depth = 2
+	secondRoot := e2e.CreateCommentModel(3, posts[0], user)
+	other := e2e.CreateCommentModel(4, posts[1], user)
+
+	comments := []*model.Comment{root, reply, nested, secondRoot, other}
+
+	BeforeAll(func() {
+		testutils.InsertUsers(apputils.ToSliceOfAny(users), store.GetInstance())
+	})
+
+	AfterAll(func() {
+		testutils.DeleteUsers(store.GetInstance())
+	})
+
+	BeforeEach(func() {
+		testutils.InsertPosts(apputils.ToSliceOfAny(posts), store.GetInstance())
+		testutils.InsertComments(comments, store.GetInstance())
+	})
+
+	AfterEach(func() {
+		testutils.DeletePosts(store.GetInstance())
+	})
+
+	Context("reply", func() {
+		testCases := []struct {
+			when     string
+			it       string
+			json     string
+			wantCode int
+			wantErr  *errorutils.APIError
+		}{
+			{
+				when:     "parent is on the same post",
+				it:       "should success",
+				json:     fmt.Sprintf(`{ "text": "a reply", "post_id": "%d", "parent_id": %d }`, posts[0].ID, root.ID),
+				wantCode: http.StatusCreated,
+			},
+			{
+				when:     "parent is on another post",
+				it:       "should fail",
+				json:     fmt.Sprintf(`{ "text": "a reply", "post_id": "%d", "parent_id": %d }`, posts[1].ID, root.ID),
+				wantCode: http.StatusBadRequest,
+				wantErr:  errorutils.New(errorutils.ErrCommentParent, nil),
+			},
+			{
+				when:     "parent is already at max depth",
+				it:       "should fail",
+				json:     fmt.Sprintf(`{ "text": "a reply", "post_id": "%d", "parent_id": %d }`, posts[0].ID, nested.ID),
+				wantCode: http.StatusBadRequest,
+				wantErr:  errorutils.New(errorutils.ErrCommentDepth, nil),
+			},
+			{
+				when:     "parent does not exist",
+				it:       "should fail",
+				json:     fmt.Sprintf(`{ "text": "a reply", "post_id": "%d", "parent_id": 20000 }`, posts[0].ID),
+				wantCode: http.StatusNotFound,
+				wantErr:  errorutils.New(errorutils.ErrCommentNotFound, nil),
+			},
+		}
+
+		for _, tc := range testCases {
+			tc := tc
+			When(tc.when, func() {
+				AfterEach(func() {
+					e2e.ClearAuthMidUser(e)
+				})
+				It(tc.it, func() {
+					e2e.AuthMidUser(e, user)
+
+					code, body, _, err := e2e.Post(ctx, "/comments", []byte(tc.json))
+					Expect(err).ToNot(HaveOccurred())
+					Expect(code).To(Equal(tc.wantCode))
+
+					if tc.wantErr != nil {
+						got := new(errorutils.APIError)
+						err = json.Unmarshal(body, got)
+						Expect(err).ToNot(HaveOccurred())
+
+						if diff := cmp.Diff(tc.wantErr, got); diff != "" {
+							Expect(diff).To(BeEmpty())
+						}
+					}
+				})
+			})
+		}
+	})
+
+	Context("tree", func() {
+		It("should paginate top level comments and nest their replies", func() {
+			code, body, header, err := e2e.Get(ctx, "/comments/"+strconv.FormatUint(posts[0].ID, 10)+"?tree=true&sort=createdAt,asc")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+			Expect(header.Get(pagination.HeaderXTotalCount)).To(Equal("2"))
+
+			var got []dto.CommentResponse
+			err = json.Unmarshal(body, &got)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(got).To(HaveLen(2))
+
+			Expect(got[0].ID).To(Equal(root.ID))
+			Expect(got[0].Replies).To(HaveLen(1))
+			Expect(got[0].Replies[0].ID).To(Equal(reply.ID))
+			Expect(got[0].Replies[0].Replies).To(HaveLen(1))
+			Expect(got[0].Replies[0].Replies[0].ID).To(Equal(nested.ID))
+			Expect(got[0].Replies[0].Replies[0].Depth).To(Equal(2))
+
+			Expect(got[1].ID).To(Equal(secondRoot.ID))
+			Expect(got[1].Replies).To(BeEmpty())
+		})
+
+		It("should list every comment flat without tree", func() {
+			code, _, header, err := e2e.Get(ctx, "/comments/"+strconv.FormatUint(posts[0].ID, 10))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+			Expect(header.Get(pagination.HeaderXTotalCount)).To(Equal("4"))
+		})
+	})
+
+	Context("update", func() {
+		testCases := []struct {
+			when     string
+			it       string
+			user     *model.User
+			id       uint64
+			json     string
+			wantCode int
+			wantErr  *errorutils.APIError
+		}{
+			{
+				when:     "author edits",
+				it:       "should success",
+				user:     user,
+				id:       root.ID,
+				json:     `{ "text": "edited by author" }`,
+				wantCode: http.StatusOK,
+			},
+			{
+				when:     "mod edits",
+				it:       "should success",
+				user:     mod,
+				id:       root.ID,
+				json:     `{ "text": "edited by mod" }`,
+				wantCode: http.StatusOK,
+			},
+			{
+				when:     "another user edits",
+				it:       "should fail",
+				user:     stranger,
+				id:       root.ID,
+				json:     `{ "text": "edited by stranger" }`,
+				wantCode: http.StatusUnauthorized,
+				wantErr:  errorutils.New(errorutils.ErrUnauthorized, nil),
+			},
+			{
+				when:     "comment does not exist",
+				it:       "should fail",
+				user:     user,
+				id:       20000,
+				json:     `{ "text": "edited by author" }`,
+				wantCode: http.StatusNotFound,
+				wantErr:  errorutils.New(errorutils.ErrCommentNotFound, nil),
+			},
+		}
+
+		for _, tc := range testCases {
+			tc := tc
+			When(tc.when, func() {
+				AfterEach(func() {
+					e2e.ClearAuthMidUser(e)
+				})
+				It(tc.it, func() {
+					e2e.AuthMidUser(e, tc.user)
+
+					code, body, _, err := e2e.Put(ctx, "/comments/"+strconv.FormatUint(tc.id, 10), []byte(tc.json))
+					Expect(err).ToNot(HaveOccurred())
+					Expect(code).To(Equal(tc.wantCode))
+
+					if tc.wantErr != nil {
+						got := new(errorutils.APIError)
+						err = json.Unmarshal(body, got)
+						Expect(err).ToNot(HaveOccurred())
+
+						if diff := cmp.Diff(tc.wantErr, got); diff != "" {
+							Expect(diff).To(BeEmpty())
+						}
+
+						return
+					}
+
+					got := new(dto.CommentResponse)
+					err = json.Unmarshal(body, got)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(got.Edited).To(BeTrue())
+					Expect(got.UpdatedAt).To(BeTemporally(">", root.UpdatedAt))
+				})
+			})
+		}
+	})
+
+	Context("moderation on edit", func() {
+		AfterEach(func() {
+			e2e.ClearAuthMidUser(e)
+		})
+
+		It("should send an untrusted author's edited comment back to the queue", func() {
+			approved := e2e.CreateCommentModel(5, posts[1], stranger)
+			testutils.InsertComments([]*model.Comment{approved}, store.GetInstance())
+
+			e2e.AuthMidUser(e, stranger)
+
+			code, body, _, err := e2e.Put(ctx, "/comments/"+strconv.FormatUint(approved.ID, 10), []byte(`{ "text": "buy cheap pills" }`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+
+			got := new(dto.CommentResponse)
+			Expect(json.Unmarshal(body, got)).To(Succeed())
+			Expect(got.State).To(Equal(types.Pending))
+		})
+
+		It("should keep a comment edited by a mod approved", func() {
+			approved := e2e.CreateCommentModel(5, posts[1], stranger)
+			testutils.InsertComments([]*model.Comment{approved}, store.GetInstance())
+
+			e2e.AuthMidUser(e, mod)
+
+			code, body, _, err := e2e.Put(ctx, "/comments/"+strconv.FormatUint(approved.ID, 10), []byte(`{ "text": "tidied up" }`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+
+			got := new(dto.CommentResponse)
+			Expect(json.Unmarshal(body, got)).To(Succeed())
+			Expect(got.State).To(Equal(types.Approved))
+		})
+	})
+
+	Context("revisions", func() {
+		AfterEach(func() {
+			e2e.ClearAuthMidUser(e)
+		})
+
+		It("should keep every previous text for mods", func() {
+			e2e.AuthMidUser(e, user)
+
+			for _, text := range []string{"first edit", "second edit"} {
+				code, _, _, err := e2e.Put(ctx, "/comments/"+strconv.FormatUint(root.ID, 10), []byte(fmt.Sprintf(`{ "text": "%s" }`, text)))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(code).To(Equal(http.StatusOK))
+			}
+
+			e2e.AuthMidUser(e, mod)
+
+			code, body, _, err := e2e.Get(ctx, "/comments/"+strconv.FormatUint(root.ID, 10)+"/revisions")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+
+			var got []dto.CommentRevisionResponse
+			err = json.Unmarshal(body, &got)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(got).To(HaveLen(2))
+			Expect(got[0].Text).To(Equal("first edit"))
+			Expect(got[1].Text).To(Equal(root.Text))
+		})
+
+		It("should not be readable by the author", func() {
+			e2e.AuthMidUser(e, user)
+
+			code, _, _, err := e2e.Get(ctx, "/comments/"+strconv.FormatUint(root.ID, 10)+"/revisions")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusUnauthorized))
+		})
+	})
+
+	Context("email verification", func() {
+		AfterEach(func() {
+			e2e.ClearAuthMidUser(e)
+		})
+
+		It("should keep unverified users from commenting", func() {
+			e2e.AuthMidUser(e, unverified)
+
+			code, body, _, err := e2e.Post(ctx, "/comments", []byte(fmt.Sprintf(`{ "text": "a comment", "post_id": "%d" }`, posts[0].ID)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusForbidden))
+
+			got := new(errorutils.APIError)
+			Expect(json.Unmarshal(body, got)).To(Succeed())
+			Expect(got.Code).To(Equal(errorutils.ErrCodeEmailNotVerified))
+		})
+	})
+
+	Context("hidden posts", func() {
+		postComments := "/comments/" + strconv.FormatUint(posts[0].ID, 10)
+
+		expectPostNotFound := func(code int, body []byte) {
+			Expect(code).To(Equal(http.StatusNotFound))
+
+			got := new(errorutils.APIError)
+			Expect(json.Unmarshal(body, got)).To(Succeed())
+			Expect(got.Code).To(Equal(errorutils.ErrCodePostNotFound))
+		}
+
+		AfterEach(func() {
+			e2e.ClearAuthMidUser(e)
+		})
+
+		testCases := []struct {
+			when string
+			hide string
+		}{
+			{when: "the post is a draft", hide: "state = 'draft', publish_at = NULL"},
+			{when: "the post is scheduled", hide: "state = 'scheduled', publish_at = now() + interval '1 day'"},
+			{when: "the post is deleted", hide: "deleted_at = now()"},
+		}
+
+		for _, tc := range testCases {
+			tc := tc
+			When(tc.when, func() {
+				It("should not read or take its comments", func() {
+					_, err := store.GetInstance().Exec("UPDATE posts SET "+tc.hide+" WHERE id = $1", posts[0].ID)
+					Expect(err).ToNot(HaveOccurred())
+
+					code, body, _, err := e2e.Get(ctx, postComments)
+					Expect(err).ToNot(HaveOccurred())
+					expectPostNotFound(code, body)
+
+					e2e.AuthMidUser(e, user)
+
+					code, body, _, err = e2e.Post(ctx, "/comments", []byte(fmt.Sprintf(`{ "text": "a comment", "post_id": "%d" }`, posts[0].ID)))
+					Expect(err).ToNot(HaveOccurred())
+					expectPostNotFound(code, body)
+				})
+			})
+		}
+
+		It("should show the comments on drafts to those who may read them", func() {
+			_, err := store.GetInstance().Exec("UPDATE posts SET state = 'draft', publish_at = NULL WHERE id = $1", posts[0].ID)
+			Expect(err).ToNot(HaveOccurred())
+
+			e2e.AuthMidUser(e, mod)
+
+			code, _, _, err := e2e.Get(ctx, postComments)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+		})
+	})
+})
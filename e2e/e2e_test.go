@@ -14,9 +14,21 @@ import (
 	postgresadapter "github.com/MehmetTalhaSeker/mts-blog-api/internal/adapter/postgres"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/database"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/rbac"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/jwtauth"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/mailer"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/oidc"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/oidc/oidctest"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/throttle"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/testutils"
 	"github.com/MehmetTalhaSeker/mts-blog-api/pkg/auth"
+	"github.com/MehmetTalhaSeker/mts-blog-api/pkg/category"
+	"github.com/MehmetTalhaSeker/mts-blog-api/pkg/comment"
+	"github.com/MehmetTalhaSeker/mts-blog-api/pkg/moderation"
 	"github.com/MehmetTalhaSeker/mts-blog-api/pkg/post"
+	"github.com/MehmetTalhaSeker/mts-blog-api/pkg/role"
+	"github.com/MehmetTalhaSeker/mts-blog-api/pkg/search"
+	"github.com/MehmetTalhaSeker/mts-blog-api/pkg/tag"
 	"github.com/MehmetTalhaSeker/mts-blog-api/pkg/user"
 )
 
@@ -25,19 +37,52 @@ var (
 	store        = database.NewPostgresStore(database.WithUser("test-user"), database.WithName("test-name"), database.WithPassword("test-password"), database.WithPort(p.Port()))
 )
 
-var e *echo.Echo
+var (
+	e      *echo.Echo
+	signer *jwtauth.Signer
+	// outbox collects the mails the server sends.
+	outbox e2e.Outbox
+	// oidcProvider is the stub provider users sign in with as "stub".
+	oidcProvider = oidctest.New("mts-blog-api")
+)
 
 var _ = BeforeSuite(func() {
 	done := make(chan struct{})
 
 	go func() {
-		rbac := rbac.New()
-
 		store.InitDB()
 
 		// initialize db repos
 		userRepo := postgresadapter.NewUserRepository(store.GetInstance())
 		postRepo := postgresadapter.NewPostRepository(store.GetInstance())
+		commentRepo := postgresadapter.NewCommentRepository(store.GetInstance())
+		tagRepo := postgresadapter.NewTagRepository(store.GetInstance())
+		categoryRepo := postgresadapter.NewCategoryRepository(store.GetInstance())
+		searchRepo := postgresadapter.NewSearchRepository(store.GetInstance(), "english")
+		sessionRepo := postgresadapter.NewSessionRepository(store.GetInstance())
+		userTokenRepo := postgresadapter.NewUserTokenRepository(store.GetInstance())
+		loginAttemptRepo := postgresadapter.NewLoginAttemptRepository(store.GetInstance())
+		recoveryCodeRepo := postgresadapter.NewRecoveryCodeRepository(store.GetInstance())
+		apiKeyRepo := postgresadapter.NewAPIKeyRepository(store.GetInstance())
+		identityRepo := postgresadapter.NewIdentityRepository(store.GetInstance())
+		roleRepo := postgresadapter.NewRoleRepository(store.GetInstance())
+		outboxMailer := mailer.NewLog(&outbox, "no-reply@example.com")
+		rbac := rbac.New(rbac.WithRoles(roleRepo))
+
+		if err := searchRepo.Reindex(); err != nil {
+			log.Fatal(err)
+		}
+
+		var err error
+
+		signer, err = e2e.NewSigner("e2e")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		authn := auth.NewAuthenticator(signer, userRepo, sessionRepo, apiKeyRepo)
+		accountLockout := throttle.Policy{Threshold: 3, Base: time.Minute, Max: time.Hour, Window: time.Hour}
+		ipLockout := throttle.Policy{Threshold: 1000, Base: time.Minute, Max: time.Hour, Window: time.Hour}
 
 		e = e2e.InitEcho()
 
@@ -46,31 +91,116 @@ var _ = BeforeSuite(func() {
 
 		// authentication router initialization.
 		authRouter := &auth.Router{
-			RouterGroup:    routerGroup,
-			UserRepository: userRepo,
+			Authenticate:           e2e.AuthMid(authn.Authenticate()),
+			RBAC:                   rbac,
+			RouterGroup:            routerGroup,
+			WellKnownGroup:         e.Group("/.well-known"),
+			UserRepository:         userRepo,
+			SessionRepository:      sessionRepo,
+			UserTokenRepository:    userTokenRepo,
+			LoginAttemptRepository: loginAttemptRepo,
+			RecoveryCodeRepository: recoveryCodeRepo,
+			IdentityRepository:     identityRepo,
+			APIKeyRepository:       apiKeyRepo,
+			Signer:                 signer,
+			Mailer:                 outboxMailer,
+			Config: auth.Config{
+				ResetURL:       "http://localhost:3000/reset-password",
+				VerifyURL:      "http://localhost:8080/v1/auth/verify",
+				AccountLockout: accountLockout,
+				IPLockout:      ipLockout,
+				OIDCProviders: map[string]oidc.Config{
+					"stub": oidcProvider.Config("http://localhost:8080/v1/auth/oidc/stub/callback"),
+				},
+			},
 		}
 		authRouter.New()
 
 		// user router initialization.
 		userRouter := &user.Router{
-			Authenticate:   e2e.AuthMid(),
-			RBAC:           rbac,
-			RouterGroup:    routerGroup,
-			UserRepository: userRepo,
+			Authenticate:      e2e.AuthMid(authn.Authenticate()),
+			RBAC:              rbac,
+			RouterGroup:       routerGroup,
+			UserRepository:    userRepo,
+			SessionRepository: sessionRepo,
+			APIKeyRepository:  apiKeyRepo,
+			Verifier:          auth.NewVerifier(userTokenRepo, outboxMailer, 0, "http://localhost:8080/v1/auth/verify"),
+			Lockout:           auth.NewLockout(loginAttemptRepo, accountLockout, ipLockout),
 		}
 		userRouter.New()
 
 		// post router initialization.
 		postRouter := &post.Router{
-			Authenticate:   e2e.AuthMid(),
+			Authenticate:         e2e.AuthMid(authn.Authenticate()),
+			OptionalAuthenticate: e2e.AuthMid(authn.OptionalAuthenticate()),
+			RBAC:                 rbac,
+			RouterGroup:          routerGroup,
+			PostRepository:       postRepo,
+			TagRepository:        tagRepo,
+			CategoryRepository:   categoryRepo,
+		}
+		postRouter.New()
+
+		// comment router initialization.
+		commentRouter := &comment.Router{
+			Authenticate:         e2e.AuthMid(authn.Authenticate()),
+			OptionalAuthenticate: e2e.AuthMid(authn.OptionalAuthenticate()),
+			RBAC:                 rbac,
+			RouterGroup:          routerGroup,
+			CommentRepository:    commentRepo,
+			PostRepository:       postRepo,
+			MaxDepth:             2,
+			Moderation:           types.ModerateTrusted,
+			TrustAfter:           1,
+			RequireVerified:      true,
+		}
+		commentRouter.New()
+
+		// moderation router initialization.
+		moderationRouter := &moderation.Router{
+			Authenticate:      e2e.AuthMid(authn.Authenticate()),
+			RBAC:              rbac,
+			RouterGroup:       routerGroup,
+			CommentRepository: commentRepo,
+		}
+		moderationRouter.New()
+
+		// tag router initialization.
+		tagRouter := &tag.Router{
+			Authenticate:  e2e.AuthMid(authn.Authenticate()),
+			RBAC:          rbac,
+			RouterGroup:   routerGroup,
+			TagRepository: tagRepo,
+		}
+		tagRouter.New()
+
+		// category router initialization.
+		categoryRouter := &category.Router{
+			Authenticate:       e2e.AuthMid(authn.Authenticate()),
+			RBAC:               rbac,
+			RouterGroup:        routerGroup,
+			CategoryRepository: categoryRepo,
+		}
+		categoryRouter.New()
+
+		// role router initialization.
+		roleRouter := &role.Router{
+			Authenticate:   e2e.AuthMid(authn.Authenticate()),
 			RBAC:           rbac,
 			RouterGroup:    routerGroup,
-			PostRepository: postRepo,
+			RoleRepository: roleRepo,
 		}
-		postRouter.New()
+		roleRouter.New()
+
+		// search router initialization.
+		searchRouter := &search.Router{
+			RouterGroup:      routerGroup,
+			SearchRepository: searchRepo,
+		}
+		searchRouter.New()
 
 		done <- struct{}{}
-		err := e.Start(":8080")
+		err = e.Start(":8080")
 		if err != nil {
 			log.Printf("error while starting the server: %v\n", err)
 		}
@@ -82,6 +212,7 @@ var _ = BeforeSuite(func() {
 
 var _ = AfterSuite(func() {
 	testutils.TerminateContainer(context.Background(), container)
+	oidcProvider.Close()
 	err := e.Close()
 	if err != nil {
 		log.Printf("error while closing the server: %v\n", err)
@@ -0,0 +1,225 @@
+package e2e_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/google/go-cmp/cmp"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/e2e"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/etag"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/apputils"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/testutils"
+)
+
+var _ = Describe("etag", Ordered, func() {
+	ctx := context.Background()
+
+	user := e2e.CreateUserModel(85, types.Registered)
+	mod := e2e.CreateUserModel(86, types.Mod)
+	admin := e2e.CreateUserModel(87, types.Admin)
+
+	var users []*model.User
+	users = append(users, user, mod, admin)
+
+	posts := e2e.CreatePostModels(1)
+	posts[0].UserID = mod.ID
+	posts[0].Author = mod.Username
+
+	comments := []*model.Comment{e2e.CreateCommentModel(0, posts[0], user)}
+
+	postPath := "/posts/" + strconv.FormatUint(posts[0].ID, 10)
+
+	expectPreconditionFailed := func(code int, body []byte) {
+		Expect(code).To(Equal(http.StatusPreconditionFailed))
+
+		got := new(errorutils.APIError)
+		Expect(json.Unmarshal(body, got)).To(Succeed())
+
+		if diff := cmp.Diff(errorutils.New(errorutils.ErrPreconditionFailed, nil), got); diff != "" {
+			Expect(diff).To(BeEmpty())
+		}
+	}
+
+	BeforeAll(func() {
+		testutils.InsertUsers(apputils.ToSliceOfAny(users), store.GetInstance())
+	})
+
+	AfterAll(func() {
+		testutils.DeleteUsers(store.GetInstance())
+	})
+
+	BeforeEach(func() {
+		testutils.InsertPosts(apputils.ToSliceOfAny(posts), store.GetInstance())
+		testutils.InsertComments(comments, store.GetInstance())
+	})
+
+	AfterEach(func() {
+		testutils.DeletePosts(store.GetInstance())
+		e2e.ClearAuthMidUser(e)
+	})
+
+	Context("posts", func() {
+		It("should tag reads and answer If-None-Match with 304", func() {
+			code, _, header, err := e2e.Get(ctx, postPath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+
+			tag := header.Get(etag.HeaderETag)
+			Expect(tag).To(Equal(etag.Make(posts[0].ID, posts[0].UpdatedAt)))
+
+			code, body, _, err := e2e.Get(ctx, postPath, map[string]string{etag.HeaderIfNoneMatch: tag})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusNotModified))
+			Expect(body).To(BeEmpty())
+
+			code, _, header, err = e2e.Get(ctx, "/posts/slug/"+posts[0].Slug, map[string]string{etag.HeaderIfNoneMatch: tag})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusNotModified))
+			Expect(header.Get(etag.HeaderETag)).To(Equal(tag))
+		})
+
+		It("should reject a stale If-Match and accept the current one", func() {
+			e2e.AuthMidUser(e, mod)
+
+			_, _, header, err := e2e.Get(ctx, postPath)
+			Expect(err).ToNot(HaveOccurred())
+
+			tag := header.Get(etag.HeaderETag)
+
+			code, _, header, err := e2e.Put(ctx, postPath, []byte(`{ "title": "FIRST-EDIT" }`), map[string]string{etag.HeaderIfMatch: tag})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+
+			newTag := header.Get(etag.HeaderETag)
+			Expect(newTag).ToNot(Equal(tag))
+
+			// Another editor still holding the first tag loses.
+			code, body, _, err := e2e.Put(ctx, postPath, []byte(`{ "title": "SECOND-EDIT" }`), map[string]string{etag.HeaderIfMatch: tag})
+			Expect(err).ToNot(HaveOccurred())
+			expectPreconditionFailed(code, body)
+
+			// The tag returned by the update is the one reads return.
+			_, _, header, err = e2e.Get(ctx, postPath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(header.Get(etag.HeaderETag)).To(Equal(newTag))
+		})
+
+		It("should let only one of several concurrent updates with the same If-Match through", func() {
+			e2e.AuthMidUser(e, mod)
+
+			tag := etag.Make(posts[0].ID, posts[0].UpdatedAt)
+			codes := make([]int, 5)
+
+			var wg sync.WaitGroup
+
+			for i := range codes {
+				wg.Add(1)
+
+				go func(i int) {
+					defer GinkgoRecover()
+					defer wg.Done()
+
+					code, _, _, err := e2e.Put(ctx, postPath, []byte(`{ "title": "EDIT-`+strconv.Itoa(i)+`" }`),
+						map[string]string{etag.HeaderIfMatch: tag})
+					Expect(err).ToNot(HaveOccurred())
+
+					codes[i] = code
+				}(i)
+			}
+
+			wg.Wait()
+
+			Expect(codes).To(HaveEach(Or(Equal(http.StatusOK), Equal(http.StatusPreconditionFailed))))
+
+			ok := 0
+			for _, code := range codes {
+				if code == http.StatusOK {
+					ok++
+				}
+			}
+
+			Expect(ok).To(Equal(1))
+		})
+
+		It("should reject a delete with a stale If-Match", func() {
+			e2e.AuthMidUser(e, admin)
+
+			code, body, _, err := e2e.Delete(ctx, postPath, map[string]string{etag.HeaderIfMatch: `"1-stale"`})
+			Expect(err).ToNot(HaveOccurred())
+			expectPreconditionFailed(code, body)
+
+			code, _, _, err = e2e.Delete(ctx, postPath, map[string]string{etag.HeaderIfMatch: etag.Make(posts[0].ID, posts[0].UpdatedAt)})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+		})
+	})
+
+	Context("users", func() {
+		It("should tag reads and honor If-Match on updates", func() {
+			e2e.AuthMidUser(e, admin)
+
+			userPath := "/users/" + strconv.FormatUint(user.ID, 10)
+
+			code, _, header, err := e2e.Get(ctx, userPath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+
+			tag := header.Get(etag.HeaderETag)
+			Expect(tag).ToNot(BeEmpty())
+
+			code, _, _, err = e2e.Get(ctx, userPath, map[string]string{etag.HeaderIfNoneMatch: tag})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusNotModified))
+
+			code, body, _, err := e2e.Put(ctx, userPath, []byte(`{ "username": "renamed" }`), map[string]string{etag.HeaderIfMatch: `"1-stale"`})
+			Expect(err).ToNot(HaveOccurred())
+			expectPreconditionFailed(code, body)
+
+			code, _, _, err = e2e.Put(ctx, userPath, []byte(`{ "username": "renamed" }`), map[string]string{etag.HeaderIfMatch: tag})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+		})
+	})
+
+	Context("comments", func() {
+		It("should tag the list and change the tag on edits", func() {
+			commentsPath := "/comments/" + strconv.FormatUint(posts[0].ID, 10)
+
+			_, _, header, err := e2e.Get(ctx, commentsPath)
+			Expect(err).ToNot(HaveOccurred())
+
+			tag := header.Get(etag.HeaderETag)
+			Expect(tag).ToNot(BeEmpty())
+
+			code, _, _, err := e2e.Get(ctx, commentsPath, map[string]string{etag.HeaderIfNoneMatch: tag})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusNotModified))
+
+			e2e.AuthMidUser(e, user)
+
+			commentPath := "/comments/" + strconv.FormatUint(comments[0].ID, 10)
+
+			code, body, _, err := e2e.Put(ctx, commentPath, []byte(`{ "text": "edited" }`), map[string]string{etag.HeaderIfMatch: `"1-stale"`})
+			Expect(err).ToNot(HaveOccurred())
+			expectPreconditionFailed(code, body)
+
+			code, _, _, err = e2e.Put(ctx, commentPath, []byte(`{ "text": "edited" }`),
+				map[string]string{etag.HeaderIfMatch: etag.Make(comments[0].ID, comments[0].UpdatedAt)})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+
+			code, _, _, err = e2e.Get(ctx, commentsPath, map[string]string{etag.HeaderIfNoneMatch: tag})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+		})
+	})
+})
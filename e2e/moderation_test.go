@@ -0,0 +1,212 @@
+package e2e_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/e2e"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/etag"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/pagination"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/apputils"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/testutils"
+)
+
+var _ = Describe("moderation", Ordered, func() {
+	ctx := context.Background()
+
+	trusted := e2e.CreateUserModel(75, types.Registered)
+	newcomer := e2e.CreateUserModel(76, types.Registered)
+	mod := e2e.CreateUserModel(77, types.Mod)
+
+	var users []*model.User
+	users = append(users, trusted, newcomer, mod)
+
+	posts := e2e.CreatePostModels(1)
+
+	approved := e2e.CreateCommentModel(0, posts[0], trusted)
+	pending := e2e.CreateCommentModel(1, posts[0], newcomer)
+	pending.State = types.Pending
+	spam := e2e.CreateCommentModel(2, posts[0], newcomer)
+	spam.State = types.Spam
+
+	comments := []*model.Comment{approved, pending, spam}
+
+	postComments := "/comments/" + strconv.FormatUint(posts[0].ID, 10)
+
+	readPublic := func() []dto.CommentResponse {
+		code, body, _, err := e2e.Get(ctx, postComments)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(code).To(Equal(http.StatusOK))
+
+		var got []dto.CommentResponse
+		Expect(json.Unmarshal(body, &got)).To(Succeed())
+
+		return got
+	}
+
+	readQueue := func(state string) []dto.CommentResponse {
+		code, body, _, err := e2e.Get(ctx, "/moderation/comments?state="+state)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(code).To(Equal(http.StatusOK))
+
+		var got []dto.CommentResponse
+		Expect(json.Unmarshal(body, &got)).To(Succeed())
+
+		return got
+	}
+
+	BeforeAll(func() {
+		testutils.InsertUsers(apputils.ToSliceOfAny(users), store.GetInstance())
+	})
+
+	AfterAll(func() {
+		testutils.DeleteUsers(store.GetInstance())
+	})
+
+	BeforeEach(func() {
+		testutils.InsertPosts(apputils.ToSliceOfAny(posts), store.GetInstance())
+		testutils.InsertComments(comments, store.GetInstance())
+	})
+
+	AfterEach(func() {
+		testutils.DeletePosts(store.GetInstance())
+		e2e.ClearAuthMidUser(e)
+	})
+
+	Context("public comments", func() {
+		It("should only list approved comments", func() {
+			got := readPublic()
+			Expect(got).To(HaveLen(1))
+			Expect(got[0].ID).To(Equal(approved.ID))
+		})
+	})
+
+	Context("new comments", func() {
+		It("should hold the comments of users without approved comments", func() {
+			e2e.AuthMidUser(e, newcomer)
+
+			code, _, _, err := e2e.Post(ctx, "/comments", []byte(fmt.Sprintf(`{ "text": "first!", "post_id": "%d" }`, posts[0].ID)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusCreated))
+			Expect(readPublic()).To(HaveLen(1))
+
+			e2e.AuthMidUser(e, mod)
+			Expect(readQueue("pending")).To(HaveLen(2))
+		})
+
+		It("should approve the comments of trusted users", func() {
+			e2e.AuthMidUser(e, trusted)
+
+			code, _, _, err := e2e.Post(ctx, "/comments", []byte(fmt.Sprintf(`{ "text": "again", "post_id": "%d" }`, posts[0].ID)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusCreated))
+			Expect(readPublic()).To(HaveLen(2))
+		})
+	})
+
+	Context("queue", func() {
+		It("should list pending comments by default", func() {
+			e2e.AuthMidUser(e, mod)
+
+			code, body, header, err := e2e.Get(ctx, "/moderation/comments")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+			Expect(header.Get(pagination.HeaderXTotalCount)).To(Equal("1"))
+
+			var got []dto.CommentResponse
+			Expect(json.Unmarshal(body, &got)).To(Succeed())
+			Expect(got[0].ID).To(Equal(pending.ID))
+			Expect(got[0].State).To(Equal(types.Pending))
+		})
+
+		It("should list other states on request", func() {
+			e2e.AuthMidUser(e, mod)
+
+			got := readQueue("spam")
+			Expect(got).To(HaveLen(1))
+			Expect(got[0].ID).To(Equal(spam.ID))
+		})
+
+		It("should not be readable by registered users", func() {
+			e2e.AuthMidUser(e, newcomer)
+
+			code, _, _, err := e2e.Get(ctx, "/moderation/comments")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusUnauthorized))
+		})
+	})
+
+	Context("bulk actions", func() {
+		It("should approve comments in bulk", func() {
+			e2e.AuthMidUser(e, mod)
+
+			ids := fmt.Sprintf(`{ "ids": [%d, %d, %d] }`, pending.ID, spam.ID, approved.ID)
+
+			code, body, _, err := e2e.Post(ctx, "/moderation/comments/approve", []byte(ids))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+
+			got := new(dto.ModerationResponse)
+			Expect(json.Unmarshal(body, got)).To(Succeed())
+			Expect(got.State).To(Equal(types.Approved))
+			Expect(got.IDs).To(ConsistOf(pending.ID, spam.ID))
+
+			Expect(readPublic()).To(HaveLen(3))
+		})
+
+		It("should reject comments in bulk", func() {
+			e2e.AuthMidUser(e, mod)
+
+			code, _, _, err := e2e.Post(ctx, "/moderation/comments/reject", []byte(fmt.Sprintf(`{ "ids": [%d] }`, approved.ID)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+
+			Expect(readPublic()).To(BeEmpty())
+
+			got := readQueue("rejected")
+			Expect(got).To(HaveLen(1))
+			Expect(got[0].ModeratedBy).ToNot(BeEmpty())
+		})
+
+		It("should fail an edit made against the comment before it was moderated", func() {
+			// The author loaded the comment before the moderator got to it.
+			tag := etag.Make(pending.ID, pending.UpdatedAt)
+
+			e2e.AuthMidUser(e, mod)
+
+			code, _, _, err := e2e.Post(ctx, "/moderation/comments/spam", []byte(fmt.Sprintf(`{ "ids": [%d] }`, pending.ID)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+
+			e2e.AuthMidUser(e, newcomer)
+
+			code, _, _, err = e2e.Put(ctx, "/comments/"+strconv.FormatUint(pending.ID, 10), []byte(`{ "text": "edited" }`),
+				map[string]string{etag.HeaderIfMatch: tag})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusPreconditionFailed))
+
+			e2e.AuthMidUser(e, mod)
+
+			got := readQueue("spam")
+			Expect(got).To(HaveLen(2))
+			Expect(got).To(ContainElement(HaveField("ID", pending.ID)))
+		})
+
+		It("should require ids", func() {
+			e2e.AuthMidUser(e, mod)
+
+			code, _, _, err := e2e.Post(ctx, "/moderation/comments/spam", []byte(`{ "ids": [] }`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusBadRequest))
+		})
+	})
+})
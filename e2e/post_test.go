@@ -3,6 +3,7 @@ package e2e_test
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
@@ -33,6 +34,8 @@ var _ = Describe("post", Ordered, func() {
 	users = append(users, user, modUser, adminUser)
 
 	posts := e2e.CreatePostModels(30)
+	posts[0].UserID = modUser.ID
+	posts[0].Author = modUser.Username
 
 	var postDTOs []dto.PostResponse
 	for _, p := range posts {
@@ -302,6 +305,78 @@ var _ = Describe("post", Ordered, func() {
 		}
 	})
 
+	Context("read by slug", func() {
+		testCases := []struct {
+			when     string
+			it       string
+			slug     string
+			oldSlug  bool
+			want     *dto.PostResponse
+			wantCode int
+			wantErr  *errorutils.APIError
+		}{
+			{
+				when:     "current slug",
+				it:       "should success",
+				slug:     posts[2].Slug,
+				want:     posts[2].ToDTO(),
+				wantCode: http.StatusOK,
+			},
+			{
+				when:     "old slug",
+				it:       "should redirect to the current slug",
+				slug:     "an-old-title",
+				oldSlug:  true,
+				want:     posts[2].ToDTO(),
+				wantCode: http.StatusOK,
+			},
+			{
+				when:     "non existing slug",
+				it:       "should fail",
+				slug:     "no-such-post",
+				wantCode: http.StatusNotFound,
+				wantErr:  errorutils.New(errorutils.ErrPostNotFound, nil),
+			},
+		}
+
+		for _, tc := range testCases {
+			tc := tc
+			When(tc.when, func() {
+				It(tc.it, func() {
+					if tc.oldSlug {
+						_, err := store.GetInstance().Exec("INSERT INTO post_slugs (slug, post_id, created_at) VALUES ($1, $2, $3)", tc.slug, posts[2].ID, time.Now())
+						Expect(err).ToNot(HaveOccurred())
+					}
+
+					// The client follows the redirect hint for old slugs.
+					code, body, _, err := e2e.Get(ctx, "/posts/slug/"+tc.slug)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(code).To(Equal(tc.wantCode))
+
+					if tc.want != nil {
+						got := new(dto.PostResponse)
+						err = json.Unmarshal(body, got)
+						Expect(err).ToNot(HaveOccurred())
+
+						if diff := cmp.Diff(tc.want, got, cmpopts.IgnoreTypes(time.Time{}), cmpopts.IgnoreFields(dto.PostResponse{}, "CreatedAt", "UpdatedAt", "DeletedAt", "UpdatedBy", "CreatedBy")); diff != "" {
+							Expect(diff).To(BeEmpty())
+						}
+					}
+
+					if tc.wantErr != nil {
+						got := new(errorutils.APIError)
+						err = json.Unmarshal(body, got)
+						Expect(err).ToNot(HaveOccurred())
+
+						if diff := cmp.Diff(tc.wantErr, got); diff != "" {
+							Expect(diff).To(BeEmpty())
+						}
+					}
+				})
+			})
+		}
+	})
+
 	Context("reads", func() {
 		testCases := []struct {
 			when        string
@@ -446,9 +521,12 @@ var _ = Describe("post", Ordered, func() {
 				id:         posts[0].ID,
 				updateJSON: `{ "title": "TAIL", "body":"12312312^123123123" }`,
 				want: &dto.PostResponse{
-					ID:    posts[0].ID,
-					Title: "TAIL",
-					Body:  "12312312^123123123",
+					ID:        posts[0].ID,
+					Title:     "TAIL",
+					Body:      "12312312^123123123",
+					Status:    types.Active,
+					UpdatedBy: strconv.FormatUint(adminUser.ID, 10),
+					Author:    &dto.AuthorResponse{ID: modUser.ID, Username: modUser.Username},
 				},
 				wantCode: http.StatusOK,
 			},
@@ -476,12 +554,24 @@ var _ = Describe("post", Ordered, func() {
 				id:         posts[0].ID,
 				updateJSON: `{ "title": "TAIL", "body":"12312312^123123123" }`,
 				want: &dto.PostResponse{
-					ID:    posts[0].ID,
-					Title: "TAIL",
-					Body:  "12312312^123123123",
+					ID:        posts[0].ID,
+					Title:     "TAIL",
+					Body:      "12312312^123123123",
+					Status:    types.Active,
+					UpdatedBy: strconv.FormatUint(modUser.ID, 10),
+					Author:    &dto.AuthorResponse{ID: modUser.ID, Username: modUser.Username},
 				},
 				wantCode: http.StatusOK,
 			},
+			{
+				when:       "by mod on another author's post",
+				it:         "should fail",
+				authUser:   modUser,
+				id:         posts[1].ID,
+				updateJSON: `{ "title": "TAIL", "body":"12312312^123123123" }`,
+				wantCode:   http.StatusUnauthorized,
+				wantErr:    errorutils.New(errorutils.ErrUnauthorized, nil),
+			},
 			{
 				when:       "empty fields",
 				it:         "should fail",
@@ -668,4 +758,380 @@ var _ = Describe("post", Ordered, func() {
 			})
 		}
 	})
+
+	Context("workflow", func() {
+		testCases := []struct {
+			when      string
+			it        string
+			id        uint64
+			action    string
+			json      string
+			authUser  *model.User
+			wantState types.PostState
+			wantCode  int
+			wantErr   *errorutils.APIError
+		}{
+			{
+				when:      "mod unpublishes own post",
+				it:        "should move it back to draft",
+				id:        posts[0].ID,
+				action:    "unpublish",
+				authUser:  modUser,
+				wantState: types.Draft,
+				wantCode:  http.StatusOK,
+			},
+			{
+				when:      "admin archives a post",
+				it:        "should archive it",
+				id:        posts[1].ID,
+				action:    "archive",
+				authUser:  adminUser,
+				wantState: types.Archived,
+				wantCode:  http.StatusOK,
+			},
+			{
+				when:      "admin publishes with a future date",
+				it:        "should schedule it",
+				id:        posts[1].ID,
+				action:    "publish",
+				json:      fmt.Sprintf(`{ "publishAt": "%s" }`, time.Now().Add(time.Hour).Format(time.RFC3339)),
+				authUser:  adminUser,
+				wantState: types.Scheduled,
+				wantCode:  http.StatusOK,
+			},
+			{
+				when:     "mod archives another author's post",
+				it:       "should fail",
+				id:       posts[1].ID,
+				action:   "archive",
+				authUser: modUser,
+				wantCode: http.StatusUnauthorized,
+				wantErr:  errorutils.New(errorutils.ErrUnauthorized, nil),
+			},
+			{
+				when:     "registered user archives a post",
+				it:       "should fail",
+				id:       posts[0].ID,
+				action:   "archive",
+				authUser: user,
+				wantCode: http.StatusUnauthorized,
+				wantErr:  errorutils.New(errorutils.ErrUnauthorized, nil),
+			},
+		}
+
+		for _, tc := range testCases {
+			tc := tc
+			When(tc.when, func() {
+				AfterEach(func() {
+					e2e.ClearAuthMidUser(e)
+				})
+				It(tc.it, func() {
+					e2e.AuthMidUser(e, tc.authUser)
+
+					var payload []byte
+					if tc.json != "" {
+						payload = []byte(tc.json)
+					}
+
+					code, body, _, err := e2e.Post(ctx, "/posts/"+strconv.FormatUint(tc.id, 10)+"/"+tc.action, payload)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(code).To(Equal(tc.wantCode))
+
+					if tc.wantState != "" {
+						got := new(dto.PostResponse)
+						err = json.Unmarshal(body, got)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(got.State).To(Equal(tc.wantState))
+
+						// Posts that left the published state are hidden from visitors.
+						e2e.ClearAuthMidUser(e)
+
+						code, _, _, err = e2e.Get(ctx, "/posts/"+strconv.FormatUint(tc.id, 10))
+						Expect(err).ToNot(HaveOccurred())
+						Expect(code).To(Equal(http.StatusNotFound))
+					}
+
+					if tc.wantErr != nil {
+						got := new(errorutils.APIError)
+						err = json.Unmarshal(body, got)
+						Expect(err).ToNot(HaveOccurred())
+
+						if diff := cmp.Diff(tc.wantErr, got); diff != "" {
+							Expect(diff).To(BeEmpty())
+						}
+					}
+				})
+			})
+		}
+	})
+
+	Context("revisions", func() {
+		postPath := "/posts/" + strconv.FormatUint(posts[0].ID, 10)
+
+		update := func(title, body string) {
+			code, _, _, err := e2e.Put(ctx, postPath, []byte(fmt.Sprintf(`{ "title": "%s", "body": "%s" }`, title, body)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+		}
+
+		readRevisions := func() []dto.PostRevisionResponse {
+			code, body, _, err := e2e.Get(ctx, postPath+"/revisions")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+
+			var got []dto.PostRevisionResponse
+			Expect(json.Unmarshal(body, &got)).To(Succeed())
+
+			return got
+		}
+
+		AfterEach(func() {
+			e2e.ClearAuthMidUser(e)
+		})
+
+		It("should snapshot every edit", func() {
+			e2e.AuthMidUser(e, modUser)
+
+			update("FIRST-EDIT", posts[0].Body)
+			update("SECOND-EDIT", "new body")
+
+			got := readRevisions()
+			Expect(got).To(HaveLen(2))
+			Expect(got[0].Number).To(Equal(2))
+			Expect(got[0].Title).To(Equal("FIRST-EDIT"))
+			Expect(got[1].Number).To(Equal(1))
+			Expect(got[1].Title).To(Equal(posts[0].Title))
+			Expect(got[1].Body).To(Equal(posts[0].Body))
+			Expect(got[1].CreatedBy).To(Equal(strconv.FormatUint(modUser.ID, 10)))
+		})
+
+		It("should diff two revisions or a revision and the current version", func() {
+			e2e.AuthMidUser(e, modUser)
+
+			update("FIRST-EDIT", posts[0].Body)
+			update("SECOND-EDIT", "new body")
+
+			code, body, _, err := e2e.Get(ctx, postPath+"/revisions/diff?from=1&to=2")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+
+			got := new(dto.PostDiffResponse)
+			Expect(json.Unmarshal(body, got)).To(Succeed())
+			Expect(got.Diff).To(Equal(fmt.Sprintf("--- revision/1\n+++ revision/2\n@@ -1,3 +1,3 @@\n-%s\n+FIRST-EDIT\n \n %s\n", posts[0].Title, posts[0].Body)))
+
+			code, body, _, err = e2e.Get(ctx, postPath+"/revisions/diff?from=2")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+
+			got = new(dto.PostDiffResponse)
+			Expect(json.Unmarshal(body, got)).To(Succeed())
+			Expect(got.Diff).To(ContainSubstring("+++ current\n"))
+			Expect(got.Diff).To(ContainSubstring("+new body\n"))
+		})
+
+		It("should restore a revision", func() {
+			e2e.AuthMidUser(e, modUser)
+
+			update("FIRST-EDIT", "new body")
+
+			code, body, _, err := e2e.Post(ctx, postPath+"/revisions/1/restore", nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+
+			got := new(dto.PostResponse)
+			Expect(json.Unmarshal(body, got)).To(Succeed())
+			Expect(got.Title).To(Equal(posts[0].Title))
+			Expect(got.Body).To(Equal(posts[0].Body))
+
+			// The replaced version is kept, so the restore can be undone.
+			revisions := readRevisions()
+			Expect(revisions).To(HaveLen(2))
+			Expect(revisions[0].Title).To(Equal("FIRST-EDIT"))
+		})
+
+		It("should fail for an unknown revision", func() {
+			e2e.AuthMidUser(e, modUser)
+
+			code, body, _, err := e2e.Post(ctx, postPath+"/revisions/7/restore", nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusNotFound))
+
+			got := new(errorutils.APIError)
+			Expect(json.Unmarshal(body, got)).To(Succeed())
+
+			if diff := cmp.Diff(errorutils.New(errorutils.ErrPostRevisionNotFound, nil), got); diff != "" {
+				Expect(diff).To(BeEmpty())
+			}
+		})
+
+		It("should not show another author's history to a mod", func() {
+			e2e.AuthMidUser(e, modUser)
+
+			code, _, _, err := e2e.Get(ctx, "/posts/"+strconv.FormatUint(posts[1].ID, 10)+"/revisions")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusUnauthorized))
+		})
+	})
+
+	Context("restore", func() {
+		testCases := []struct {
+			when     string
+			it       string
+			id       uint64
+			deleted  bool
+			authUser *model.User
+			want     *dto.ResponseWithID
+			wantCode int
+			wantErr  *errorutils.APIError
+		}{
+			{
+				when:     "by mod",
+				it:       "should fail",
+				id:       posts[0].ID,
+				deleted:  true,
+				authUser: modUser,
+				wantCode: http.StatusUnauthorized,
+				wantErr:  errorutils.New(errorutils.ErrUnauthorized, nil),
+			},
+			{
+				when:     "by admin",
+				it:       "should success",
+				id:       posts[0].ID,
+				deleted:  true,
+				authUser: adminUser,
+				want:     &dto.ResponseWithID{ID: strconv.FormatUint(posts[0].ID, 10)},
+				wantCode: http.StatusOK,
+			},
+			{
+				when:     "post is not deleted",
+				it:       "should fail",
+				id:       posts[0].ID,
+				authUser: adminUser,
+				wantCode: http.StatusNotFound,
+				wantErr:  errorutils.New(errorutils.ErrPostNotFound, nil),
+			},
+		}
+
+		for _, tc := range testCases {
+			tc := tc
+			When(tc.when, func() {
+				AfterEach(func() {
+					e2e.ClearAuthMidUser(e)
+				})
+				It(tc.it, func() {
+					if tc.deleted {
+						_, err := store.GetInstance().Exec("UPDATE posts SET deleted_at = $1 WHERE id = $2", time.Now(), tc.id)
+						Expect(err).ToNot(HaveOccurred())
+					}
+
+					if tc.authUser != nil {
+						e2e.AuthMidUser(e, tc.authUser)
+					}
+
+					code, body, _, err := e2e.Post(ctx, "/posts/"+strconv.FormatUint(tc.id, 10)+"/restore", nil)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(code).To(Equal(tc.wantCode))
+
+					if tc.want != nil {
+						got := new(dto.ResponseWithID)
+						err = json.Unmarshal(body, got)
+						Expect(err).ToNot(HaveOccurred())
+
+						if diff := cmp.Diff(tc.want, got); diff != "" {
+							Expect(diff).To(BeEmpty())
+						}
+
+						code, _, _, err = e2e.Get(ctx, "/posts/"+strconv.FormatUint(tc.id, 10))
+						Expect(err).ToNot(HaveOccurred())
+						Expect(code).To(Equal(http.StatusOK))
+					}
+
+					if tc.wantErr != nil {
+						got := new(errorutils.APIError)
+						err = json.Unmarshal(body, got)
+						Expect(err).ToNot(HaveOccurred())
+
+						if diff := cmp.Diff(tc.wantErr, got); diff != "" {
+							Expect(diff).To(BeEmpty())
+						}
+					}
+				})
+			})
+		}
+	})
+
+	Context("purge", func() {
+		testCases := []struct {
+			when     string
+			it       string
+			id       uint64
+			authUser *model.User
+			want     *dto.ResponseWithID
+			wantCode int
+			wantErr  *errorutils.APIError
+		}{
+			{
+				when:     "by mod",
+				it:       "should fail",
+				id:       posts[0].ID,
+				authUser: modUser,
+				wantCode: http.StatusUnauthorized,
+				wantErr:  errorutils.New(errorutils.ErrUnauthorized, nil),
+			},
+			{
+				when:     "by admin",
+				it:       "should success",
+				id:       posts[0].ID,
+				authUser: adminUser,
+				want:     &dto.ResponseWithID{ID: strconv.FormatUint(posts[0].ID, 10)},
+				wantCode: http.StatusOK,
+			},
+			{
+				when:     "valid id but no data",
+				it:       "should fail",
+				id:       20000,
+				authUser: adminUser,
+				wantCode: http.StatusNotFound,
+				wantErr:  errorutils.New(errorutils.ErrPostNotFound, nil),
+			},
+		}
+
+		for _, tc := range testCases {
+			tc := tc
+			When(tc.when, func() {
+				AfterEach(func() {
+					e2e.ClearAuthMidUser(e)
+				})
+				It(tc.it, func() {
+					if tc.authUser != nil {
+						e2e.AuthMidUser(e, tc.authUser)
+					}
+
+					code, body, _, err := e2e.Delete(ctx, "/posts/"+strconv.FormatUint(tc.id, 10)+"/purge")
+					Expect(err).ToNot(HaveOccurred())
+					Expect(code).To(Equal(tc.wantCode))
+
+					if tc.want != nil {
+						got := new(dto.ResponseWithID)
+						err = json.Unmarshal(body, got)
+						Expect(err).ToNot(HaveOccurred())
+
+						if diff := cmp.Diff(tc.want, got); diff != "" {
+							Expect(diff).To(BeEmpty())
+						}
+					}
+
+					if tc.wantErr != nil {
+						got := new(errorutils.APIError)
+						err = json.Unmarshal(body, got)
+						Expect(err).ToNot(HaveOccurred())
+
+						if diff := cmp.Diff(tc.wantErr, got); diff != "" {
+							Expect(diff).To(BeEmpty())
+						}
+					}
+				})
+			})
+		}
+	})
 })
@@ -0,0 +1,273 @@
+package e2e_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/e2e"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/apputils"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/testutils"
+)
+
+var _ = Describe("roles", Ordered, func() {
+	ctx := context.Background()
+
+	adminUser := e2e.CreateUserModel(91, types.Admin)
+	modUser := e2e.CreateUserModel(92, types.Mod)
+	user := e2e.CreateUserModel(93, types.Registered)
+	manager := e2e.CreateUserModel(94, types.Registered)
+	roleManager := e2e.CreateUserModel(95, types.Registered)
+
+	var users []*model.User
+	users = append(users, adminUser, modUser, user, manager, roleManager)
+
+	apiCode := func(body []byte) string {
+		got := new(errorutils.APIError)
+		Expect(json.Unmarshal(body, got)).To(Succeed())
+
+		return got.Code
+	}
+
+	BeforeAll(func() {
+		testutils.InsertUsers(apputils.ToSliceOfAny(users), store.GetInstance())
+	})
+
+	AfterAll(func() {
+		testutils.DeleteUsers(store.GetInstance())
+
+		_, err := store.GetInstance().Exec("DELETE FROM roles WHERE name NOT IN ('admin', 'mod', 'registered')")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		e2e.ClearAuthMidUser(e)
+	})
+
+	It("should list the built-in roles", func() {
+		e2e.AuthMidUser(e, adminUser)
+
+		code, body, _, err := e2e.Get(ctx, "/roles")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(code).To(Equal(http.StatusOK))
+
+		var got []*dto.RoleResponse
+		Expect(json.Unmarshal(body, &got)).To(Succeed())
+		Expect(got).To(HaveLen(3))
+		Expect(got[0].Name).To(Equal(types.Admin))
+		Expect(got[0].Permissions).To(ContainElement(types.RoleManage))
+		Expect(got[2].Permissions).To(Equal([]types.Permission{types.CommentCreate}))
+	})
+
+	It("should not let a mod manage roles", func() {
+		e2e.AuthMidUser(e, modUser)
+
+		code, _, _, err := e2e.Get(ctx, "/roles")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(code).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should reject unknown permissions and names that are not slugs", func() {
+		e2e.AuthMidUser(e, adminUser)
+
+		code, _, _, err := e2e.Post(ctx, "/roles", []byte(`{ "name": "editor", "permissions": ["post:fly"] }`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(code).To(Equal(http.StatusBadRequest))
+
+		code, _, _, err = e2e.Post(ctx, "/roles", []byte(`{ "name": "Chief Editor", "permissions": ["post:create"] }`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("should give a custom role only its permissions", func() {
+		e2e.AuthMidUser(e, adminUser)
+
+		code, body, _, err := e2e.Post(ctx, "/roles", []byte(`{ "name": "comment-moderator", "permissions": ["comment:moderate", "comment:moderate"] }`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(code).To(Equal(http.StatusCreated))
+
+		got := new(dto.RoleResponse)
+		Expect(json.Unmarshal(body, got)).To(Succeed())
+		Expect(got.Permissions).To(Equal([]types.Permission{types.CommentModerate}))
+
+		code, body, _, err = e2e.Post(ctx, "/roles", []byte(`{ "name": "comment-moderator", "permissions": [] }`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(code).To(Equal(http.StatusBadRequest))
+		Expect(apiCode(body)).To(Equal(errorutils.ErrCodeRoleAlreadyExists))
+
+		code, _, _, err = e2e.Put(ctx, fmt.Sprintf("/users/%d/role", user.ID), []byte(`{ "role": "comment-moderator" }`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(code).To(Equal(http.StatusOK))
+
+		e2e.ClearAuthMidUser(e)
+
+		user.Role = "comment-moderator"
+		e2e.AuthMidUser(e, user)
+
+		code, _, _, err = e2e.Get(ctx, "/moderation/comments")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(code).To(Equal(http.StatusOK))
+
+		code, _, _, err = e2e.Post(ctx, "/tags", []byte(`{ "name": "Go" }`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(code).To(Equal(http.StatusUnauthorized))
+
+		code, _, _, err = e2e.Get(ctx, "/users/me")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(code).To(Equal(http.StatusOK))
+	})
+
+	It("should apply edited permissions at once", func() {
+		e2e.AuthMidUser(e, adminUser)
+
+		code, body, _, err := e2e.Put(ctx, "/roles/comment-moderator", []byte(`{ "permissions": ["tag:manage"] }`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(code).To(Equal(http.StatusOK))
+
+		got := new(dto.RoleResponse)
+		Expect(json.Unmarshal(body, got)).To(Succeed())
+		Expect(got.Permissions).To(Equal([]types.Permission{types.TagManage}))
+
+		e2e.ClearAuthMidUser(e)
+		e2e.AuthMidUser(e, user)
+
+		code, _, _, err = e2e.Get(ctx, "/moderation/comments")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(code).To(Equal(http.StatusUnauthorized))
+
+		code, _, _, err = e2e.Post(ctx, "/tags", []byte(`{ "name": "Go" }`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(code).To(Equal(http.StatusCreated))
+
+		testutils.DeleteTags(store.GetInstance())
+	})
+
+	It("should keep the built-in roles", func() {
+		e2e.AuthMidUser(e, adminUser)
+
+		code, body, _, err := e2e.Put(ctx, "/roles/admin", []byte(`{ "permissions": [] }`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(code).To(Equal(http.StatusConflict))
+		Expect(apiCode(body)).To(Equal(errorutils.ErrCodeRoleBuiltIn))
+
+		code, body, _, err = e2e.Delete(ctx, "/roles/mod")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(code).To(Equal(http.StatusConflict))
+		Expect(apiCode(body)).To(Equal(errorutils.ErrCodeRoleBuiltIn))
+	})
+
+	It("should not give users a role that does not exist", func() {
+		e2e.AuthMidUser(e, adminUser)
+
+		code, body, _, err := e2e.Put(ctx, fmt.Sprintf("/users/%d/role", modUser.ID), []byte(`{ "role": "ghost" }`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(code).To(Equal(http.StatusNotFound))
+		Expect(apiCode(body)).To(Equal(errorutils.ErrCodeRoleNotFound))
+	})
+
+	It("should not let a user manager hand out more than their role has", func() {
+		e2e.AuthMidUser(e, adminUser)
+
+		code, _, _, err := e2e.Post(ctx, "/roles", []byte(`{ "name": "user-manager", "permissions": ["user:manage"] }`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(code).To(Equal(http.StatusCreated))
+
+		code, _, _, err = e2e.Put(ctx, fmt.Sprintf("/users/%d/role", manager.ID), []byte(`{ "role": "user-manager" }`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(code).To(Equal(http.StatusOK))
+
+		e2e.ClearAuthMidUser(e)
+
+		manager.Role = "user-manager"
+		e2e.AuthMidUser(e, manager)
+
+		for _, tc := range []struct {
+			id   uint64
+			role types.Role
+		}{
+			{manager.ID, types.Admin},
+			{modUser.ID, types.Admin},
+			{adminUser.ID, types.Registered},
+		} {
+			code, _, _, err = e2e.Put(ctx, fmt.Sprintf("/users/%d/role", tc.id), []byte(fmt.Sprintf(`{ "role": "%s" }`, tc.role)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusUnauthorized))
+		}
+
+		// Nor suspend, delete or purge those whose role has more than theirs.
+		code, _, _, err = e2e.Put(ctx, fmt.Sprintf("/users/%d/status", adminUser.ID), []byte(`{ "status": "passive", "reason": "spam" }`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(code).To(Equal(http.StatusUnauthorized))
+
+		code, _, _, err = e2e.Delete(ctx, fmt.Sprintf("/users/%d", adminUser.ID))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(code).To(Equal(http.StatusUnauthorized))
+
+		code, _, _, err = e2e.Delete(ctx, fmt.Sprintf("/users/%d/purge", modUser.ID))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(code).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should not let a role manager give roles more than their role has", func() {
+		e2e.AuthMidUser(e, adminUser)
+
+		code, _, _, err := e2e.Post(ctx, "/roles", []byte(`{ "name": "role-manager", "permissions": ["role:manage"] }`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(code).To(Equal(http.StatusCreated))
+
+		code, _, _, err = e2e.Put(ctx, fmt.Sprintf("/users/%d/role", roleManager.ID), []byte(`{ "role": "role-manager" }`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(code).To(Equal(http.StatusOK))
+
+		e2e.ClearAuthMidUser(e)
+
+		roleManager.Role = "role-manager"
+		e2e.AuthMidUser(e, roleManager)
+
+		code, _, _, err = e2e.Put(ctx, "/roles/role-manager", []byte(`{ "permissions": ["role:manage", "user:manage"] }`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(code).To(Equal(http.StatusUnauthorized))
+
+		code, _, _, err = e2e.Post(ctx, "/roles", []byte(`{ "name": "user-admin", "permissions": ["user:manage"] }`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(code).To(Equal(http.StatusUnauthorized))
+
+		// Nor can they strip a role that has more than theirs.
+		code, _, _, err = e2e.Put(ctx, "/roles/mod", []byte(`{ "permissions": ["role:manage"] }`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(code).To(Equal(http.StatusUnauthorized))
+
+		code, _, _, err = e2e.Put(ctx, "/roles/role-manager", []byte(`{ "permissions": ["role:manage"] }`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(code).To(Equal(http.StatusOK))
+	})
+
+	It("should delete a role once no user has it", func() {
+		e2e.AuthMidUser(e, adminUser)
+
+		code, body, _, err := e2e.Delete(ctx, "/roles/comment-moderator")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(code).To(Equal(http.StatusConflict))
+		Expect(apiCode(body)).To(Equal(errorutils.ErrCodeRoleInUse))
+
+		code, _, _, err = e2e.Put(ctx, fmt.Sprintf("/users/%d/role", user.ID), []byte(`{ "role": "registered" }`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(code).To(Equal(http.StatusOK))
+
+		code, _, _, err = e2e.Delete(ctx, "/roles/comment-moderator")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(code).To(Equal(http.StatusOK))
+
+		code, _, _, err = e2e.Get(ctx, "/roles/comment-moderator")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(code).To(Equal(http.StatusNotFound))
+	})
+})
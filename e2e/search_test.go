@@ -0,0 +1,139 @@
+package e2e_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/e2e"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/pagination"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/apputils"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/testutils"
+)
+
+var _ = Describe("search", Ordered, func() {
+	ctx := context.Background()
+
+	user := e2e.CreateUserModel(64, types.Registered)
+
+	var users []*model.User
+	users = append(users, user)
+
+	posts := e2e.CreatePostModels(3)
+	posts[0].Title = "Indexing"
+	posts[0].Body = "Postgres indexes make searching fast."
+	posts[1].Title = "Cooking"
+	posts[1].Body = "A recipe for bread."
+	posts[2].Title = "Drafted index"
+	posts[2].Body = "Not published yet."
+	posts[2].State = types.Draft
+
+	comments := []*model.Comment{e2e.CreateCommentModel(0, posts[1], user)}
+	comments[0].Text = "I keep an index of my recipes."
+
+	BeforeAll(func() {
+		testutils.InsertUsers(apputils.ToSliceOfAny(users), store.GetInstance())
+	})
+
+	AfterAll(func() {
+		testutils.DeleteUsers(store.GetInstance())
+	})
+
+	BeforeEach(func() {
+		testutils.InsertPosts(apputils.ToSliceOfAny(posts), store.GetInstance())
+		testutils.InsertComments(comments, store.GetInstance())
+	})
+
+	AfterEach(func() {
+		testutils.DeletePosts(store.GetInstance())
+	})
+
+	testCases := []struct {
+		when      string
+		it        string
+		path      string
+		want      []dto.SearchResultResponse
+		wantTotal string
+		wantCode  int
+		wantErr   *errorutils.APIError
+	}{
+		{
+			when: "term matches a post and a comment",
+			it:   "should rank the title match first",
+			path: "/search?q=indexes",
+			want: []dto.SearchResultResponse{
+				{Type: types.SearchPost, ID: posts[0].ID, PostID: posts[0].ID, Title: "Indexing"},
+				{Type: types.SearchComment, ID: comments[0].ID, PostID: posts[1].ID, Title: "Cooking"},
+			},
+			wantTotal: "2",
+			wantCode:  http.StatusOK,
+		},
+		{
+			when: "only comments are asked for",
+			it:   "should skip posts",
+			path: "/search?q=index&type=comment",
+			want: []dto.SearchResultResponse{
+				{Type: types.SearchComment, ID: comments[0].ID, PostID: posts[1].ID, Title: "Cooking"},
+			},
+			wantTotal: "1",
+			wantCode:  http.StatusOK,
+		},
+		{
+			when:      "paged",
+			it:        "should keep the total count",
+			path:      "/search?q=index&size=1",
+			want:      []dto.SearchResultResponse{{Type: types.SearchPost, ID: posts[0].ID, PostID: posts[0].ID, Title: "Indexing"}},
+			wantTotal: "2",
+			wantCode:  http.StatusOK,
+		},
+		{
+			when:     "unknown type",
+			it:       "should fail",
+			path:     "/search?q=index&type=user",
+			wantCode: http.StatusBadRequest,
+			wantErr:  errorutils.New(errorutils.ErrBadRequest, nil),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		When(tc.when, func() {
+			It(tc.it, func() {
+				code, body, header, err := e2e.Get(ctx, tc.path)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(code).To(Equal(tc.wantCode))
+
+				if tc.want != nil {
+					Expect(header.Get(pagination.HeaderXTotalCount)).To(Equal(tc.wantTotal))
+
+					var got []dto.SearchResultResponse
+					err = json.Unmarshal(body, &got)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(got).To(HaveLen(len(tc.want)))
+
+					for i, w := range tc.want {
+						Expect(got[i].Type).To(Equal(w.Type))
+						Expect(got[i].ID).To(Equal(w.ID))
+						Expect(got[i].PostID).To(Equal(w.PostID))
+						Expect(got[i].Title).To(Equal(w.Title))
+						Expect(got[i].Snippet).To(ContainSubstring("<mark>"))
+					}
+				}
+
+				if tc.wantErr != nil {
+					got := new(errorutils.APIError)
+					err = json.Unmarshal(body, got)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(got).To(Equal(tc.wantErr))
+				}
+			})
+		})
+	}
+})
@@ -44,6 +44,7 @@ func CreateUserModel(i int, role types.Role) *model.User {
 		Role:              role,
 		Username:          fmt.Sprintf("username-%v", i),
 		EncryptedPassword: ep,
+		EmailVerifiedAt:   &date,
 	}
 }
 
@@ -68,7 +69,60 @@ func CreatePostModel(i int) *model.Post {
 			DeletedAt: nil,
 			Status:    types.Active,
 		},
-		Title: fmt.Sprintf("TITLE-%v", i),
-		Body:  fmt.Sprintf("%v-BODY-BODY-BODY-BODY", i),
+		Title:     fmt.Sprintf("TITLE-%v", i),
+		Slug:      fmt.Sprintf("title-%v", i),
+		Body:      fmt.Sprintf("%v-BODY-BODY-BODY-BODY", i),
+		State:     types.Published,
+		PublishAt: &date,
 	}
 }
+
+func CreateCommentModel(i int, p *model.Post, u *model.User) *model.Comment {
+	date := time.Now().Add(-6 * time.Hour).Add(time.Duration(i) * time.Minute)
+
+	return &model.Comment{
+		BaseModel: model.BaseModel{
+			ID:        uint64(i + 100),
+			CreatedAt: date,
+			UpdatedAt: date,
+			CreatedBy: strconv.FormatUint(u.ID, 10),
+			UpdatedBy: strconv.FormatUint(u.ID, 10),
+			Status:    types.Active,
+		},
+		Author: u.Username,
+		PostID: p.ID,
+		UserID: u.ID,
+		Text:   fmt.Sprintf("COMMENT-%v", i),
+		State:  types.Approved,
+	}
+}
+
+func CreateTagModel(i int) *model.Tag {
+	date := time.Now().Add(-6 * time.Hour).Add(time.Duration(i) * time.Minute)
+
+	return &model.Tag{
+		ID:        uint64(i + 100),
+		Name:      fmt.Sprintf("Tag %v", i),
+		Slug:      fmt.Sprintf("tag-%v", i),
+		CreatedAt: date,
+		UpdatedAt: date,
+	}
+}
+
+func CreateCategoryModel(i int, parent *model.Category) *model.Category {
+	date := time.Now().Add(-6 * time.Hour).Add(time.Duration(i) * time.Minute)
+
+	c := &model.Category{
+		ID:        uint64(i + 100),
+		Name:      fmt.Sprintf("Category %v", i),
+		Slug:      fmt.Sprintf("category-%v", i),
+		CreatedAt: date,
+		UpdatedAt: date,
+	}
+
+	if parent != nil {
+		c.ParentID = &parent.ID
+	}
+
+	return c
+}
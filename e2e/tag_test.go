@@ -0,0 +1,239 @@
+package e2e_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/e2e"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/pagination"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/apputils"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/testutils"
+)
+
+var _ = Describe("tags", Ordered, func() {
+	ctx := context.Background()
+
+	user := e2e.CreateUserModel(61, types.Registered)
+	modUser := e2e.CreateUserModel(62, types.Mod)
+
+	var users []*model.User
+	users = append(users, user, modUser)
+
+	tags := []*model.Tag{e2e.CreateTagModel(0), e2e.CreateTagModel(1)}
+	posts := e2e.CreatePostModels(3)
+
+	BeforeAll(func() {
+		testutils.InsertUsers(apputils.ToSliceOfAny(users), store.GetInstance())
+	})
+
+	AfterAll(func() {
+		testutils.DeleteUsers(store.GetInstance())
+	})
+
+	BeforeEach(func() {
+		testutils.InsertTags(tags, store.GetInstance())
+		testutils.InsertPosts(apputils.ToSliceOfAny(posts), store.GetInstance())
+
+		// posts[0] is tagged tag-0, posts[1] tag-1 and posts[2] is untagged.
+		for i, t := range tags {
+			_, err := store.GetInstance().Exec("INSERT INTO post_tags (post_id, tag_id) VALUES ($1, $2)", posts[i].ID, t.ID)
+			Expect(err).ToNot(HaveOccurred())
+		}
+	})
+
+	AfterEach(func() {
+		testutils.DeletePosts(store.GetInstance())
+		testutils.DeleteTags(store.GetInstance())
+	})
+
+	Context("create", func() {
+		testCases := []struct {
+			when     string
+			it       string
+			json     string
+			authUser *model.User
+			want     *dto.TagResponse
+			wantCode int
+			wantErr  *errorutils.APIError
+			wantErrs *errorutils.APIErrors
+		}{
+			{
+				when:     "by mod",
+				it:       "should slugify the name",
+				json:     `{ "name": "Göç Yolları" }`,
+				authUser: modUser,
+				want:     &dto.TagResponse{Name: "Göç Yolları", Slug: "goc-yollari", CreatedBy: "62", UpdatedBy: "62"},
+				wantCode: http.StatusCreated,
+			},
+			{
+				when:     "name already used",
+				it:       "should fail",
+				json:     `{ "name": "TAG 0" }`,
+				authUser: modUser,
+				wantCode: http.StatusBadRequest,
+				wantErr:  errorutils.New(errorutils.ErrTagAlreadyExists, nil),
+			},
+			{
+				when:     "name empty",
+				it:       "should fail",
+				json:     `{}`,
+				authUser: modUser,
+				wantCode: http.StatusBadRequest,
+				wantErrs: &errorutils.APIErrors{Errors: []*errorutils.APIError{
+					errorutils.New(errorutils.Required("Name"), nil),
+				}},
+			},
+			{
+				when:     "by registered user",
+				it:       "should fail",
+				json:     `{ "name": "golang" }`,
+				authUser: user,
+				wantCode: http.StatusUnauthorized,
+				wantErr:  errorutils.New(errorutils.ErrUnauthorized, nil),
+			},
+		}
+
+		for _, tc := range testCases {
+			tc := tc
+			When(tc.when, func() {
+				AfterEach(func() {
+					e2e.ClearAuthMidUser(e)
+				})
+				It(tc.it, func() {
+					e2e.AuthMidUser(e, tc.authUser)
+
+					code, body, _, err := e2e.Post(ctx, "/tags", []byte(tc.json))
+					Expect(err).ToNot(HaveOccurred())
+					Expect(code).To(Equal(tc.wantCode))
+
+					if tc.want != nil {
+						got := new(dto.TagResponse)
+						err = json.Unmarshal(body, got)
+						Expect(err).ToNot(HaveOccurred())
+
+						if diff := cmp.Diff(tc.want, got, cmpopts.IgnoreTypes(time.Time{}), cmpopts.IgnoreFields(dto.TagResponse{}, "ID")); diff != "" {
+							Expect(diff).To(BeEmpty())
+						}
+					}
+
+					if tc.wantErr != nil {
+						got := new(errorutils.APIError)
+						err = json.Unmarshal(body, got)
+						Expect(err).ToNot(HaveOccurred())
+
+						if diff := cmp.Diff(tc.wantErr, got); diff != "" {
+							Expect(diff).To(BeEmpty())
+						}
+					}
+
+					if tc.wantErrs != nil {
+						got := new(errorutils.APIErrors)
+						err = json.Unmarshal(body, got)
+						Expect(err).ToNot(HaveOccurred())
+
+						if diff := cmp.Diff(tc.wantErrs, got); diff != "" {
+							Expect(diff).To(BeEmpty())
+						}
+					}
+				})
+			})
+		}
+	})
+
+	Context("posts by tag", func() {
+		testCases := []struct {
+			when      string
+			it        string
+			path      string
+			wantIDs   []uint64
+			wantTotal string
+		}{
+			{
+				when:      "tag has posts",
+				it:        "should only return the tagged posts",
+				path:      "/posts?tag=tag-0",
+				wantIDs:   []uint64{posts[0].ID},
+				wantTotal: "1",
+			},
+			{
+				when:      "unknown tag",
+				it:        "should return nothing",
+				path:      "/posts?tag=nope",
+				wantTotal: "0",
+			},
+			{
+				when:      "no tag",
+				it:        "should return every post",
+				path:      "/posts?sort=createdAt,asc",
+				wantIDs:   []uint64{posts[0].ID, posts[1].ID, posts[2].ID},
+				wantTotal: "3",
+			},
+		}
+
+		for _, tc := range testCases {
+			tc := tc
+			When(tc.when, func() {
+				It(tc.it, func() {
+					code, body, header, err := e2e.Get(ctx, tc.path)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(code).To(Equal(http.StatusOK))
+					Expect(header.Get(pagination.HeaderXTotalCount)).To(Equal(tc.wantTotal))
+
+					var got []dto.PostResponse
+					err = json.Unmarshal(body, &got)
+					Expect(err).ToNot(HaveOccurred())
+
+					var ids []uint64
+					for _, p := range got {
+						ids = append(ids, p.ID)
+					}
+
+					Expect(ids).To(Equal(tc.wantIDs))
+				})
+			})
+		}
+	})
+
+	Context("create post with tags", func() {
+		It("should attach the tags", func() {
+			e2e.AuthMidUser(e, modUser)
+			defer e2e.ClearAuthMidUser(e)
+
+			code, _, _, err := e2e.Post(ctx, "/posts", []byte(`{ "title": "Tagged", "body": "body", "state": "published", "tags": ["Tag 1", "tag-0"] }`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusCreated))
+
+			code, body, _, err := e2e.Get(ctx, "/posts/slug/tagged")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+
+			got := new(dto.PostResponse)
+			err = json.Unmarshal(body, got)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(got.Tags).To(Equal([]*dto.PostTagResponse{
+				{ID: tags[0].ID, Name: tags[0].Name, Slug: tags[0].Slug},
+				{ID: tags[1].ID, Name: tags[1].Name, Slug: tags[1].Slug},
+			}))
+		})
+
+		It("should reject unknown tags", func() {
+			e2e.AuthMidUser(e, modUser)
+			defer e2e.ClearAuthMidUser(e)
+
+			code, _, _, err := e2e.Post(ctx, "/posts", []byte(`{ "title": "Tagged", "body": "body", "tags": ["nope"] }`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusNotFound))
+		})
+	})
+})
@@ -3,14 +3,20 @@ package e2e
 import (
 	"bytes"
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
 	"io"
 	"net/http"
+	"sync"
 
 	"github.com/labstack/echo/v4"
 
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/appcontext"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/jwtauth"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/validatorutils"
 )
@@ -19,6 +25,7 @@ const baseURL = "http://localhost:8080/v1"
 
 func InitEcho(middlewares ...func(next echo.HandlerFunc) echo.HandlerFunc) *echo.Echo {
 	e := echo.New()
+	e.IPExtractor = echo.ExtractIPDirect()
 	e.Validator = validatorutils.NewValidator()
 	e.HTTPErrorHandler = errorutils.Handler
 
@@ -29,9 +36,65 @@ func InitEcho(middlewares ...func(next echo.HandlerFunc) echo.HandlerFunc) *echo
 	return e
 }
 
-func AuthMid() func(next echo.HandlerFunc) echo.HandlerFunc {
+// Outbox collects what is written to it, such as the mails of a log mailer,
+// and may be read while the server still writes.
+type Outbox struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (o *Outbox) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.buf.Write(p)
+}
+
+func (o *Outbox) String() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.buf.String()
+}
+
+func (o *Outbox) Reset() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.buf.Reset()
+}
+
+// NewSigner returns a token signer over a fresh EdDSA key with the id kid.
+func NewSigner(kid string) (*jwtauth.Signer, error) {
+	_, pk, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := x509.MarshalPKCS8PrivateKey(pk)
+	if err != nil {
+		return nil, err
+	}
+
+	k, err := jwtauth.NewKey(kid, jwtauth.EdDSA, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: b}))
+	if err != nil {
+		return nil, err
+	}
+
+	return jwtauth.New("mts-blog-api", "mts-blog-api", 0, k)
+}
+
+// AuthMid authenticates requests with an Authorization header through authenticate
+// and lets the others through, signed in as AuthMidUser says.
+func AuthMid(authenticate echo.MiddlewareFunc) func(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		authenticated := authenticate(next)
+
 		return func(c echo.Context) error {
+			if c.Request().Header.Get("Authorization") != "" {
+				return authenticated(c)
+			}
+
 			return next(c)
 		}
 	}
@@ -41,10 +104,11 @@ func AuthMidUser(e *echo.Echo, u *model.User) {
 	mid := func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			claims := &dto.Claims{
-				UID:      u.ID,
-				Role:     u.Role,
-				Username: u.Username,
-				Email:    u.Email,
+				UID:           u.ID,
+				Role:          u.Role,
+				Username:      u.Username,
+				Email:         u.Email,
+				EmailVerified: u.EmailVerifiedAt != nil,
 			}
 
 			// Use custom context functions to store values
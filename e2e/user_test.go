@@ -3,8 +3,10 @@ package e2e_test
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
@@ -244,10 +246,12 @@ var _ = Describe("user", Ordered, func() {
 				id:         user.ID,
 				updateJSON: `{ "username": "samil" }`,
 				want: &dto.UserResponse{
-					ID:       user.ID,
-					Username: "samil",
-					Role:     types.Registered,
-					Status:   types.Active,
+					ID:        user.ID,
+					Username:  "samil",
+					Role:      types.Registered,
+					Status:    types.Active,
+					CreatedBy: strconv.FormatUint(user.ID, 10),
+					UpdatedBy: strconv.FormatUint(user.ID, 10),
 				},
 				wantCode: http.StatusOK,
 			},
@@ -276,9 +280,11 @@ var _ = Describe("user", Ordered, func() {
 				id:         user.ID,
 				updateJSON: `{ "username": "samil-admin" }`,
 				want: &dto.UserResponse{
-					ID:       user.ID,
-					Username: "samil-admin",
-					Role:     types.Registered,
+					ID:        user.ID,
+					Username:  "samil-admin",
+					Role:      types.Registered,
+					CreatedBy: strconv.FormatUint(user.ID, 10),
+					UpdatedBy: strconv.FormatUint(adminUser.ID, 10),
 				},
 				wantCode: http.StatusOK,
 			},
@@ -355,7 +361,7 @@ var _ = Describe("user", Ordered, func() {
 						err = json.Unmarshal(body, got)
 						Expect(err).ToNot(HaveOccurred())
 
-						if diff := cmp.Diff(tc.want, got, cmpopts.IgnoreFields(dto.UserResponse{}, "CreatedAt", "UpdatedAt", "DeletedAt", "Email", "Status")); diff != "" {
+						if diff := cmp.Diff(tc.want, got, cmpopts.IgnoreFields(dto.UserResponse{}, "CreatedAt", "UpdatedAt", "DeletedAt", "Email", "EmailVerifiedAt", "Status")); diff != "" {
 							Expect(diff).To(BeEmpty())
 						}
 					}
@@ -477,4 +483,472 @@ var _ = Describe("user", Ordered, func() {
 			})
 		}
 	})
+
+	Context("me", func() {
+		BeforeEach(func() {
+			outbox.Reset()
+		})
+
+		AfterEach(func() {
+			e2e.ClearAuthMidUser(e)
+		})
+
+		login := func(email, password string) (int, *dto.WithTokenResponse) {
+			code, body, _, err := e2e.Post(ctx, "/auth/login", []byte(fmt.Sprintf(`{ "email": "%s", "password": "%s" }`, email, password)))
+			Expect(err).ToNot(HaveOccurred())
+
+			got := new(dto.WithTokenResponse)
+			if code == http.StatusOK {
+				Expect(json.Unmarshal(body, got)).To(Succeed())
+			}
+
+			return code, got
+		}
+
+		refresh := func(token string) int {
+			code, _, _, err := e2e.Post(ctx, "/auth/refresh", []byte(fmt.Sprintf(`{ "refreshToken": "%s" }`, token)))
+			Expect(err).ToNot(HaveOccurred())
+
+			return code
+		}
+
+		It("should change the password, end other sessions and revoke the API keys", func() {
+			code, session := login(user.Email, "12341234")
+			Expect(code).To(Equal(http.StatusOK))
+
+			e2e.AuthMidUser(e, user)
+
+			code, body, _, err := e2e.Post(ctx, "/users/me/api-keys", []byte(`{ "name": "ci", "scopes": ["posts:read"], "expiresAt": "2099-01-01T00:00:00Z" }`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusCreated))
+
+			key := new(dto.APIKeyCreateResponse)
+			Expect(json.Unmarshal(body, key)).To(Succeed())
+
+			code, _, _, err = e2e.Put(ctx, "/users/me/password", []byte(`{ "currentPassword": "wrong-password", "newPassword": "43214321" }`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusBadRequest))
+
+			code, _, _, err = e2e.Put(ctx, "/users/me/password", []byte(`{ "currentPassword": "12341234", "newPassword": "43214321" }`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusNoContent))
+
+			code, _ = login(user.Email, "43214321")
+			Expect(code).To(Equal(http.StatusOK))
+			Expect(refresh(session.RefreshToken)).To(Equal(http.StatusUnauthorized))
+
+			code, _, _, err = e2e.Get(ctx, "/posts", map[string]string{"Authorization": "Bearer " + key.Key})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusUnauthorized))
+		})
+
+		It("should count wrong current passwords towards the lockout", func() {
+			e2e.AuthMidUser(e, user)
+
+			for i := 0; i < 2; i++ {
+				code, _, _, err := e2e.Put(ctx, "/users/me/password", []byte(`{ "currentPassword": "wrong-password", "newPassword": "43214321" }`))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(code).To(Equal(http.StatusBadRequest))
+			}
+
+			code, _, _, err := e2e.Put(ctx, "/users/me/email", []byte(`{ "email": "samil@samilov.com", "password": "wrong-password" }`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusBadRequest))
+
+			// Locked out now, even with the right password.
+			code, body, _, err := e2e.Put(ctx, "/users/me/password", []byte(`{ "currentPassword": "12341234", "newPassword": "43214321" }`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusTooManyRequests))
+
+			got := new(errorutils.APIError)
+			Expect(json.Unmarshal(body, got)).To(Succeed())
+			Expect(got.Code).To(Equal(errorutils.ErrCodeLoginLocked))
+
+			code, _ = login(user.Email, "12341234")
+			Expect(code).To(Equal(http.StatusTooManyRequests))
+		})
+
+		It("should change the email and ask to verify it", func() {
+			e2e.AuthMidUser(e, user)
+
+			code, body, _, err := e2e.Put(ctx, "/users/me/email", []byte(`{ "email": "samil@samilov.com", "password": "12341234" }`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+
+			got := new(dto.UserResponse)
+			Expect(json.Unmarshal(body, got)).To(Succeed())
+			Expect(got.Email).To(Equal("samil@samilov.com"))
+			Expect(got.EmailVerifiedAt).To(BeNil())
+			Expect(outbox.String()).To(And(ContainSubstring("To: samil@samilov.com"), ContainSubstring("/v1/auth/verify?token=")))
+		})
+
+		It("should not take an email in use", func() {
+			e2e.AuthMidUser(e, user)
+
+			code, body, _, err := e2e.Put(ctx, "/users/me/email", []byte(fmt.Sprintf(`{ "email": "%s", "password": "12341234" }`, adminUser.Email)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusBadRequest))
+
+			got := new(errorutils.APIError)
+			Expect(json.Unmarshal(body, got)).To(Succeed())
+			Expect(got.Code).To(Equal(errorutils.ErrCodeEmailAlreadyTaken))
+		})
+
+		It("should ask for the current password", func() {
+			e2e.AuthMidUser(e, user)
+
+			code, _, _, err := e2e.Put(ctx, "/users/me/email", []byte(`{ "email": "samil@samilov.com", "password": "wrong-password" }`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("should read the caller with their counts", func() {
+			post := e2e.CreatePostModel(1)
+			post.UserID = user.ID
+			deleted := e2e.CreatePostModel(2)
+			deleted.UserID = user.ID
+			deleted.Slug = "deleted"
+
+			testutils.InsertPosts([]*model.Post{post, deleted}, store.GetInstance())
+			testutils.InsertComments([]*model.Comment{e2e.CreateCommentModel(1, post, user), e2e.CreateCommentModel(2, post, user), e2e.CreateCommentModel(3, post, adminUser)}, store.GetInstance())
+			defer testutils.DeletePosts(store.GetInstance())
+
+			_, err := store.GetInstance().Exec("UPDATE posts SET deleted_at = NOW() WHERE id = $1", deleted.ID)
+			Expect(err).ToNot(HaveOccurred())
+
+			e2e.AuthMidUser(e, user)
+
+			code, body, header, err := e2e.Get(ctx, "/users/me")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+			Expect(header.Get("ETag")).ToNot(BeEmpty())
+
+			got := new(dto.MeResponse)
+			Expect(json.Unmarshal(body, got)).To(Succeed())
+			Expect(got.ID).To(Equal(user.ID))
+			Expect(got.Email).To(Equal(user.Email))
+			Expect(got.PostCount).To(Equal(1))
+			Expect(got.CommentCount).To(Equal(2))
+		})
+
+		It("should update the caller", func() {
+			e2e.AuthMidUser(e, user)
+
+			code, body, _, err := e2e.Put(ctx, "/users/me", []byte(`{ "username": "renamed" }`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+
+			got := new(dto.MeResponse)
+			Expect(json.Unmarshal(body, got)).To(Succeed())
+			Expect(got.ID).To(Equal(user.ID))
+			Expect(got.Username).To(Equal("renamed"))
+		})
+
+		It("should delete the caller and end their sessions", func() {
+			code, session := login(user.Email, "12341234")
+			Expect(code).To(Equal(http.StatusOK))
+
+			e2e.AuthMidUser(e, user)
+
+			code, _, _, err := e2e.Delete(ctx, "/users/me")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusNoContent))
+
+			Expect(refresh(session.RefreshToken)).To(Equal(http.StatusUnauthorized))
+
+			code, _ = login(user.Email, "12341234")
+			Expect(code).ToNot(Equal(http.StatusOK))
+		})
+	})
+
+	Context("role and status", func() {
+		AfterEach(func() {
+			e2e.ClearAuthMidUser(e)
+		})
+
+		login := func(email string) (int, []byte) {
+			code, body, _, err := e2e.Post(ctx, "/auth/login", []byte(fmt.Sprintf(`{ "email": "%s", "password": "12341234" }`, email)))
+			Expect(err).ToNot(HaveOccurred())
+
+			return code, body
+		}
+
+		It("should change the role of a user", func() {
+			e2e.AuthMidUser(e, adminUser)
+
+			code, body, _, err := e2e.Put(ctx, fmt.Sprintf("/users/%d/role", user.ID), []byte(`{ "role": "mod" }`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+
+			got := new(dto.UserResponse)
+			Expect(json.Unmarshal(body, got)).To(Succeed())
+			Expect(got.Role).To(Equal(types.Mod))
+		})
+
+		It("should not let a mod change roles", func() {
+			e2e.AuthMidUser(e, modUser)
+
+			code, _, _, err := e2e.Put(ctx, fmt.Sprintf("/users/%d/role", user.ID), []byte(`{ "role": "admin" }`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusUnauthorized))
+		})
+
+		It("should keep the last admin", func() {
+			e2e.AuthMidUser(e, adminUser)
+
+			code, body, _, err := e2e.Put(ctx, fmt.Sprintf("/users/%d/role", adminUser.ID), []byte(`{ "role": "registered" }`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusConflict))
+
+			got := new(errorutils.APIError)
+			Expect(json.Unmarshal(body, got)).To(Succeed())
+			Expect(got.Code).To(Equal(errorutils.ErrCodeLastAdmin))
+
+			code, _, _, err = e2e.Put(ctx, fmt.Sprintf("/users/%d/status", adminUser.ID), []byte(`{ "status": "passive", "reason": "leaving" }`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusConflict))
+
+			code, _, _, err = e2e.Delete(ctx, fmt.Sprintf("/users/%d", adminUser.ID))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusConflict))
+
+			code, _, _, err = e2e.Delete(ctx, "/users/me")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusConflict))
+		})
+
+		It("should keep one of two admins suspended at the same time", func() {
+			otherAdmin := e2e.CreateUserModel(59, types.Admin)
+			testutils.InsertUsers(apputils.ToSliceOfAny([]*model.User{otherAdmin}), store.GetInstance())
+
+			e2e.AuthMidUser(e, adminUser)
+
+			admins := []*model.User{adminUser, otherAdmin}
+			codes := make([]int, len(admins))
+
+			var wg sync.WaitGroup
+
+			for i, a := range admins {
+				wg.Add(1)
+
+				go func(i int, a *model.User) {
+					defer GinkgoRecover()
+					defer wg.Done()
+
+					code, _, _, err := e2e.Put(ctx, fmt.Sprintf("/users/%d/status", a.ID), []byte(`{ "status": "passive", "reason": "leaving" }`))
+					Expect(err).ToNot(HaveOccurred())
+
+					codes[i] = code
+				}(i, a)
+			}
+
+			wg.Wait()
+
+			Expect(codes).To(ConsistOf(http.StatusOK, http.StatusConflict))
+		})
+
+		It("should let an admin step down once there is another", func() {
+			e2e.AuthMidUser(e, adminUser)
+
+			code, _, _, err := e2e.Put(ctx, fmt.Sprintf("/users/%d/role", modUser.ID), []byte(`{ "role": "admin" }`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+
+			code, _, _, err = e2e.Put(ctx, fmt.Sprintf("/users/%d/role", adminUser.ID), []byte(`{ "role": "registered" }`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+		})
+
+		It("should suspend a user and lift it", func() {
+			e2e.AuthMidUser(e, adminUser)
+
+			code, _, _, err := e2e.Put(ctx, fmt.Sprintf("/users/%d/status", user.ID), []byte(`{ "status": "passive" }`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusBadRequest))
+
+			code, body, _, err := e2e.Put(ctx, fmt.Sprintf("/users/%d/status", user.ID), []byte(`{ "status": "passive", "reason": "spam", "until": "2099-01-01T00:00:00Z" }`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+
+			got := new(dto.UserResponse)
+			Expect(json.Unmarshal(body, got)).To(Succeed())
+			Expect(got.Status).To(Equal(types.Passive))
+			Expect(got.SuspendedReason).To(Equal("spam"))
+			Expect(got.SuspendedUntil).ToNot(BeNil())
+
+			code, body = login(user.Email)
+			Expect(code).To(Equal(http.StatusUnauthorized))
+
+			apiErr := new(errorutils.APIError)
+			Expect(json.Unmarshal(body, apiErr)).To(Succeed())
+			Expect(apiErr.Code).To(Equal(errorutils.ErrCodeUserDisabled))
+
+			code, body, _, err = e2e.Put(ctx, fmt.Sprintf("/users/%d/status", user.ID), []byte(`{ "status": "active" }`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+
+			got = new(dto.UserResponse)
+			Expect(json.Unmarshal(body, got)).To(Succeed())
+			Expect(got.Status).To(Equal(types.Active))
+			Expect(got.SuspendedReason).To(BeEmpty())
+
+			code, _ = login(user.Email)
+			Expect(code).To(Equal(http.StatusOK))
+		})
+	})
+
+	Context("api keys", func() {
+		AfterEach(func() {
+			e2e.ClearAuthMidUser(e)
+		})
+
+		create := func(body string) (int, *dto.APIKeyCreateResponse) {
+			code, res, _, err := e2e.Post(ctx, "/users/me/api-keys", []byte(body))
+			Expect(err).ToNot(HaveOccurred())
+
+			got := new(dto.APIKeyCreateResponse)
+			if code == http.StatusCreated {
+				Expect(json.Unmarshal(res, got)).To(Succeed())
+			}
+
+			return code, got
+		}
+
+		list := func() []*dto.APIKeyResponse {
+			code, body, _, err := e2e.Get(ctx, "/users/me/api-keys")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+
+			var got []*dto.APIKeyResponse
+			Expect(json.Unmarshal(body, &got)).To(Succeed())
+
+			return got
+		}
+
+		It("should create, list and revoke a key", func() {
+			e2e.AuthMidUser(e, user)
+
+			code, key := create(`{ "name": "ci", "scopes": ["posts:write"], "expiresAt": "2099-01-01T00:00:00Z" }`)
+			Expect(code).To(Equal(http.StatusCreated))
+			Expect(key.Key).To(HavePrefix(key.Prefix))
+			Expect(key.Scopes).To(Equal([]string{"posts:write"}))
+
+			keys := list()
+			Expect(keys).To(HaveLen(1))
+			Expect(keys[0].ID).To(Equal(key.ID))
+			Expect(keys[0].Name).To(Equal("ci"))
+
+			code, body, _, err := e2e.Get(ctx, "/users/me/api-keys")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusOK))
+			Expect(string(body)).ToNot(ContainSubstring(key.Key))
+
+			code, _, _, err = e2e.Delete(ctx, fmt.Sprintf("/users/me/api-keys/%d", key.ID))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusNoContent))
+			Expect(list()).To(BeEmpty())
+
+			code, _, _, err = e2e.Delete(ctx, fmt.Sprintf("/users/me/api-keys/%d", key.ID))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusNotFound))
+		})
+
+		It("should not create a key that is expired or has unknown scopes", func() {
+			e2e.AuthMidUser(e, user)
+
+			code, _ := create(`{ "name": "ci", "scopes": ["posts:write"], "expiresAt": "2000-01-01T00:00:00Z" }`)
+			Expect(code).To(Equal(http.StatusBadRequest))
+
+			code, _ = create(`{ "name": "ci", "scopes": ["users:write"], "expiresAt": "2099-01-01T00:00:00Z" }`)
+			Expect(code).To(Equal(http.StatusBadRequest))
+
+			code, _ = create(`{ "name": "ci", "scopes": [], "expiresAt": "2099-01-01T00:00:00Z" }`)
+			Expect(code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("should not revoke the key of another user", func() {
+			e2e.AuthMidUser(e, anotherUser)
+
+			code, key := create(`{ "name": "deploy", "scopes": ["posts:read"], "expiresAt": "2099-01-01T00:00:00Z" }`)
+			Expect(code).To(Equal(http.StatusCreated))
+
+			e2e.AuthMidUser(e, user)
+
+			code, _, _, err := e2e.Delete(ctx, fmt.Sprintf("/users/me/api-keys/%d", key.ID))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusNotFound))
+		})
+
+		It("should authenticate a key within its scopes and reject the rest", func() {
+			defer testutils.DeletePosts(store.GetInstance())
+
+			e2e.AuthMidUser(e, modUser)
+
+			code, writer := create(`{ "name": "publisher", "scopes": ["posts:write"], "expiresAt": "2099-01-01T00:00:00Z" }`)
+			Expect(code).To(Equal(http.StatusCreated))
+
+			code, reader := create(`{ "name": "reader", "scopes": ["posts:read"], "expiresAt": "2099-01-01T00:00:00Z" }`)
+			Expect(code).To(Equal(http.StatusCreated))
+
+			code, expired := create(`{ "name": "old", "scopes": ["posts:write"], "expiresAt": "2099-01-01T00:00:00Z" }`)
+			Expect(code).To(Equal(http.StatusCreated))
+
+			code, revoked := create(`{ "name": "gone", "scopes": ["posts:write"], "expiresAt": "2099-01-01T00:00:00Z" }`)
+			Expect(code).To(Equal(http.StatusCreated))
+
+			code, _, _, err := e2e.Delete(ctx, fmt.Sprintf("/users/me/api-keys/%d", revoked.ID))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusNoContent))
+
+			_, err = store.GetInstance().Exec("UPDATE api_keys SET expires_at = now() - interval '1 hour' WHERE id = $1", expired.ID)
+			Expect(err).ToNot(HaveOccurred())
+
+			e2e.ClearAuthMidUser(e)
+
+			bearer := func(key string) map[string]string {
+				return map[string]string{"Authorization": "Bearer " + key}
+			}
+
+			expectErr := func(code int, body []byte, wantCode int, wantErr error) {
+				Expect(code).To(Equal(wantCode))
+
+				got := new(errorutils.APIError)
+				Expect(json.Unmarshal(body, got)).To(Succeed())
+
+				if diff := cmp.Diff(errorutils.New(wantErr, nil), got); diff != "" {
+					Expect(diff).To(BeEmpty())
+				}
+			}
+
+			post := []byte(`{ "title": "Keyed", "body": "body" }`)
+
+			code, body, _, err := e2e.Post(ctx, "/posts", post, bearer(writer.Key))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(code).To(Equal(http.StatusCreated))
+
+			got := new(dto.PostResponse)
+			Expect(json.Unmarshal(body, got)).To(Succeed())
+			Expect(got.Author.ID).To(Equal(modUser.ID))
+
+			code, body, _, err = e2e.Post(ctx, "/posts", post, bearer(reader.Key))
+			Expect(err).ToNot(HaveOccurred())
+			expectErr(code, body, http.StatusForbidden, errorutils.ErrAPIKeyScope)
+
+			code, body, _, err = e2e.Get(ctx, "/users/me", bearer(writer.Key))
+			Expect(err).ToNot(HaveOccurred())
+			expectErr(code, body, http.StatusForbidden, errorutils.ErrAPIKeyScope)
+
+			code, body, _, err = e2e.Post(ctx, "/posts", post, bearer("mtsk_unknown"))
+			Expect(err).ToNot(HaveOccurred())
+			expectErr(code, body, http.StatusUnauthorized, errorutils.ErrInvalidToken)
+
+			code, body, _, err = e2e.Post(ctx, "/posts", post, bearer(expired.Key))
+			Expect(err).ToNot(HaveOccurred())
+			expectErr(code, body, http.StatusUnauthorized, errorutils.ErrExpiredToken)
+
+			code, body, _, err = e2e.Post(ctx, "/posts", post, bearer(revoked.Key))
+			Expect(err).ToNot(HaveOccurred())
+			expectErr(code, body, http.StatusUnauthorized, errorutils.ErrRevokedToken)
+		})
+	})
 })
@@ -0,0 +1,113 @@
+package postgresadapter
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/repository"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
+)
+
+const apiKeyColumns = "id, user_id, name, prefix, key_hash, scopes, created_at, expires_at, last_used_at, revoked_at"
+
+type apiKeyRepository struct {
+	db *sql.DB
+}
+
+func NewAPIKeyRepository(db *sql.DB) repository.APIKey {
+	return &apiKeyRepository{
+		db: db,
+	}
+}
+
+func (r *apiKeyRepository) Create(k *model.APIKey) error {
+	err := r.db.QueryRow(`INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, created_at, expires_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`,
+		k.UserID, k.Name, k.Prefix, k.KeyHash, pq.Array(k.Scopes), k.CreatedAt, k.ExpiresAt).Scan(&k.ID)
+	if err != nil {
+		return errorutils.New(errorutils.ErrAPIKeyCreate, err)
+	}
+
+	return nil
+}
+
+func (r *apiKeyRepository) ReadByHash(hash string) (*model.APIKey, error) {
+	rows, err := r.db.Query("SELECT "+apiKeyColumns+" FROM api_keys WHERE key_hash = $1", hash)
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrAPIKeyRead, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		k, err := scanIntoAPIKey(rows)
+		if err != nil {
+			return nil, errorutils.New(errorutils.ErrAPIKeyRead, err)
+		}
+
+		return k, nil
+	}
+
+	return nil, errorutils.New(errorutils.ErrInvalidToken, nil)
+}
+
+func (r *apiKeyRepository) ReadsByUserID(userID uint64) (*[]model.APIKey, error) {
+	rows, err := r.db.Query("SELECT "+apiKeyColumns+" FROM api_keys WHERE user_id = $1 AND revoked_at IS NULL ORDER BY id", userID)
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrAPIKeyRead, err)
+	}
+	defer rows.Close()
+
+	keys := []model.APIKey{}
+
+	for rows.Next() {
+		k, err := scanIntoAPIKey(rows)
+		if err != nil {
+			return nil, errorutils.New(errorutils.ErrAPIKeyRead, err)
+		}
+
+		keys = append(keys, *k)
+	}
+
+	return &keys, nil
+}
+
+func (r *apiKeyRepository) Revoke(id, userID uint64, at time.Time) error {
+	res, err := r.db.Exec("UPDATE api_keys SET revoked_at = $1 WHERE id = $2 AND user_id = $3 AND revoked_at IS NULL", at, id, userID)
+	if err != nil {
+		return errorutils.New(errorutils.ErrAPIKeyUpdate, err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return errorutils.New(errorutils.ErrAPIKeyNotFound, errorutils.ErrAPIKeyUpdate)
+	}
+
+	return nil
+}
+
+func (r *apiKeyRepository) RevokeAll(userID uint64, at time.Time) error {
+	_, err := r.db.Exec("UPDATE api_keys SET revoked_at = $1 WHERE user_id = $2 AND revoked_at IS NULL", at, userID)
+	if err != nil {
+		return errorutils.New(errorutils.ErrAPIKeyUpdate, err)
+	}
+
+	return nil
+}
+
+func (r *apiKeyRepository) Touch(id uint64, at time.Time) error {
+	_, err := r.db.Exec("UPDATE api_keys SET last_used_at = $1 WHERE id = $2", at, id)
+	if err != nil {
+		return errorutils.New(errorutils.ErrAPIKeyUpdate, err)
+	}
+
+	return nil
+}
+
+func scanIntoAPIKey(rows *sql.Rows) (*model.APIKey, error) {
+	k := new(model.APIKey)
+	err := rows.Scan(&k.ID, &k.UserID, &k.Name, &k.Prefix, &k.KeyHash, pq.Array(&k.Scopes), &k.CreatedAt, &k.ExpiresAt, &k.LastUsedAt, &k.RevokedAt)
+
+	return k, err
+}
@@ -0,0 +1,181 @@
+package postgresadapter
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/repository"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/pagination"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
+)
+
+// categoryColumns is the column list scanIntoCategory expects, in order.
+const categoryColumns = `id, parent_id, name, slug, created_at, updated_at, created_by, updated_by`
+
+type categoryRepository struct {
+	db *sql.DB
+}
+
+func NewCategoryRepository(db *sql.DB) repository.Category {
+	return &categoryRepository{
+		db: db,
+	}
+}
+
+func (r *categoryRepository) Create(c *model.Category) error {
+	err := r.db.QueryRow(`INSERT INTO categories (parent_id, name, slug, created_at, updated_at, created_by, updated_by)
+	VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`,
+		c.ParentID, c.Name, c.Slug, c.CreatedAt, c.UpdatedAt, c.CreatedBy, c.UpdatedBy).Scan(&c.ID)
+	if err != nil {
+		return categoryError(err, errorutils.ErrCategoryCreate)
+	}
+
+	return nil
+}
+
+func (r *categoryRepository) Read(id uint64) (*model.Category, error) {
+	rows, err := r.db.Query("SELECT "+categoryColumns+" FROM categories WHERE id = $1", id)
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrInvalidRequest, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		c, err := scanIntoCategory(rows)
+		if err != nil {
+			return nil, errorutils.New(errorutils.ErrCategoryNotFound, err)
+		}
+
+		return c, nil
+	}
+
+	return nil, errorutils.New(errorutils.ErrCategoryNotFound, errorutils.ErrCategoryRead)
+}
+
+func (r *categoryRepository) ReadsBySlugs(slugs []string) ([]model.Category, error) {
+	rows, err := r.db.Query("SELECT "+categoryColumns+" FROM categories WHERE slug = ANY($1) ORDER BY name", pq.Array(slugs))
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrCategoryReads, err)
+	}
+	defer rows.Close()
+
+	var categories []model.Category
+
+	for rows.Next() {
+		c, err := scanIntoCategory(rows)
+		if err != nil {
+			return nil, errorutils.New(errorutils.ErrCategoryReads, err)
+		}
+
+		categories = append(categories, *c)
+	}
+
+	return categories, nil
+}
+
+func (r *categoryRepository) Reads(p *pagination.Pageable) (*[]model.Category, error) {
+	rows, err := r.db.Query("SELECT "+categoryColumns+", COUNT(*) OVER() AS count FROM categories ORDER BY "+
+		fmt.Sprintf("%s LIMIT $1 OFFSET $2;", p.Order()), p.Size, p.Offset())
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrCategoryReads, err)
+	}
+	defer rows.Close()
+
+	var categories []model.Category
+
+	var count int64
+
+	for rows.Next() {
+		c := new(model.Category)
+
+		err := rows.Scan(&c.ID, &c.ParentID, &c.Name, &c.Slug, &c.CreatedAt, &c.UpdatedAt, &c.CreatedBy, &c.UpdatedBy, &count)
+		if err != nil {
+			return nil, errorutils.New(errorutils.ErrCategoryReads, err)
+		}
+
+		categories = append(categories, *c)
+	}
+
+	p.TotalCount = count
+
+	return &categories, nil
+}
+
+func (r *categoryRepository) AncestorIDs(id uint64) ([]uint64, error) {
+	rows, err := r.db.Query(`WITH RECURSIVE ancestors AS (
+		SELECT parent_id FROM categories WHERE id = $1
+		UNION
+		SELECT c.parent_id FROM categories c JOIN ancestors a ON c.id = a.parent_id
+	) SELECT parent_id FROM ancestors WHERE parent_id IS NOT NULL`, id)
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrCategoryRead, err)
+	}
+	defer rows.Close()
+
+	var ids []uint64
+
+	for rows.Next() {
+		var pid uint64
+		if err := rows.Scan(&pid); err != nil {
+			return nil, errorutils.New(errorutils.ErrCategoryRead, err)
+		}
+
+		ids = append(ids, pid)
+	}
+
+	return ids, nil
+}
+
+func (r *categoryRepository) Update(c *model.Category) error {
+	res, err := r.db.Exec(`UPDATE categories SET parent_id = $1, name = $2, slug = $3, updated_at = $4, updated_by = $5
+	WHERE id = $6`, c.ParentID, c.Name, c.Slug, c.UpdatedAt, c.UpdatedBy, c.ID)
+	if err != nil {
+		return categoryError(err, errorutils.ErrCategoryUpdate)
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return errorutils.New(errorutils.ErrCategoryNotFound, errorutils.ErrCategoryUpdate)
+	}
+
+	return nil
+}
+
+func (r *categoryRepository) Delete(id uint64) error {
+	res, err := r.db.Exec("DELETE FROM categories WHERE id = $1", id)
+	if err != nil {
+		return errorutils.New(errorutils.ErrCategoryDelete, err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return errorutils.New(errorutils.ErrCategoryNotFound, errorutils.ErrCategoryDelete)
+	}
+
+	return nil
+}
+
+// categoryError reports slug collisions and unknown parents as such and
+// everything else as reason.
+func categoryError(err, reason error) error {
+	var pErr *pq.Error
+	if errors.As(err, &pErr) {
+		switch pErr.Constraint {
+		case "categories_slug_key":
+			return errorutils.New(errorutils.ErrCategoryAlreadyExists, err)
+		case "categories_parent_id_fkey":
+			return errorutils.New(errorutils.ErrCategoryNotFound, err)
+		}
+	}
+
+	return errorutils.New(reason, err)
+}
+
+func scanIntoCategory(rows *sql.Rows) (*model.Category, error) {
+	c := new(model.Category)
+	err := rows.Scan(&c.ID, &c.ParentID, &c.Name, &c.Slug, &c.CreatedAt, &c.UpdatedAt, &c.CreatedBy, &c.UpdatedBy)
+
+	return c, err
+}
@@ -3,13 +3,23 @@ package postgresadapter
 import (
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
 
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/repository"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/pagination"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
 )
 
+// commentColumns is the column list scanIntoComment expects, in order.
+const commentColumns = `id, author, user_id, post_id, text, created_at,
+	status, deleted_at, created_by, updated_by, deleted_by, parent_id, depth,
+	updated_at, edited, state, moderated_at, moderated_by`
+
 type commentRepository struct {
 	db *sql.DB
 }
@@ -22,10 +32,11 @@ func NewCommentRepository(db *sql.DB) repository.Comment {
 
 func (r *commentRepository) Create(c *model.Comment) error {
 	query := `INSERT INTO comments 
-    (author, post_id, text, user_id, created_at)
-    VALUES ($1, $2, $3, $4, $5)`
+    (author, post_id, text, user_id, created_at, status, created_by, updated_by, parent_id, depth, updated_at, state)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id`
 
-	_, err := r.db.Query(query, c.Author, c.PostID, c.Text, c.UserID, c.CreatedAt)
+	err := r.db.QueryRow(query, c.Author, c.PostID, c.Text, c.UserID, c.CreatedAt, c.Status, c.CreatedBy, c.UpdatedBy,
+		c.ParentID, c.Depth, c.UpdatedAt, c.State).Scan(&c.ID)
 	if err != nil {
 		return errorutils.New(errorutils.ErrCommentCreate, err)
 	}
@@ -34,7 +45,7 @@ func (r *commentRepository) Create(c *model.Comment) error {
 }
 
 func (r *commentRepository) Read(id uint64) (*model.Comment, error) {
-	rows, err := r.db.Query("SELECT * FROM comments WHERE id = $1", id)
+	rows, err := r.db.Query("SELECT "+commentColumns+" FROM comments WHERE id = $1 AND deleted_at IS NULL", id)
 	if err != nil {
 		return nil, errorutils.New(errorutils.ErrInvalidRequest, err)
 	}
@@ -51,77 +62,249 @@ func (r *commentRepository) Read(id uint64) (*model.Comment, error) {
 	return nil, errorutils.New(errorutils.ErrCommentNotFound, errorutils.ErrCommentRead)
 }
 
-func (r *commentRepository) ReadsByPostID(p *pagination.Pageable, pid string) (*[]model.Comment, error) {
-	fq := `SELECT * FROM comments WHERE comments.post_id=$1 ORDER BY ` +
-		fmt.Sprintf("%s LIMIT $2 OFFSET $3;", p.Order())
+func (r *commentRepository) ReadsByPostID(p *pagination.Pageable, pid string, f repository.CommentFilter) (*[]model.Comment, error) {
+	return r.reads(p, f, "post_id = $3", pid)
+}
 
-	cq := `SELECT COUNT(*) FROM comments WHERE post_id=$1;`
+func (r *commentRepository) Reads(p *pagination.Pageable, f repository.CommentFilter) (*[]model.Comment, error) {
+	return r.reads(p, f, "")
+}
 
-	countErr := make(chan error)
-	findErr := make(chan error)
+// reads lists the comments matching f and cond, when set; cond's arguments start at $3.
+func (r *commentRepository) reads(p *pagination.Pageable, f repository.CommentFilter, cond string, condArgs ...any) (*[]model.Comment, error) {
+	var conds []string
+
+	args := append([]any{p.Size, p.Offset()}, condArgs...)
+
+	if cond != "" {
+		conds = append(conds, cond)
+	}
+
+	if !f.IncludeDeleted {
+		conds = append(conds, "deleted_at IS NULL")
+	}
+
+	if f.TopLevel {
+		conds = append(conds, "parent_id IS NULL")
+	}
+
+	if f.State != "" {
+		args = append(args, f.State)
+		conds = append(conds, fmt.Sprintf("state = $%d", len(args)))
+	}
+
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ") + " "
+	}
+
+	q := `SELECT ` + commentColumns + `, COUNT(*) OVER() AS count FROM comments ` + where + `ORDER BY ` +
+		fmt.Sprintf("%s LIMIT $1 OFFSET $2;", p.Order())
+
+	rows, err := r.db.Query(q, args...)
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrCommentReads, err)
+	}
+	defer rows.Close()
 
 	var comments []model.Comment
 
-	go func() {
-		rows, err := r.db.Query(fq, pid, p.Size, p.Offset())
+	var count int64
+
+	for rows.Next() {
+		c, err := scanIntoComment(rows, &count)
 		if err != nil {
-			findErr <- errorutils.New(errorutils.ErrCommentReads, err)
+			return nil, errorutils.New(errorutils.ErrCommentReads, err)
 		}
 
-		for rows.Next() {
-			u, err := scanIntoComment(rows)
-			if err != nil {
-				findErr <- errorutils.New(errorutils.ErrCommentReads, err)
-			}
+		comments = append(comments, *c)
+	}
 
-			comments = append(comments, *u)
-		}
-		findErr <- nil
-	}()
+	p.TotalCount = count
 
-	var count int64
-	go func() {
-		rows, err := r.db.Query(cq, pid)
+	return &comments, nil
+}
+
+func (r *commentRepository) ReadReplies(ids []uint64, f repository.CommentFilter) ([]model.Comment, error) {
+	var conds []string
+
+	args := []any{pq.Array(ids)}
+
+	if !f.IncludeDeleted {
+		conds = append(conds, "c.deleted_at IS NULL")
+	}
+
+	if f.State != "" {
+		args = append(args, f.State)
+		conds = append(conds, fmt.Sprintf("c.state = $%d", len(args)))
+	}
+
+	where := ""
+	if len(conds) > 0 {
+		where = "AND " + strings.Join(conds, " AND ")
+	}
+
+	// Replies of a hidden comment are hidden with it.
+	rows, err := r.db.Query(`WITH RECURSIVE replies AS (
+		SELECT c.* FROM comments c WHERE c.parent_id = ANY($1) `+where+`
+		UNION ALL
+		SELECT c.* FROM comments c JOIN replies ON c.parent_id = replies.id WHERE true `+where+`
+	) SELECT `+commentColumns+` FROM replies ORDER BY depth, created_at`, args...)
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrCommentReads, err)
+	}
+	defer rows.Close()
+
+	var comments []model.Comment
+
+	for rows.Next() {
+		c, err := scanIntoComment(rows)
 		if err != nil {
-			countErr <- errorutils.New(errorutils.ErrCommentCount, err)
+			return nil, errorutils.New(errorutils.ErrCommentReads, err)
 		}
 
-		for rows.Next() {
-			err = rows.Scan(&count)
-			if err != nil {
-				countErr <- errorutils.New(errorutils.ErrCommentCount, err)
-			}
+		comments = append(comments, *c)
+	}
+
+	return comments, nil
+}
+
+func (r *commentRepository) CountApproved(userID uint64) (int, error) {
+	var n int
+
+	err := r.db.QueryRow("SELECT COUNT(*) FROM comments WHERE user_id = $1 AND state = $2 AND deleted_at IS NULL",
+		userID, types.Approved).Scan(&n)
+	if err != nil {
+		return 0, errorutils.New(errorutils.ErrCommentCount, err)
+	}
+
+	return n, nil
+}
+
+func (r *commentRepository) Moderate(ids []uint64, state types.CommentState, actor string, at time.Time) ([]uint64, error) {
+	rows, err := r.db.Query(`UPDATE comments SET state = $1, moderated_at = $2, moderated_by = $3, updated_at = $2
+	WHERE id = ANY($4) AND state <> $1 AND deleted_at IS NULL RETURNING id`, state, at, actor, pq.Array(ids))
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrCommentModerate, err)
+	}
+	defer rows.Close()
+
+	changed := make([]uint64, 0, len(ids))
+
+	for rows.Next() {
+		var id uint64
+		if err = rows.Scan(&id); err != nil {
+			return nil, errorutils.New(errorutils.ErrCommentModerate, err)
 		}
 
-		p.TotalCount = count
-		countErr <- nil
-	}()
+		changed = append(changed, id)
+	}
+
+	return changed, nil
+}
 
-	err := <-countErr
+func (r *commentRepository) Update(c *model.Comment, previous string, version time.Time) error {
+	tx, err := r.db.Begin()
 	if err != nil {
-		return nil, err
+		return errorutils.New(errorutils.ErrCommentUpdate, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err = tx.Exec(`INSERT INTO comment_revisions (comment_id, text, created_at, created_by)
+	VALUES ($1, $2, $3, $4)`, c.ID, previous, c.UpdatedAt, c.UpdatedBy); err != nil {
+		return errorutils.New(errorutils.ErrCommentUpdate, err)
 	}
 
-	err = <-findErr
+	res, err := tx.Exec(`UPDATE comments SET text = $1, state = $2, updated_at = $3, updated_by = $4, edited = true
+	WHERE id = $5 AND updated_at = $6`, c.Text, c.State, c.UpdatedAt, c.UpdatedBy, c.ID, version)
 	if err != nil {
-		return nil, err
+		return errorutils.New(errorutils.ErrCommentUpdate, err)
 	}
 
-	return &comments, nil
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return errorutils.New(errorutils.ErrPreconditionFailed, errorutils.ErrCommentUpdate)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errorutils.New(errorutils.ErrCommentUpdate, err)
+	}
+
+	c.Edited = true
+
+	return nil
 }
 
-func (r *commentRepository) Delete(id uint64) error {
-	_, err := r.db.Query("DELETE FROM comments WHERE id = $1", id)
+func (r *commentRepository) ReadRevisions(id uint64) ([]model.CommentRevision, error) {
+	rows, err := r.db.Query(`SELECT id, comment_id, text, created_at, created_by FROM comment_revisions
+	WHERE comment_id = $1 ORDER BY created_at DESC, id DESC`, id)
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrCommentRevisions, err)
+	}
+	defer rows.Close()
+
+	var revisions []model.CommentRevision
+
+	for rows.Next() {
+		var rv model.CommentRevision
+		if err = rows.Scan(&rv.ID, &rv.CommentID, &rv.Text, &rv.CreatedAt, &rv.CreatedBy); err != nil {
+			return nil, errorutils.New(errorutils.ErrCommentRevisions, err)
+		}
+
+		revisions = append(revisions, rv)
+	}
+
+	return revisions, nil
+}
+
+func (r *commentRepository) Delete(c *model.Comment, version time.Time) error {
+	res, err := r.db.Exec("UPDATE comments SET deleted_at = $1, deleted_by = $2 WHERE id = $3 AND deleted_at IS NULL AND updated_at = $4;",
+		c.DeletedAt, c.DeletedBy, c.ID, version)
 	if err != nil {
 		return errorutils.New(errorutils.ErrCommentDelete, err)
 	}
 
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return errorutils.New(errorutils.ErrPreconditionFailed, errorutils.ErrCommentDelete)
+	}
+
+	return nil
+}
+
+func (r *commentRepository) Restore(c *model.Comment) error {
+	res, err := r.db.Exec(`UPDATE comments SET deleted_at = NULL, deleted_by = '', updated_at = $1, updated_by = $2
+	WHERE id = $3 AND deleted_at IS NOT NULL;`, c.UpdatedAt, c.UpdatedBy, c.ID)
+	if err != nil {
+		return errorutils.New(errorutils.ErrCommentRestore, err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return errorutils.New(errorutils.ErrCommentNotFound, errorutils.ErrCommentRestore)
+	}
+
+	return nil
+}
+
+func (r *commentRepository) Purge(id uint64) error {
+	res, err := r.db.Exec("DELETE FROM comments WHERE id = $1", id)
+	if err != nil {
+		return errorutils.New(errorutils.ErrCommentPurge, err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return errorutils.New(errorutils.ErrCommentNotFound, errorutils.ErrCommentPurge)
+	}
+
 	return nil
 }
 
-func scanIntoComment(rows *sql.Rows) (*model.Comment, error) {
+// scanIntoComment scans a row selected with commentColumns; extra receives
+// any columns selected after them.
+func scanIntoComment(rows *sql.Rows, extra ...any) (*model.Comment, error) {
 	c := new(model.Comment)
-	err := rows.Scan(&c.ID, &c.Author, &c.UserID, &c.PostID, &c.Text, &c.CreatedAt)
+	dest := []any{&c.ID, &c.Author, &c.UserID, &c.PostID, &c.Text, &c.CreatedAt,
+		&c.Status, &c.DeletedAt, &c.CreatedBy, &c.UpdatedBy, &c.DeletedBy, &c.ParentID, &c.Depth,
+		&c.UpdatedAt, &c.Edited, &c.State, &c.ModeratedAt, &c.ModeratedBy}
+	err := rows.Scan(append(dest, extra...)...)
 
 	return c, err
 }
@@ -0,0 +1,75 @@
+package postgresadapter
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/repository"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
+)
+
+type identityRepository struct {
+	db *sql.DB
+}
+
+func NewIdentityRepository(db *sql.DB) repository.Identity {
+	return &identityRepository{
+		db: db,
+	}
+}
+
+func (r *identityRepository) Create(i *model.Identity) error {
+	err := r.db.QueryRow(`INSERT INTO user_identities (user_id, provider, subject, email, created_at)
+	VALUES ($1, $2, $3, $4, $5) RETURNING id`, i.UserID, i.Provider, i.Subject, i.Email, i.CreatedAt).Scan(&i.ID)
+	if err != nil {
+		return errorutils.New(errorutils.ErrIdentityCreate, err)
+	}
+
+	return nil
+}
+
+func (r *identityRepository) Read(provider, subject string) (*model.Identity, error) {
+	i := new(model.Identity)
+
+	err := r.db.QueryRow(`SELECT id, user_id, provider, subject, email, created_at
+	FROM user_identities WHERE provider = $1 AND subject = $2`, provider, subject).
+		Scan(&i.ID, &i.UserID, &i.Provider, &i.Subject, &i.Email, &i.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errorutils.New(errorutils.ErrIdentityNotFound, err)
+	}
+
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrIdentityRead, err)
+	}
+
+	return i, nil
+}
+
+func (r *identityRepository) CreateLogin(l *model.OIDCLogin) error {
+	err := r.db.QueryRow(`INSERT INTO oidc_logins (state_hash, provider, nonce, code_verifier, created_at, expires_at)
+	VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`, l.StateHash, l.Provider, l.Nonce, l.CodeVerifier, l.CreatedAt, l.ExpiresAt).Scan(&l.ID)
+	if err != nil {
+		return errorutils.New(errorutils.ErrOIDCLoginCreate, err)
+	}
+
+	return nil
+}
+
+func (r *identityRepository) UseLogin(stateHash string, at time.Time) (*model.OIDCLogin, error) {
+	l := new(model.OIDCLogin)
+
+	err := r.db.QueryRow(`UPDATE oidc_logins SET used_at = $1 WHERE state_hash = $2 AND used_at IS NULL
+	RETURNING id, state_hash, provider, nonce, code_verifier, created_at, expires_at, used_at`, at, stateHash).
+		Scan(&l.ID, &l.StateHash, &l.Provider, &l.Nonce, &l.CodeVerifier, &l.CreatedAt, &l.ExpiresAt, &l.UsedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errorutils.New(errorutils.ErrOIDCInvalidState, err)
+	}
+
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrOIDCLoginUpdate, err)
+	}
+
+	return l, nil
+}
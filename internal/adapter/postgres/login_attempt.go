@@ -0,0 +1,115 @@
+package postgresadapter
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/repository"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/pagination"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
+)
+
+type loginAttemptRepository struct {
+	db *sql.DB
+}
+
+func NewLoginAttemptRepository(db *sql.DB) repository.LoginAttempt {
+	return &loginAttemptRepository{
+		db: db,
+	}
+}
+
+func (r *loginAttemptRepository) LockedUntil(kind types.LoginAttemptKind, key string, now time.Time) (*time.Time, error) {
+	var until *time.Time
+
+	err := r.db.QueryRow("SELECT locked_until FROM login_attempts WHERE kind = $1 AND key = $2 AND locked_until > $3",
+		kind, key, now).Scan(&until)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrLoginAttemptRead, err)
+	}
+
+	return until, nil
+}
+
+func (r *loginAttemptRepository) Fail(kind types.LoginAttemptKind, key string, at, since time.Time) (*model.LoginAttempt, error) {
+	a := new(model.LoginAttempt)
+
+	err := r.db.QueryRow(`INSERT INTO login_attempts (kind, key, failures, last_failed_at) VALUES ($1, $2, 1, $3)
+	ON CONFLICT (kind, key) DO UPDATE SET
+		failures = CASE WHEN login_attempts.last_failed_at < $4 THEN 1 ELSE login_attempts.failures + 1 END,
+		locked_until = CASE WHEN login_attempts.last_failed_at < $4 THEN NULL ELSE login_attempts.locked_until END,
+		last_failed_at = $3
+	RETURNING id, kind, key, failures, last_failed_at, locked_until`, kind, key, at, since).
+		Scan(&a.ID, &a.Kind, &a.Key, &a.Failures, &a.LastFailedAt, &a.LockedUntil)
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrLoginAttemptUpdate, err)
+	}
+
+	return a, nil
+}
+
+func (r *loginAttemptRepository) Lock(id uint64, until time.Time) error {
+	_, err := r.db.Exec("UPDATE login_attempts SET locked_until = $1 WHERE id = $2", until, id)
+	if err != nil {
+		return errorutils.New(errorutils.ErrLoginAttemptUpdate, err)
+	}
+
+	return nil
+}
+
+func (r *loginAttemptRepository) Clear(kind types.LoginAttemptKind, key string) error {
+	_, err := r.db.Exec("DELETE FROM login_attempts WHERE kind = $1 AND key = $2", kind, key)
+	if err != nil {
+		return errorutils.New(errorutils.ErrLoginAttemptDelete, err)
+	}
+
+	return nil
+}
+
+func (r *loginAttemptRepository) ReadsLocked(p *pagination.Pageable, now time.Time) (*[]model.LoginAttempt, error) {
+	rows, err := r.db.Query(`SELECT id, kind, key, failures, last_failed_at, locked_until, COUNT(*) OVER() AS count
+	FROM login_attempts WHERE locked_until > $1 ORDER BY locked_until DESC LIMIT $2 OFFSET $3;`, now, p.Size, p.Offset())
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrLoginAttemptRead, err)
+	}
+	defer rows.Close()
+
+	attempts := []model.LoginAttempt{}
+
+	var count int64
+
+	for rows.Next() {
+		a := new(model.LoginAttempt)
+
+		err := rows.Scan(&a.ID, &a.Kind, &a.Key, &a.Failures, &a.LastFailedAt, &a.LockedUntil, &count)
+		if err != nil {
+			return nil, errorutils.New(errorutils.ErrLoginAttemptRead, err)
+		}
+
+		attempts = append(attempts, *a)
+	}
+
+	p.TotalCount = count
+
+	return &attempts, nil
+}
+
+func (r *loginAttemptRepository) Delete(id uint64) error {
+	res, err := r.db.Exec("DELETE FROM login_attempts WHERE id = $1", id)
+	if err != nil {
+		return errorutils.New(errorutils.ErrLoginAttemptDelete, err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return errorutils.New(errorutils.ErrLoginAttemptNotFound, errorutils.ErrLoginAttemptDelete)
+	}
+
+	return nil
+}
@@ -2,7 +2,10 @@ package postgresadapter
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/repository"
@@ -10,6 +13,16 @@ import (
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
 )
 
+// postColumns is the column list scanIntoPost expects, in order.
+const postColumns = `id, title, slug, body, created_at, updated_at,
+	status, deleted_at, created_by, updated_by, deleted_by,
+	COALESCE(user_id, 0), COALESCE((SELECT username FROM users WHERE users.id = posts.user_id), ''),
+	state, publish_at,
+	COALESCE((SELECT json_agg(json_build_object('id', t.id, 'name', t.name, 'slug', t.slug) ORDER BY t.name)
+		FROM post_tags pt JOIN tags t ON t.id = pt.tag_id WHERE pt.post_id = posts.id), '[]'),
+	COALESCE((SELECT json_agg(json_build_object('id', c.id, 'parent_id', c.parent_id, 'name', c.name, 'slug', c.slug) ORDER BY c.name)
+		FROM post_categories pc JOIN categories c ON c.id = pc.category_id WHERE pc.post_id = posts.id), '[]')`
+
 type postRepository struct {
 	db *sql.DB
 }
@@ -20,24 +33,58 @@ func NewPostRepository(db *sql.DB) repository.Post {
 	}
 }
 
-func (r *postRepository) Create(p *model.Post) error {
+func (r *postRepository) Create(p *model.Post, tagIDs, categoryIDs []uint64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return errorutils.New(errorutils.ErrPostCreate, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
 	query := `INSERT INTO posts 
-    (title, body, created_at, updated_at)
-    VALUES ($1, $2, $3, $4)`
+    (title, body, created_at, updated_at, status, created_by, updated_by, user_id, state, publish_at, slug)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, 0), $9, $10, $11) RETURNING id`
 
-	_, err := r.db.Query(query, p.Title, p.Body, p.CreatedAt, p.UpdatedAt)
+	err = tx.QueryRow(query, p.Title, p.Body, p.CreatedAt, p.UpdatedAt, p.Status, p.CreatedBy, p.UpdatedBy, p.UserID,
+		p.State, p.PublishAt, p.Slug).Scan(&p.ID)
 	if err != nil {
 		return errorutils.New(errorutils.ErrPostCreate, err)
 	}
 
+	if err = replaceLinks(tx, "post_tags", "tag_id", p.ID, tagIDs); err != nil {
+		return err
+	}
+
+	if err = replaceLinks(tx, "post_categories", "category_id", p.ID, categoryIDs); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errorutils.New(errorutils.ErrPostCreate, err)
+	}
+
 	return nil
 }
 
 func (r *postRepository) Read(id uint64) (*model.Post, error) {
-	rows, err := r.db.Query("SELECT * FROM posts WHERE id = $1", id)
+	return r.readOne("SELECT "+postColumns+" FROM posts WHERE id = $1 AND deleted_at IS NULL", id)
+}
+
+func (r *postRepository) ReadBySlug(slug string) (*model.Post, error) {
+	return r.readOne("SELECT "+postColumns+" FROM posts WHERE slug = $1 AND deleted_at IS NULL", slug)
+}
+
+// ReadByOldSlug finds the post that used to be addressed by slug.
+func (r *postRepository) ReadByOldSlug(slug string) (*model.Post, error) {
+	return r.readOne("SELECT "+postColumns+` FROM posts
+	WHERE id = (SELECT post_id FROM post_slugs WHERE slug = $1) AND deleted_at IS NULL`, slug)
+}
+
+func (r *postRepository) readOne(query string, args ...any) (*model.Post, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, errorutils.New(errorutils.ErrInvalidRequest, err)
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		post, err := scanIntoPost(rows)
@@ -51,23 +98,107 @@ func (r *postRepository) Read(id uint64) (*model.Post, error) {
 	return nil, errorutils.New(errorutils.ErrPostNotFound, errorutils.ErrPostRead)
 }
 
-func (r *postRepository) Reads(p *pagination.Pageable) (*[]model.Post, error) {
-	q := `SELECT id, title, body, created_at, updated_at, COUNT(*) OVER() AS count FROM posts ORDER BY ` +
+// SlugTaken reports whether slug is used, now or in the past, by a post other than exceptID.
+func (r *postRepository) SlugTaken(slug string, exceptID uint64) (bool, error) {
+	var taken bool
+
+	err := r.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM posts WHERE slug = $1 AND id <> $2)
+	OR EXISTS (SELECT 1 FROM post_slugs WHERE slug = $1 AND post_id <> $2)`, slug, exceptID).Scan(&taken)
+	if err != nil {
+		return false, errorutils.New(errorutils.ErrPostRead, err)
+	}
+
+	return taken, nil
+}
+
+// changeSlug moves the post to p.Slug and keeps old around for redirects.
+func changeSlug(tx *sql.Tx, p *model.Post, old string) error {
+	if _, err := tx.Exec(`INSERT INTO post_slugs (slug, post_id, created_at) VALUES ($1, $2, $3)
+	ON CONFLICT (slug) DO NOTHING`, old, p.ID, p.UpdatedAt); err != nil {
+		return errorutils.New(errorutils.ErrPostUpdate, err)
+	}
+
+	// A post may go back to one of its old slugs.
+	if _, err := tx.Exec("DELETE FROM post_slugs WHERE slug = $1 AND post_id = $2", p.Slug, p.ID); err != nil {
+		return errorutils.New(errorutils.ErrPostUpdate, err)
+	}
+
+	if _, err := tx.Exec("UPDATE posts SET slug = $1 WHERE id = $2", p.Slug, p.ID); err != nil {
+		return errorutils.New(errorutils.ErrPostSlugUsed, err)
+	}
+
+	return nil
+}
+
+// replaceLinks swaps the rows of a post join table for ids.
+func replaceLinks(tx *sql.Tx, table, column string, postID uint64, ids []uint64) error {
+	if _, err := tx.Exec("DELETE FROM "+table+" WHERE post_id = $1", postID); err != nil {
+		return errorutils.New(errorutils.ErrPostUpdate, err)
+	}
+
+	for _, id := range ids {
+		if _, err := tx.Exec("INSERT INTO "+table+" (post_id, "+column+") VALUES ($1, $2) ON CONFLICT DO NOTHING", postID, id); err != nil {
+			return errorutils.New(errorutils.ErrPostUpdate, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *postRepository) Reads(p *pagination.Pageable, f repository.PostFilter) (*[]model.Post, error) {
+	var conds []string
+
+	args := []any{p.Size, p.Offset()}
+
+	if !f.IncludeDeleted {
+		conds = append(conds, "deleted_at IS NULL")
+	}
+
+	if f.UserID != 0 {
+		args = append(args, f.UserID)
+		conds = append(conds, fmt.Sprintf("user_id = $%d", len(args)))
+	}
+
+	if f.State != "" {
+		args = append(args, f.State)
+		conds = append(conds, fmt.Sprintf("state = $%d", len(args)))
+	}
+
+	if f.Tag != "" {
+		args = append(args, f.Tag)
+		conds = append(conds, fmt.Sprintf(`EXISTS (SELECT 1 FROM post_tags pt JOIN tags t ON t.id = pt.tag_id
+		WHERE pt.post_id = posts.id AND t.slug = $%d)`, len(args)))
+	}
+
+	if f.Category != "" {
+		args = append(args, f.Category)
+		conds = append(conds, fmt.Sprintf(`EXISTS (SELECT 1 FROM post_categories pc WHERE pc.post_id = posts.id
+		AND pc.category_id IN (WITH RECURSIVE sub AS (
+			SELECT id FROM categories WHERE slug = $%d
+			UNION
+			SELECT c.id FROM categories c JOIN sub ON c.parent_id = sub.id
+		) SELECT id FROM sub))`, len(args)))
+	}
+
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ") + " "
+	}
+
+	q := `SELECT ` + postColumns + `, COUNT(*) OVER() AS count FROM posts ` + where + `ORDER BY ` +
 		fmt.Sprintf("%s LIMIT $1 OFFSET $2;", p.Order())
 
 	var posts []model.Post
 
 	var count int64
 
-	rows, err := r.db.Query(q, p.Size, p.Offset())
+	rows, err := r.db.Query(q, args...)
 	if err != nil {
 		return nil, errorutils.New(errorutils.ErrPostReads, err)
 	}
 
 	for rows.Next() {
-		p := new(model.Post)
-
-		err := rows.Scan(&p.ID, &p.Title, &p.Body, &p.CreatedAt, &p.UpdatedAt, &count)
+		p, err := scanIntoPost(rows, &count)
 		if err != nil {
 			return nil, errorutils.New(errorutils.ErrPostReads, err)
 		}
@@ -80,27 +211,181 @@ func (r *postRepository) Reads(p *pagination.Pageable) (*[]model.Post, error) {
 	return &posts, nil
 }
 
-func (r *postRepository) Update(p *model.Post) error {
-	_, err := r.db.Query("UPDATE posts SET title = $1, body = $2, updated_at = $3 WHERE id = $4;", p.Title, p.Body, p.UpdatedAt, p.ID)
+func (r *postRepository) Update(p *model.Post, version time.Time, change repository.PostChange) error {
+	tx, err := r.db.Begin()
 	if err != nil {
 		return errorutils.New(errorutils.ErrPostUpdate, err)
 	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.Exec(`UPDATE posts SET title = $1, body = $2, updated_at = $3, updated_by = $4, state = $5, publish_at = $6
+	WHERE id = $7 AND updated_at = $8;`, p.Title, p.Body, p.UpdatedAt, p.UpdatedBy, p.State, p.PublishAt, p.ID, version)
+	if err != nil {
+		return errorutils.New(errorutils.ErrPostUpdate, err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return errorutils.New(errorutils.ErrPreconditionFailed, errorutils.ErrPostUpdate)
+	}
+
+	if rv := change.Revision; rv != nil {
+		err = tx.QueryRow(`INSERT INTO post_revisions (post_id, number, title, body, created_at, created_by)
+		SELECT $1, COALESCE(MAX(number), 0) + 1, $2, $3, $4, $5 FROM post_revisions WHERE post_id = $1
+		RETURNING id, number`, rv.PostID, rv.Title, rv.Body, rv.CreatedAt, rv.CreatedBy).Scan(&rv.ID, &rv.Number)
+		if err != nil {
+			return errorutils.New(errorutils.ErrPostRevisionCreate, err)
+		}
+	}
+
+	if change.OldSlug != "" && change.OldSlug != p.Slug {
+		if err = changeSlug(tx, p, change.OldSlug); err != nil {
+			return err
+		}
+	}
+
+	if change.Tags != nil {
+		if err = replaceLinks(tx, "post_tags", "tag_id", p.ID, *change.Tags); err != nil {
+			return err
+		}
+	}
+
+	if change.Categories != nil {
+		if err = replaceLinks(tx, "post_categories", "category_id", p.ID, *change.Categories); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errorutils.New(errorutils.ErrPostUpdate, err)
+	}
 
 	return nil
 }
 
-func (r *postRepository) Delete(id uint64) error {
-	_, err := r.db.Query("DELETE FROM posts WHERE id = $1", id)
+func (r *postRepository) ReadRevisions(postID uint64) ([]model.PostRevision, error) {
+	rows, err := r.db.Query("SELECT "+postRevisionColumns+" FROM post_revisions WHERE post_id = $1 ORDER BY number DESC", postID)
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrPostRevisions, err)
+	}
+	defer rows.Close()
+
+	var revisions []model.PostRevision
+
+	for rows.Next() {
+		rv, err := scanIntoPostRevision(rows)
+		if err != nil {
+			return nil, errorutils.New(errorutils.ErrPostRevisions, err)
+		}
+
+		revisions = append(revisions, *rv)
+	}
+
+	return revisions, nil
+}
+
+func (r *postRepository) ReadRevision(postID uint64, number int) (*model.PostRevision, error) {
+	rows, err := r.db.Query("SELECT "+postRevisionColumns+" FROM post_revisions WHERE post_id = $1 AND number = $2", postID, number)
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrPostRevisions, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		rv, err := scanIntoPostRevision(rows)
+		if err != nil {
+			return nil, errorutils.New(errorutils.ErrPostRevisions, err)
+		}
+
+		return rv, nil
+	}
+
+	return nil, errorutils.New(errorutils.ErrPostRevisionNotFound, nil)
+}
+
+func (r *postRepository) Delete(p *model.Post, version time.Time) error {
+	res, err := r.db.Exec("UPDATE posts SET deleted_at = $1, deleted_by = $2 WHERE id = $3 AND deleted_at IS NULL AND updated_at = $4;",
+		p.DeletedAt, p.DeletedBy, p.ID, version)
 	if err != nil {
 		return errorutils.New(errorutils.ErrPostDelete, err)
 	}
 
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return errorutils.New(errorutils.ErrPreconditionFailed, errorutils.ErrPostDelete)
+	}
+
+	return nil
+}
+
+func (r *postRepository) Restore(p *model.Post) error {
+	res, err := r.db.Exec(`UPDATE posts SET deleted_at = NULL, deleted_by = '', updated_at = $1, updated_by = $2
+	WHERE id = $3 AND deleted_at IS NOT NULL;`, p.UpdatedAt, p.UpdatedBy, p.ID)
+	if err != nil {
+		return errorutils.New(errorutils.ErrPostRestore, err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return errorutils.New(errorutils.ErrPostNotFound, errorutils.ErrPostRestore)
+	}
+
+	return nil
+}
+
+func (r *postRepository) Purge(id uint64) error {
+	res, err := r.db.Exec("DELETE FROM posts WHERE id = $1", id)
+	if err != nil {
+		return errorutils.New(errorutils.ErrPostPurge, err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return errorutils.New(errorutils.ErrPostNotFound, errorutils.ErrPostPurge)
+	}
+
 	return nil
 }
 
-func scanIntoPost(rows *sql.Rows) (*model.Post, error) {
+// PublishDue publishes the scheduled posts whose publish date has passed and
+// returns how many were published.
+func (r *postRepository) PublishDue(now time.Time) (int64, error) {
+	res, err := r.db.Exec(`UPDATE posts SET state = 'published', updated_at = $1
+	WHERE state = 'scheduled' AND publish_at <= $1 AND deleted_at IS NULL;`, now)
+	if err != nil {
+		return 0, errorutils.New(errorutils.ErrPostUpdate, err)
+	}
+
+	return res.RowsAffected()
+}
+
+// scanIntoPost scans a postColumns row; extra receives any columns selected after them.
+func scanIntoPost(rows *sql.Rows, extra ...any) (*model.Post, error) {
 	p := new(model.Post)
-	err := rows.Scan(&p.ID, &p.Title, &p.Body, &p.CreatedAt, &p.UpdatedAt)
 
-	return p, err
+	var tags, categories []byte
+
+	dest := []any{&p.ID, &p.Title, &p.Slug, &p.Body, &p.CreatedAt, &p.UpdatedAt,
+		&p.Status, &p.DeletedAt, &p.CreatedBy, &p.UpdatedBy, &p.DeletedBy,
+		&p.UserID, &p.Author, &p.State, &p.PublishAt, &tags, &categories}
+
+	if err := rows.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(tags, &p.Tags); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(categories, &p.Categories); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// postRevisionColumns is the column list scanIntoPostRevision expects, in order.
+const postRevisionColumns = "id, post_id, number, title, body, created_at, created_by"
+
+func scanIntoPostRevision(rows *sql.Rows) (*model.PostRevision, error) {
+	rv := new(model.PostRevision)
+	err := rows.Scan(&rv.ID, &rv.PostID, &rv.Number, &rv.Title, &rv.Body, &rv.CreatedAt, &rv.CreatedBy)
+
+	return rv, err
 }
@@ -0,0 +1,71 @@
+package postgresadapter
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/repository"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
+)
+
+type recoveryCodeRepository struct {
+	db *sql.DB
+}
+
+func NewRecoveryCodeRepository(db *sql.DB) repository.RecoveryCode {
+	return &recoveryCodeRepository{
+		db: db,
+	}
+}
+
+func (r *recoveryCodeRepository) Replace(userID uint64, hashes []string, at time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return errorutils.New(errorutils.ErrRecoveryCodeCreate, err)
+	}
+
+	if _, err = tx.Exec("DELETE FROM recovery_codes WHERE user_id = $1", userID); err != nil {
+		_ = tx.Rollback()
+
+		return errorutils.New(errorutils.ErrRecoveryCodeCreate, err)
+	}
+
+	for _, h := range hashes {
+		_, err = tx.Exec("INSERT INTO recovery_codes (user_id, code_hash, created_at) VALUES ($1, $2, $3)", userID, h, at)
+		if err != nil {
+			_ = tx.Rollback()
+
+			return errorutils.New(errorutils.ErrRecoveryCodeCreate, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errorutils.New(errorutils.ErrRecoveryCodeCreate, err)
+	}
+
+	return nil
+}
+
+func (r *recoveryCodeRepository) Use(userID uint64, hash string, at time.Time) (bool, error) {
+	res, err := r.db.Exec(`UPDATE recovery_codes SET used_at = $1
+	WHERE id = (SELECT id FROM recovery_codes WHERE user_id = $2 AND code_hash = $3 AND used_at IS NULL LIMIT 1)`, at, userID, hash)
+	if err != nil {
+		return false, errorutils.New(errorutils.ErrRecoveryCodeUpdate, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, errorutils.New(errorutils.ErrRecoveryCodeUpdate, err)
+	}
+
+	return n == 1, nil
+}
+
+func (r *recoveryCodeRepository) DeleteAll(userID uint64) error {
+	_, err := r.db.Exec("DELETE FROM recovery_codes WHERE user_id = $1", userID)
+	if err != nil {
+		return errorutils.New(errorutils.ErrRecoveryCodeUpdate, err)
+	}
+
+	return nil
+}
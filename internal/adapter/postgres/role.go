@@ -0,0 +1,131 @@
+package postgresadapter
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/repository"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
+)
+
+// roleColumns is the column list scanIntoRole expects, in order.
+const roleColumns = `name, permissions, created_at, updated_at, created_by, updated_by`
+
+type roleRepository struct {
+	db *sql.DB
+}
+
+func NewRoleRepository(db *sql.DB) repository.Role {
+	return &roleRepository{
+		db: db,
+	}
+}
+
+func (r *roleRepository) Create(role *model.Role) error {
+	_, err := r.db.Exec(`INSERT INTO roles (name, permissions, created_at, updated_at, created_by, updated_by)
+	VALUES ($1, $2, $3, $4, $5, $6)`,
+		role.Name, pq.Array(role.Permissions), role.CreatedAt, role.UpdatedAt, role.CreatedBy, role.UpdatedBy)
+	if err != nil {
+		var pErr *pq.Error
+		if errors.As(err, &pErr) && pErr.Constraint == "roles_pkey" {
+			return errorutils.New(errorutils.ErrRoleAlreadyExists, err)
+		}
+
+		return errorutils.New(errorutils.ErrRoleCreate, err)
+	}
+
+	return nil
+}
+
+func (r *roleRepository) Read(name types.Role) (*model.Role, error) {
+	rows, err := r.db.Query("SELECT "+roleColumns+" FROM roles WHERE name = $1", name)
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrRoleRead, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		role, err := scanIntoRole(rows)
+		if err != nil {
+			return nil, errorutils.New(errorutils.ErrRoleRead, err)
+		}
+
+		return role, nil
+	}
+
+	return nil, errorutils.New(errorutils.ErrRoleNotFound, errorutils.ErrRoleRead)
+}
+
+func (r *roleRepository) Reads() (*[]model.Role, error) {
+	rows, err := r.db.Query("SELECT " + roleColumns + " FROM roles ORDER BY name")
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrRoleReads, err)
+	}
+	defer rows.Close()
+
+	var roles []model.Role
+
+	for rows.Next() {
+		role, err := scanIntoRole(rows)
+		if err != nil {
+			return nil, errorutils.New(errorutils.ErrRoleReads, err)
+		}
+
+		roles = append(roles, *role)
+	}
+
+	return &roles, nil
+}
+
+func (r *roleRepository) Update(role *model.Role) error {
+	res, err := r.db.Exec("UPDATE roles SET permissions = $1, updated_at = $2, updated_by = $3 WHERE name = $4",
+		pq.Array(role.Permissions), role.UpdatedAt, role.UpdatedBy, role.Name)
+	if err != nil {
+		return errorutils.New(errorutils.ErrRoleUpdate, err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return errorutils.New(errorutils.ErrRoleNotFound, errorutils.ErrRoleUpdate)
+	}
+
+	return nil
+}
+
+func (r *roleRepository) Delete(name types.Role) error {
+	res, err := r.db.Exec("DELETE FROM roles WHERE name = $1", name)
+	if err != nil {
+		var pErr *pq.Error
+		if errors.As(err, &pErr) && pErr.Constraint == "users_user_role_fkey" {
+			return errorutils.New(errorutils.ErrRoleInUse, err)
+		}
+
+		return errorutils.New(errorutils.ErrRoleDelete, err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return errorutils.New(errorutils.ErrRoleNotFound, errorutils.ErrRoleDelete)
+	}
+
+	return nil
+}
+
+func scanIntoRole(rows *sql.Rows) (*model.Role, error) {
+	role := new(model.Role)
+
+	var permissions []string
+
+	err := rows.Scan(&role.Name, pq.Array(&permissions), &role.CreatedAt, &role.UpdatedAt, &role.CreatedBy, &role.UpdatedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range permissions {
+		role.Permissions = append(role.Permissions, types.Permission(p))
+	}
+
+	return role, nil
+}
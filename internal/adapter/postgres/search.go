@@ -0,0 +1,141 @@
+package postgresadapter
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/repository"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/pagination"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
+)
+
+// defaultSearchLanguage is the text search configuration the migrations build the vectors with.
+const defaultSearchLanguage = "simple"
+
+// searchTables are the tables carrying search_config and search_vector columns.
+var searchTables = []string{"posts", "comments"}
+
+// searchQueries select the matching rows of each content type; $3 is the
+// language and $4 the query.
+var searchQueries = map[types.SearchType]string{
+	types.SearchPost: `SELECT 'post' AS type, id, id AS post_id, title, body AS content,
+		ts_rank(search_vector, query) AS rank, created_at
+	FROM posts CROSS JOIN websearch_to_tsquery($3::regconfig, $4) query
+	WHERE search_vector @@ query AND deleted_at IS NULL AND state = 'published'`,
+	types.SearchComment: `SELECT 'comment' AS type, c.id, c.post_id, p.title, c.text AS content,
+		ts_rank(c.search_vector, query) AS rank, c.created_at
+	FROM comments c JOIN posts p ON p.id = c.post_id CROSS JOIN websearch_to_tsquery($3::regconfig, $4) query
+	WHERE c.search_vector @@ query AND c.deleted_at IS NULL AND c.state = 'approved'
+		AND p.deleted_at IS NULL AND p.state = 'published'`,
+}
+
+type searchRepository struct {
+	db       *sql.DB
+	language string
+}
+
+// NewSearchRepository returns a search repository using the given text search
+// language, e.g. "english" or "turkish"; empty means "simple".
+func NewSearchRepository(db *sql.DB, language string) repository.Search {
+	if language == "" {
+		language = defaultSearchLanguage
+	}
+
+	return &searchRepository{
+		db:       db,
+		language: language,
+	}
+}
+
+func (r *searchRepository) Search(p *pagination.Pageable, f repository.SearchFilter) (*[]model.SearchResult, error) {
+	var parts []string
+
+	for _, t := range f.Types {
+		parts = append(parts, searchQueries[t])
+	}
+
+	// The snippet is only built for the rows of the requested page.
+	q := `SELECT type, id, post_id, title,
+		ts_headline($3::regconfig, content, websearch_to_tsquery($3::regconfig, $4), 'StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15'),
+		rank, created_at, COUNT(*) OVER() AS count
+	FROM (` + strings.Join(parts, " UNION ALL ") + `) results ORDER BY ` +
+		fmt.Sprintf("%s, created_at DESC LIMIT $1 OFFSET $2;", p.Order())
+
+	rows, err := r.db.Query(q, p.Size, p.Offset(), r.language, f.Query)
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrSearch, err)
+	}
+	defer rows.Close()
+
+	var results []model.SearchResult
+
+	var count int64
+
+	for rows.Next() {
+		var sr model.SearchResult
+
+		err := rows.Scan(&sr.Type, &sr.ID, &sr.PostID, &sr.Title, &sr.Snippet, &sr.Rank, &sr.CreatedAt, &count)
+		if err != nil {
+			return nil, errorutils.New(errorutils.ErrSearch, err)
+		}
+
+		results = append(results, sr)
+	}
+
+	p.TotalCount = count
+
+	return &results, nil
+}
+
+func (r *searchRepository) Reindex() error {
+	var known bool
+
+	if err := r.db.QueryRow("SELECT to_regconfig($1) IS NOT NULL", r.language).Scan(&known); err != nil || !known {
+		return errorutils.New(errorutils.ErrSearchLanguage, err)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return errorutils.New(errorutils.ErrSearch, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, t := range searchTables {
+		// Altering the default locks the table, so only do it, and the
+		// update, when the language actually changed.
+		var defaultSet, stale bool
+
+		err = tx.QueryRow(`SELECT COALESCE((SELECT column_default FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = $2 AND column_name = 'search_config')
+			= quote_literal($1::regconfig::text) || '::regconfig', false),
+		EXISTS (SELECT 1 FROM `+t+` WHERE search_config <> $1::regconfig)`, r.language, t).Scan(&defaultSet, &stale)
+		if err != nil {
+			return errorutils.New(errorutils.ErrSearch, err)
+		}
+
+		// New rows pick the language up from the column default.
+		if !defaultSet {
+			if _, err = tx.Exec("ALTER TABLE " + t + " ALTER COLUMN search_config SET DEFAULT " + pq.QuoteLiteral(r.language)); err != nil {
+				return errorutils.New(errorutils.ErrSearch, err)
+			}
+		}
+
+		// Changing search_config regenerates search_vector.
+		if stale {
+			if _, err = tx.Exec("UPDATE "+t+" SET search_config = $1::regconfig WHERE search_config <> $1::regconfig", r.language); err != nil {
+				return errorutils.New(errorutils.ErrSearch, err)
+			}
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errorutils.New(errorutils.ErrSearch, err)
+	}
+
+	return nil
+}
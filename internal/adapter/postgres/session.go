@@ -0,0 +1,113 @@
+package postgresadapter
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/repository"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
+)
+
+type sessionRepository struct {
+	db *sql.DB
+}
+
+func NewSessionRepository(db *sql.DB) repository.Session {
+	return &sessionRepository{
+		db: db,
+	}
+}
+
+func (r *sessionRepository) Create(s *model.Session) error {
+	_, err := r.db.Exec("INSERT INTO sessions (id, user_id, created_at) VALUES ($1, $2, $3)", s.ID, s.UserID, s.CreatedAt)
+	if err != nil {
+		return errorutils.New(errorutils.ErrSessionCreate, err)
+	}
+
+	return nil
+}
+
+func (r *sessionRepository) Revoked(id string) (bool, error) {
+	var revokedAt *time.Time
+
+	err := r.db.QueryRow("SELECT revoked_at FROM sessions WHERE id = $1", id).Scan(&revokedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return true, nil
+	}
+
+	if err != nil {
+		return false, errorutils.New(errorutils.ErrSessionRead, err)
+	}
+
+	return revokedAt != nil, nil
+}
+
+func (r *sessionRepository) Revoke(id string, at time.Time) error {
+	_, err := r.db.Exec("UPDATE sessions SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL", at, id)
+	if err != nil {
+		return errorutils.New(errorutils.ErrSessionRevoke, err)
+	}
+
+	return nil
+}
+
+func (r *sessionRepository) RevokeAll(userID uint64, at time.Time) error {
+	_, err := r.db.Exec("UPDATE sessions SET revoked_at = $1 WHERE user_id = $2 AND revoked_at IS NULL", at, userID)
+	if err != nil {
+		return errorutils.New(errorutils.ErrSessionRevoke, err)
+	}
+
+	return nil
+}
+
+func (r *sessionRepository) RevokeOthers(userID uint64, keep string, at time.Time) error {
+	_, err := r.db.Exec("UPDATE sessions SET revoked_at = $1 WHERE user_id = $2 AND id <> $3 AND revoked_at IS NULL", at, userID, keep)
+	if err != nil {
+		return errorutils.New(errorutils.ErrSessionRevoke, err)
+	}
+
+	return nil
+}
+
+func (r *sessionRepository) CreateRefreshToken(t *model.RefreshToken) error {
+	err := r.db.QueryRow(`INSERT INTO refresh_tokens (session_id, user_id, token_hash, created_at, expires_at)
+	VALUES ($1, $2, $3, $4, $5) RETURNING id`, t.SessionID, t.UserID, t.TokenHash, t.CreatedAt, t.ExpiresAt).Scan(&t.ID)
+	if err != nil {
+		return errorutils.New(errorutils.ErrSessionCreate, err)
+	}
+
+	return nil
+}
+
+func (r *sessionRepository) ReadRefreshToken(hash string) (*model.RefreshToken, error) {
+	t := new(model.RefreshToken)
+
+	err := r.db.QueryRow(`SELECT id, session_id, user_id, token_hash, created_at, expires_at, used_at
+	FROM refresh_tokens WHERE token_hash = $1`, hash).
+		Scan(&t.ID, &t.SessionID, &t.UserID, &t.TokenHash, &t.CreatedAt, &t.ExpiresAt, &t.UsedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errorutils.New(errorutils.ErrInvalidToken, err)
+	}
+
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrSessionRead, err)
+	}
+
+	return t, nil
+}
+
+func (r *sessionRepository) UseRefreshToken(id uint64, at time.Time) (bool, error) {
+	res, err := r.db.Exec("UPDATE refresh_tokens SET used_at = $1 WHERE id = $2 AND used_at IS NULL", at, id)
+	if err != nil {
+		return false, errorutils.New(errorutils.ErrSessionRevoke, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, errorutils.New(errorutils.ErrSessionRevoke, err)
+	}
+
+	return n == 1, nil
+}
@@ -0,0 +1,150 @@
+package postgresadapter
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/repository"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/pagination"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
+)
+
+// tagColumns is the column list scanIntoTag expects, in order.
+const tagColumns = `id, name, slug, created_at, updated_at, created_by, updated_by`
+
+type tagRepository struct {
+	db *sql.DB
+}
+
+func NewTagRepository(db *sql.DB) repository.Tag {
+	return &tagRepository{
+		db: db,
+	}
+}
+
+func (r *tagRepository) Create(t *model.Tag) error {
+	err := r.db.QueryRow(`INSERT INTO tags (name, slug, created_at, updated_at, created_by, updated_by)
+	VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+		t.Name, t.Slug, t.CreatedAt, t.UpdatedAt, t.CreatedBy, t.UpdatedBy).Scan(&t.ID)
+	if err != nil {
+		return tagError(err, errorutils.ErrTagCreate)
+	}
+
+	return nil
+}
+
+func (r *tagRepository) Read(id uint64) (*model.Tag, error) {
+	rows, err := r.db.Query("SELECT "+tagColumns+" FROM tags WHERE id = $1", id)
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrInvalidRequest, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		t, err := scanIntoTag(rows)
+		if err != nil {
+			return nil, errorutils.New(errorutils.ErrTagNotFound, err)
+		}
+
+		return t, nil
+	}
+
+	return nil, errorutils.New(errorutils.ErrTagNotFound, errorutils.ErrTagRead)
+}
+
+func (r *tagRepository) ReadsBySlugs(slugs []string) ([]model.Tag, error) {
+	rows, err := r.db.Query("SELECT "+tagColumns+" FROM tags WHERE slug = ANY($1) ORDER BY name", pq.Array(slugs))
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrTagReads, err)
+	}
+	defer rows.Close()
+
+	var tags []model.Tag
+
+	for rows.Next() {
+		t, err := scanIntoTag(rows)
+		if err != nil {
+			return nil, errorutils.New(errorutils.ErrTagReads, err)
+		}
+
+		tags = append(tags, *t)
+	}
+
+	return tags, nil
+}
+
+func (r *tagRepository) Reads(p *pagination.Pageable) (*[]model.Tag, error) {
+	rows, err := r.db.Query("SELECT "+tagColumns+", COUNT(*) OVER() AS count FROM tags ORDER BY "+
+		fmt.Sprintf("%s LIMIT $1 OFFSET $2;", p.Order()), p.Size, p.Offset())
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrTagReads, err)
+	}
+	defer rows.Close()
+
+	var tags []model.Tag
+
+	var count int64
+
+	for rows.Next() {
+		t := new(model.Tag)
+
+		err := rows.Scan(&t.ID, &t.Name, &t.Slug, &t.CreatedAt, &t.UpdatedAt, &t.CreatedBy, &t.UpdatedBy, &count)
+		if err != nil {
+			return nil, errorutils.New(errorutils.ErrTagReads, err)
+		}
+
+		tags = append(tags, *t)
+	}
+
+	p.TotalCount = count
+
+	return &tags, nil
+}
+
+func (r *tagRepository) Update(t *model.Tag) error {
+	res, err := r.db.Exec("UPDATE tags SET name = $1, slug = $2, updated_at = $3, updated_by = $4 WHERE id = $5",
+		t.Name, t.Slug, t.UpdatedAt, t.UpdatedBy, t.ID)
+	if err != nil {
+		return tagError(err, errorutils.ErrTagUpdate)
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return errorutils.New(errorutils.ErrTagNotFound, errorutils.ErrTagUpdate)
+	}
+
+	return nil
+}
+
+func (r *tagRepository) Delete(id uint64) error {
+	res, err := r.db.Exec("DELETE FROM tags WHERE id = $1", id)
+	if err != nil {
+		return errorutils.New(errorutils.ErrTagDelete, err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return errorutils.New(errorutils.ErrTagNotFound, errorutils.ErrTagDelete)
+	}
+
+	return nil
+}
+
+// tagError reports slug collisions as such and everything else as reason.
+func tagError(err, reason error) error {
+	var pErr *pq.Error
+	if errors.As(err, &pErr) && pErr.Constraint == "tags_slug_key" {
+		return errorutils.New(errorutils.ErrTagAlreadyExists, err)
+	}
+
+	return errorutils.New(reason, err)
+}
+
+func scanIntoTag(rows *sql.Rows) (*model.Tag, error) {
+	t := new(model.Tag)
+	err := rows.Scan(&t.ID, &t.Name, &t.Slug, &t.CreatedAt, &t.UpdatedAt, &t.CreatedBy, &t.UpdatedBy)
+
+	return t, err
+}
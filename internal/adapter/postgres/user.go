@@ -4,15 +4,22 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/lib/pq"
 
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/repository"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/pagination"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
 )
 
+// userColumns is the column list scanIntoUser expects, in order.
+const userColumns = `id, encrypted_password, username, email, user_role, created_at, updated_at,
+	status, deleted_at, created_by, updated_by, deleted_by, email_verified_at, suspended_reason, suspended_until,
+	totp_secret, totp_enabled_at`
+
 type userRepository struct {
 	db *sql.DB
 }
@@ -25,28 +32,27 @@ func NewUserRepository(db *sql.DB) repository.User {
 
 func (r *userRepository) Create(u *model.User) error {
 	query := `INSERT INTO users 
-    (email, username, encrypted_password, user_role, created_at, updated_at)
-    VALUES ($1, $2, $3, $4, $5, $6)`
-
-	_, err := r.db.Query(query, u.Email, u.Username, u.EncryptedPassword, u.Role, u.CreatedAt, u.UpdatedAt)
+    (email, username, encrypted_password, user_role, created_at, updated_at, status, created_by, updated_by)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`
 
-	var pErr *pq.Error
+	err := r.db.QueryRow(query, u.Email, u.Username, u.EncryptedPassword, u.Role, u.CreatedAt, u.UpdatedAt, u.Status, u.CreatedBy, u.UpdatedBy).Scan(&u.ID)
 	if err != nil {
-		switch errors.As(err, &pErr) {
-		case pErr.Constraint == "users_username_key":
-			return errorutils.New(errorutils.ErrUsernameAlreadyTaken, err)
-		case pErr.Constraint == "users_email_key":
-			return errorutils.New(errorutils.ErrEmailAlreadyTaken, err)
-		}
-
-		return errorutils.New(errorutils.ErrUserCreate, err)
+		return userWriteError(err, errorutils.ErrUserCreate)
 	}
 
 	return nil
 }
 
 func (r *userRepository) Read(i uint64) (*model.User, error) {
-	rows, err := r.db.Query("SELECT * FROM users WHERE id = $1", i)
+	return r.readOne("SELECT "+userColumns+" FROM users WHERE id = $1 AND deleted_at IS NULL", i)
+}
+
+func (r *userRepository) ReadAny(i uint64) (*model.User, error) {
+	return r.readOne("SELECT "+userColumns+" FROM users WHERE id = $1", i)
+}
+
+func (r *userRepository) readOne(query string, args ...any) (*model.User, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, errorutils.New(errorutils.ErrInvalidRequest, err)
 	}
@@ -64,7 +70,7 @@ func (r *userRepository) Read(i uint64) (*model.User, error) {
 }
 
 func (r *userRepository) ReadByEmail(e string) (*model.User, error) {
-	rows, err := r.db.Query("SELECT * FROM users WHERE email = $1", e)
+	rows, err := r.db.Query("SELECT "+userColumns+" FROM users WHERE email = $1 AND deleted_at IS NULL", e)
 	if err != nil {
 		return nil, errorutils.New(errorutils.ErrInvalidRequest, err)
 	}
@@ -81,12 +87,17 @@ func (r *userRepository) ReadByEmail(e string) (*model.User, error) {
 	return nil, errorutils.New(errorutils.ErrEmailNotFound, errorutils.ErrUserRead)
 }
 
-func (r *userRepository) Reads(p *pagination.Pageable) (*[]model.User, error) {
+func (r *userRepository) Reads(p *pagination.Pageable, f repository.ReadsFilter) (*[]model.User, error) {
+	where := ""
+	if !f.IncludeDeleted {
+		where = "WHERE deleted_at IS NULL "
+	}
+
 	// Note: Just for show off. I know it can be handled in single query :)
-	fq := `SELECT * FROM users ORDER BY ` +
+	fq := `SELECT ` + userColumns + ` FROM users ` + where + `ORDER BY ` +
 		fmt.Sprintf("%s LIMIT $1 OFFSET $2;", p.Order())
 
-	cq := `SELECT COUNT(*) FROM users;`
+	cq := `SELECT COUNT(*) FROM users ` + where + `;`
 
 	countErr := make(chan error)
 	findErr := make(chan error)
@@ -141,35 +152,290 @@ func (r *userRepository) Reads(p *pagination.Pageable) (*[]model.User, error) {
 	return &users, nil
 }
 
-func (r *userRepository) Update(u *model.User) error {
-	_, err := r.db.Query("UPDATE users SET username = $1, updated_at = $2 WHERE id = $3;", u.Username, u.UpdatedAt, u.ID)
+func (r *userRepository) Update(u *model.User, version time.Time) error {
+	res, err := r.db.Exec("UPDATE users SET username = $1, updated_at = $2, updated_by = $3 WHERE id = $4 AND updated_at = $5;",
+		u.Username, u.UpdatedAt, u.UpdatedBy, u.ID, version)
+	if err != nil {
+		return userWriteError(err, errorutils.ErrUserUpdate)
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return errorutils.New(errorutils.ErrPreconditionFailed, errorutils.ErrUserUpdate)
+	}
 
-	var pErr *pq.Error
+	return nil
+}
 
+func (r *userRepository) UpdatePassword(u *model.User) error {
+	_, err := r.db.Exec("UPDATE users SET encrypted_password = $1, updated_at = $2, updated_by = $3 WHERE id = $4;", u.EncryptedPassword, u.UpdatedAt, u.UpdatedBy, u.ID)
 	if err != nil {
-		switch errors.As(err, &pErr) {
-		case pErr.Constraint == "users_username_key":
-			return errorutils.New(errorutils.ErrUsernameAlreadyTaken, err)
+		return errorutils.New(errorutils.ErrUserPasswordUpdate, err)
+	}
+
+	return nil
+}
+
+// UpdateEmail changes the email of the user, who has to verify it again.
+func (r *userRepository) UpdateEmail(u *model.User) error {
+	_, err := r.db.Exec("UPDATE users SET email = $1, email_verified_at = NULL, updated_at = $2, updated_by = $3 WHERE id = $4;", u.Email, u.UpdatedAt, u.UpdatedBy, u.ID)
+	if err != nil {
+		return userWriteError(err, errorutils.ErrUserUpdate)
+	}
+
+	u.EmailVerifiedAt = nil
+
+	return nil
+}
+
+func (r *userRepository) VerifyEmail(id uint64, at time.Time) error {
+	_, err := r.db.Exec("UPDATE users SET email_verified_at = $1 WHERE id = $2 AND email_verified_at IS NULL;", at, id)
+	if err != nil {
+		return errorutils.New(errorutils.ErrUserUpdate, err)
+	}
+
+	return nil
+}
+
+func (r *userRepository) ReadStats(id uint64) (*model.UserStats, error) {
+	st := new(model.UserStats)
+
+	err := r.db.QueryRow(`SELECT
+	(SELECT COUNT(*) FROM posts WHERE user_id = $1 AND deleted_at IS NULL),
+	(SELECT COUNT(*) FROM comments WHERE user_id = $1 AND deleted_at IS NULL)`, id).Scan(&st.Posts, &st.Comments)
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrUserRead, err)
+	}
+
+	return st, nil
+}
+
+func (r *userRepository) UpdateRole(u *model.User, version time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return errorutils.New(errorutils.ErrUserRoleUpdate, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if u.Role != types.Admin {
+		if err = keepAdmin(tx, u.ID, u.UpdatedAt, errorutils.ErrUserRoleUpdate); err != nil {
+			return err
+		}
+	}
+
+	res, err := tx.Exec("UPDATE users SET user_role = $1, updated_at = $2, updated_by = $3 WHERE id = $4 AND updated_at = $5;",
+		u.Role, u.UpdatedAt, u.UpdatedBy, u.ID, version)
+	if err != nil {
+		return userWriteError(err, errorutils.ErrUserRoleUpdate)
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return errorutils.New(errorutils.ErrPreconditionFailed, errorutils.ErrUserRoleUpdate)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errorutils.New(errorutils.ErrUserRoleUpdate, err)
+	}
+
+	return nil
+}
+
+func (r *userRepository) UpdateStatus(u *model.User, version time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return errorutils.New(errorutils.ErrUserStatusUpdate, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if u.Suspended(u.UpdatedAt) {
+		if err = keepAdmin(tx, u.ID, u.UpdatedAt, errorutils.ErrUserStatusUpdate); err != nil {
+			return err
+		}
+	}
+
+	res, err := tx.Exec(`UPDATE users SET status = $1, suspended_reason = $2, suspended_until = $3, updated_at = $4, updated_by = $5
+	WHERE id = $6 AND updated_at = $7;`, u.Status, u.SuspendedReason, u.SuspendedUntil, u.UpdatedAt, u.UpdatedBy, u.ID, version)
+	if err != nil {
+		return errorutils.New(errorutils.ErrUserStatusUpdate, err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return errorutils.New(errorutils.ErrPreconditionFailed, errorutils.ErrUserStatusUpdate)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errorutils.New(errorutils.ErrUserStatusUpdate, err)
+	}
+
+	return nil
+}
+
+// keepAdmin locks the admins able to act at now and fails when id is the only
+// one, so concurrent writes can not take away the last admin between them.
+func keepAdmin(tx *sql.Tx, id uint64, now time.Time, reason error) error {
+	rows, err := tx.Query(`SELECT id FROM users WHERE user_role = 'admin' AND deleted_at IS NULL
+	AND (status = 'active' OR suspended_until <= $1) FOR UPDATE`, now)
+	if err != nil {
+		return errorutils.New(reason, err)
+	}
+	defer rows.Close()
+
+	var ids []uint64
+
+	for rows.Next() {
+		var admin uint64
+		if err = rows.Scan(&admin); err != nil {
+			return errorutils.New(reason, err)
 		}
 
+		ids = append(ids, admin)
+	}
+
+	if err = rows.Err(); err != nil {
+		return errorutils.New(reason, err)
+	}
+
+	if len(ids) == 1 && ids[0] == id {
+		return errorutils.New(errorutils.ErrLastAdmin, nil)
+	}
+
+	return nil
+}
+
+func (r *userRepository) SetTOTPSecret(u *model.User) error {
+	_, err := r.db.Exec("UPDATE users SET totp_secret = $1, totp_enabled_at = NULL, totp_last_step = 0 WHERE id = $2;", u.TOTPSecret, u.ID)
+	if err != nil {
+		return errorutils.New(errorutils.ErrUserUpdate, err)
+	}
+
+	u.TOTPEnabledAt = nil
+
+	return nil
+}
+
+func (r *userRepository) EnableTOTP(id uint64, at time.Time) error {
+	_, err := r.db.Exec("UPDATE users SET totp_enabled_at = $1 WHERE id = $2 AND totp_secret <> '';", at, id)
+	if err != nil {
+		return errorutils.New(errorutils.ErrUserUpdate, err)
+	}
+
+	return nil
+}
+
+func (r *userRepository) UseTOTPStep(id uint64, step int64) (bool, error) {
+	res, err := r.db.Exec("UPDATE users SET totp_last_step = $1 WHERE id = $2 AND totp_last_step < $1;", step, id)
+	if err != nil {
+		return false, errorutils.New(errorutils.ErrUserUpdate, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, errorutils.New(errorutils.ErrUserUpdate, err)
+	}
+
+	return n == 1, nil
+}
+
+func (r *userRepository) DisableTOTP(id uint64) error {
+	_, err := r.db.Exec("UPDATE users SET totp_secret = '', totp_enabled_at = NULL, totp_last_step = 0 WHERE id = $1;", id)
+	if err != nil {
 		return errorutils.New(errorutils.ErrUserUpdate, err)
 	}
 
 	return nil
 }
 
-func (r *userRepository) Delete(i uint64) error {
-	_, err := r.db.Query("DELETE FROM users WHERE id = $1", i)
+func (r *userRepository) Delete(u *model.User, version time.Time) error {
+	tx, err := r.db.Begin()
 	if err != nil {
 		return errorutils.New(errorutils.ErrUserDelete, err)
 	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err = keepAdmin(tx, u.ID, *u.DeletedAt, errorutils.ErrUserDelete); err != nil {
+		return err
+	}
+
+	res, err := tx.Exec("UPDATE users SET deleted_at = $1, deleted_by = $2 WHERE id = $3 AND deleted_at IS NULL AND updated_at = $4;",
+		u.DeletedAt, u.DeletedBy, u.ID, version)
+	if err != nil {
+		return errorutils.New(errorutils.ErrUserDelete, err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return errorutils.New(errorutils.ErrPreconditionFailed, errorutils.ErrUserDelete)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errorutils.New(errorutils.ErrUserDelete, err)
+	}
+
+	return nil
+}
+
+func (r *userRepository) Restore(u *model.User) error {
+	res, err := r.db.Exec(`UPDATE users SET deleted_at = NULL, deleted_by = '', updated_at = $1, updated_by = $2
+	WHERE id = $3 AND deleted_at IS NOT NULL;`, u.UpdatedAt, u.UpdatedBy, u.ID)
+	if err != nil {
+		return errorutils.New(errorutils.ErrUserRestore, err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return errorutils.New(errorutils.ErrUserNotFound, errorutils.ErrUserRestore)
+	}
+
+	return nil
+}
+
+func (r *userRepository) Purge(i uint64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return errorutils.New(errorutils.ErrUserPurge, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err = keepAdmin(tx, i, time.Now(), errorutils.ErrUserPurge); err != nil {
+		return err
+	}
+
+	res, err := tx.Exec("DELETE FROM users WHERE id = $1", i)
+	if err != nil {
+		return errorutils.New(errorutils.ErrUserPurge, err)
+	}
+
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return errorutils.New(errorutils.ErrUserNotFound, errorutils.ErrUserPurge)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errorutils.New(errorutils.ErrUserPurge, err)
+	}
 
 	return nil
 }
 
 func scanIntoUser(rows *sql.Rows) (*model.User, error) {
 	u := new(model.User)
-	err := rows.Scan(&u.ID, &u.EncryptedPassword, &u.Username, &u.Email, &u.Role, &u.CreatedAt, &u.UpdatedAt)
+	err := rows.Scan(&u.ID, &u.EncryptedPassword, &u.Username, &u.Email, &u.Role, &u.CreatedAt, &u.UpdatedAt,
+		&u.Status, &u.DeletedAt, &u.CreatedBy, &u.UpdatedBy, &u.DeletedBy, &u.EmailVerifiedAt,
+		&u.SuspendedReason, &u.SuspendedUntil, &u.TOTPSecret, &u.TOTPEnabledAt)
 
 	return u, err
 }
+
+// userWriteError maps unique violations on users to the taken errors, an
+// unknown role to ErrRoleNotFound and wraps anything else in reason.
+func userWriteError(err error, reason error) error {
+	var pErr *pq.Error
+	if errors.As(err, &pErr) {
+		switch pErr.Constraint {
+		case "users_username_key":
+			return errorutils.New(errorutils.ErrUsernameAlreadyTaken, err)
+		case "users_email_key":
+			return errorutils.New(errorutils.ErrEmailAlreadyTaken, err)
+		case "users_user_role_fkey":
+			return errorutils.New(errorutils.ErrRoleNotFound, err)
+		}
+	}
+
+	return errorutils.New(reason, err)
+}
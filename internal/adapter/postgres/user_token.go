@@ -0,0 +1,83 @@
+package postgresadapter
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/repository"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
+)
+
+type userTokenRepository struct {
+	db *sql.DB
+}
+
+func NewUserTokenRepository(db *sql.DB) repository.UserToken {
+	return &userTokenRepository{
+		db: db,
+	}
+}
+
+func (r *userTokenRepository) Create(t *model.UserToken) error {
+	err := r.db.QueryRow(`INSERT INTO user_tokens (user_id, purpose, token_hash, created_at, expires_at)
+	VALUES ($1, $2, $3, $4, $5) RETURNING id`, t.UserID, t.Purpose, t.TokenHash, t.CreatedAt, t.ExpiresAt).Scan(&t.ID)
+	if err != nil {
+		return errorutils.New(errorutils.ErrUserTokenCreate, err)
+	}
+
+	return nil
+}
+
+func (r *userTokenRepository) Read(hash string, purpose types.TokenPurpose) (*model.UserToken, error) {
+	t := new(model.UserToken)
+
+	err := r.db.QueryRow(`SELECT id, user_id, purpose, token_hash, created_at, expires_at, used_at
+	FROM user_tokens WHERE token_hash = $1 AND purpose = $2`, hash, purpose).
+		Scan(&t.ID, &t.UserID, &t.Purpose, &t.TokenHash, &t.CreatedAt, &t.ExpiresAt, &t.UsedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errorutils.New(errorutils.ErrInvalidToken, err)
+	}
+
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrUserTokenRead, err)
+	}
+
+	return t, nil
+}
+
+func (r *userTokenRepository) Use(id uint64, at time.Time) (bool, error) {
+	res, err := r.db.Exec("UPDATE user_tokens SET used_at = $1 WHERE id = $2 AND used_at IS NULL", at, id)
+	if err != nil {
+		return false, errorutils.New(errorutils.ErrUserTokenUpdate, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, errorutils.New(errorutils.ErrUserTokenUpdate, err)
+	}
+
+	return n == 1, nil
+}
+
+func (r *userTokenRepository) UseAll(userID uint64, purpose types.TokenPurpose, at time.Time) error {
+	_, err := r.db.Exec("UPDATE user_tokens SET used_at = $1 WHERE user_id = $2 AND purpose = $3 AND used_at IS NULL", at, userID, purpose)
+	if err != nil {
+		return errorutils.New(errorutils.ErrUserTokenUpdate, err)
+	}
+
+	return nil
+}
+
+func (r *userTokenRepository) LastCreatedAt(userID uint64, purpose types.TokenPurpose) (*time.Time, error) {
+	var at *time.Time
+
+	err := r.db.QueryRow("SELECT MAX(created_at) FROM user_tokens WHERE user_id = $1 AND purpose = $2", userID, purpose).Scan(&at)
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrUserTokenRead, err)
+	}
+
+	return at, nil
+}
@@ -3,6 +3,7 @@ package appcontext
 import (
 	"context"
 	"errors"
+	"strconv"
 
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
@@ -92,15 +93,26 @@ func MtsBlogRole(ctx context.Context) (types.Role, error) {
 }
 
 // MtsBlogUserID returns the userID value associated with the mtsBlogUserIDCtxKey in the given appcontext.
-func MtsBlogUserID(ctx context.Context) (string, error) {
-	userID, ok := ctx.Value(mtsBlogUserIDCtxKey{}).(string)
+func MtsBlogUserID(ctx context.Context) (uint64, error) {
+	userID, ok := ctx.Value(mtsBlogUserIDCtxKey{}).(uint64)
 	if !ok {
-		return "", ErrInvalidUserID
+		return 0, ErrInvalidUserID
 	}
 
 	return userID, nil
 }
 
+// MtsBlogActor returns the user ID in the given appcontext formatted for the
+// created_by, updated_by and deleted_by audit columns.
+func MtsBlogActor(ctx context.Context) (string, error) {
+	userID, err := MtsBlogUserID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return strconv.FormatUint(userID, 10), nil
+}
+
 // Lang returns the language value associated with the langCtxKey in the given appcontext.
 func Lang(ctx context.Context) (string, error) {
 	lang, ok := ctx.Value(langCtxKey{}).(string)
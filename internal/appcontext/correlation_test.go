@@ -48,3 +48,30 @@ func TestCorrelationID(t *testing.T) {
 		})
 	}
 }
+
+func TestMtsBlogActor(t *testing.T) {
+	cases := map[string]struct {
+		ctx     context.Context
+		want    string
+		wantErr bool
+	}{
+		"user ID missing": {
+			ctx:     context.Background(),
+			want:    "",
+			wantErr: true,
+		},
+		"appcontext with user ID": {
+			ctx:     appcontext.WithMtsBlogUserID(context.Background(), 42),
+			want:    "42",
+			wantErr: false,
+		},
+	}
+
+	for desc, tc := range cases {
+		t.Run(desc, func(t *testing.T) {
+			got, err := appcontext.MtsBlogActor(tc.ctx)
+			assert.Equal(t, tc.wantErr, err != nil)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,348 @@
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrInvalidFileName   = errors.New("invalid migration file name")
+	ErrDuplicateVersion  = errors.New("duplicate migration version")
+	ErrMissingUp         = errors.New("migration has no up script")
+	ErrMissingDown       = errors.New("migration has no down script")
+	ErrChecksumMismatch  = errors.New("applied migration checksum does not match the embedded script")
+	ErrDatabaseAhead     = errors.New("database schema is ahead of this binary")
+	ErrNothingToRollback = errors.New("no applied migration to roll back")
+)
+
+// lockID is the Postgres advisory lock key held while migrations run, so
+// replicas starting at the same time apply the pending set only once.
+const lockID int64 = 7_041_990_212
+
+// fileNamePattern matches names like "0002_add_audit_columns.up.sql".
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration is a single versioned schema change.
+type Migration struct {
+	Version  uint64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// Applied is a row of the schema_migrations table.
+type Applied struct {
+	Version   uint64
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// Load reads every migration script in dir and returns them ordered by version.
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[uint64]*Migration)
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidFileName, entry.Name())
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidFileName, entry.Name())
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("%w: %d", ErrDuplicateVersion, version)
+		}
+
+		switch match[3] {
+		case "up":
+			m.Up = string(content)
+		case "down":
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("%w: %d_%s", ErrMissingUp, m.Version, m.Name)
+		}
+
+		if m.Down == "" {
+			return nil, fmt.Errorf("%w: %d_%s", ErrMissingDown, m.Version, m.Name)
+		}
+
+		m.Checksum = checksum(m.Up)
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+func checksum(s string) string {
+	sum := sha256.Sum256([]byte(s))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// Migrator applies and rolls back migrations against a Postgres database.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+func New(db *sql.DB, migrations []Migration) *Migrator {
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+	}
+}
+
+// Up applies every pending migration in version order. It fails if an applied
+// migration was modified or if the database knows versions this binary doesn't.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.verify(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, mg := range m.Pending(applied) {
+			if err := m.apply(ctx, conn, mg); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Down rolls back the last n applied migrations.
+func (m *Migrator) Down(ctx context.Context, n int) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.verify(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		if len(applied) == 0 {
+			return ErrNothingToRollback
+		}
+
+		for i := len(applied) - 1; i >= 0 && n > 0; i, n = i-1, n-1 {
+			mg, _ := m.find(applied[i].Version)
+
+			if err := m.rollback(ctx, conn, mg); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Applied returns the rows of schema_migrations ordered by version.
+func (m *Migrator) Applied(ctx context.Context) ([]Applied, error) {
+	var applied []Applied
+
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		var err error
+		applied, err = readApplied(ctx, conn)
+
+		return err
+	})
+
+	return applied, err
+}
+
+// Pending returns the known migrations that are not in applied.
+func (m *Migrator) Pending(applied []Applied) []Migration {
+	done := make(map[uint64]bool, len(applied))
+	for _, a := range applied {
+		done[a.Version] = true
+	}
+
+	var pending []Migration
+
+	for _, mg := range m.migrations {
+		if !done[mg.Version] {
+			pending = append(pending, mg)
+		}
+	}
+
+	return pending
+}
+
+func (m *Migrator) find(version uint64) (Migration, bool) {
+	for _, mg := range m.migrations {
+		if mg.Version == version {
+			return mg, true
+		}
+	}
+
+	return Migration{}, false
+}
+
+// verify checks every applied migration against the embedded set.
+func (m *Migrator) verify(ctx context.Context, conn *sql.Conn) ([]Applied, error) {
+	applied, err := readApplied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range applied {
+		mg, ok := m.find(a.Version)
+		if !ok {
+			return nil, fmt.Errorf("%w: version %d (%s) is unknown", ErrDatabaseAhead, a.Version, a.Name)
+		}
+
+		if mg.Checksum != a.Checksum {
+			return nil, fmt.Errorf("%w: version %d (%s)", ErrChecksumMismatch, a.Version, a.Name)
+		}
+	}
+
+	return applied, nil
+}
+
+func (m *Migrator) withLock(ctx context.Context, fn func(*sql.Conn) error) (err error) {
+	// Advisory locks belong to a session, so everything runs on one connection.
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err = conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockID); err != nil {
+		return err
+	}
+
+	defer func() {
+		_, uErr := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockID)
+		if err == nil {
+			err = uErr
+		}
+	}()
+
+	if err = createMigrationsTable(ctx, conn); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, mg Migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, mg.Up); err != nil {
+		_ = tx.Rollback()
+
+		return fmt.Errorf("migration %d_%s up: %w", mg.Version, mg.Name, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, name, checksum, applied_at)
+	VALUES ($1, $2, $3, $4)`, mg.Version, mg.Name, mg.Checksum, time.Now())
+	if err != nil {
+		_ = tx.Rollback()
+
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (m *Migrator) rollback(ctx context.Context, conn *sql.Conn, mg Migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, mg.Down); err != nil {
+		_ = tx.Rollback()
+
+		return fmt.Errorf("migration %d_%s down: %w", mg.Version, mg.Name, err)
+	}
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = $1", mg.Version); err != nil {
+		_ = tx.Rollback()
+
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func createMigrationsTable(ctx context.Context, conn *sql.Conn) error {
+	query := `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version 		   bigint PRIMARY KEY,
+    name 			   varchar(255) NOT NULL,
+    checksum 		   varchar(64) NOT NULL,
+    applied_at 		   timestamp NOT NULL
+	)`
+
+	_, err := conn.ExecContext(ctx, query)
+
+	return err
+}
+
+func readApplied(ctx context.Context, conn *sql.Conn) ([]Applied, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, name, checksum, applied_at FROM schema_migrations ORDER BY version")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var applied []Applied
+
+	for rows.Next() {
+		var a Applied
+		if err := rows.Scan(&a.Version, &a.Name, &a.Checksum, &a.AppliedAt); err != nil {
+			return nil, err
+		}
+
+		applied = append(applied, a)
+	}
+
+	return applied, rows.Err()
+}
@@ -0,0 +1,96 @@
+package migrate_test
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/assets"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/database/migrate"
+)
+
+func TestLoad(t *testing.T) {
+	cases := map[string]struct {
+		files    fstest.MapFS
+		versions []uint64
+		wantErr  error
+	}{
+		"ordered by version": {
+			files: fstest.MapFS{
+				"m/0002_second.up.sql":   {Data: []byte("SELECT 2;")},
+				"m/0002_second.down.sql": {Data: []byte("SELECT -2;")},
+				"m/0001_first.up.sql":    {Data: []byte("SELECT 1;")},
+				"m/0001_first.down.sql":  {Data: []byte("SELECT -1;")},
+			},
+			versions: []uint64{1, 2},
+		},
+		"invalid file name": {
+			files: fstest.MapFS{
+				"m/first.sql": {Data: []byte("SELECT 1;")},
+			},
+			wantErr: migrate.ErrInvalidFileName,
+		},
+		"missing down script": {
+			files: fstest.MapFS{
+				"m/0001_first.up.sql": {Data: []byte("SELECT 1;")},
+			},
+			wantErr: migrate.ErrMissingDown,
+		},
+		"missing up script": {
+			files: fstest.MapFS{
+				"m/0001_first.down.sql": {Data: []byte("SELECT -1;")},
+			},
+			wantErr: migrate.ErrMissingUp,
+		},
+		"duplicate version": {
+			files: fstest.MapFS{
+				"m/0001_first.up.sql":   {Data: []byte("SELECT 1;")},
+				"m/0001_first.down.sql": {Data: []byte("SELECT -1;")},
+				"m/0001_other.up.sql":   {Data: []byte("SELECT 1;")},
+			},
+			wantErr: migrate.ErrDuplicateVersion,
+		},
+	}
+
+	for desc, tc := range cases {
+		t.Run(desc, func(t *testing.T) {
+			ms, err := migrate.Load(tc.files, "m")
+			if tc.wantErr != nil {
+				assert.True(t, errors.Is(err, tc.wantErr), "got %v, want %v", err, tc.wantErr)
+
+				return
+			}
+
+			assert.NoError(t, err)
+
+			var versions []uint64
+			for _, m := range ms {
+				versions = append(versions, m.Version)
+				assert.Len(t, m.Checksum, 64)
+			}
+
+			assert.Equal(t, tc.versions, versions)
+		})
+	}
+}
+
+func TestLoadEmbedded(t *testing.T) {
+	ms, err := migrate.Load(assets.EmbeddedFiles, "migrations")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ms)
+
+	for i, m := range ms {
+		assert.Equal(t, uint64(i+1), m.Version, "migration versions must be contiguous")
+	}
+}
+
+func TestPending(t *testing.T) {
+	m := migrate.New(nil, []migrate.Migration{{Version: 1}, {Version: 2}, {Version: 3}})
+
+	pending := m.Pending([]migrate.Applied{{Version: 1}, {Version: 3}})
+
+	assert.Len(t, pending, 1)
+	assert.Equal(t, uint64(2), pending[0].Version)
+}
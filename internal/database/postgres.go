@@ -1,12 +1,16 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
 	"sync"
 
 	_ "github.com/lib/pq"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/assets"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/database/migrate"
 )
 
 var (
@@ -49,73 +53,26 @@ func (s postgresStore) GetInstance() *sql.DB {
 
 func (s postgresStore) InitDB() {
 	initOnce.Do(func() {
-		s.createUsersEnumRoles()
-		s.createUsersTable()
-		s.createPostsTable()
-		s.createCommentsTable()
-	})
-}
-
-func (s postgresStore) createUsersEnumRoles() {
-	query := `DO $$ BEGIN
-	IF to_regtype('user_roles') IS NULL THEN
-	CREATE TYPE user_roles AS ENUM('admin', 'mod', 'registered');
-	END IF;
-	END $$;
-	`
-
-	_, err := s.DB.Exec(query)
-	if err != nil {
-		log.Fatal(err.Error())
-	}
-}
+		m, err := NewMigrator(s.DB)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
 
-func (s postgresStore) createUsersTable() {
-	query := `CREATE TABLE IF NOT EXISTS users (
-    id				   serial PRIMARY KEY,
-    encrypted_password varchar(500) NOT NULL, 
-    username 		   varchar(21) NOT NULL UNIQUE, 
-    email 			   varchar(55) NOT NULL UNIQUE,
-	user_role     	   user_roles,
-    created_at 		   timestamp,
-    updated_at 		   timestamp
-	)`
-
-	_, err := s.DB.Exec(query)
-	if err != nil {
-		log.Fatal(err.Error())
-	}
+		// Apply pending migrations; refuse to start on a newer or modified schema.
+		if err := m.Up(context.Background()); err != nil {
+			log.Fatal(err.Error())
+		}
+	})
 }
 
-func (s postgresStore) createPostsTable() {
-	query := `CREATE TABLE IF NOT EXISTS posts (
-    id 				   serial PRIMARY KEY,
-    title 			   varchar(255),
-	body 			   varchar, 
-    created_at 		   timestamp,
-    updated_at 		   timestamp
-	)`
-
-	_, err := s.DB.Exec(query)
+// NewMigrator returns a migrator loaded with the embedded migration set.
+func NewMigrator(db *sql.DB) (*migrate.Migrator, error) {
+	ms, err := migrate.Load(assets.EmbeddedFiles, "migrations")
 	if err != nil {
-		log.Fatal(err.Error())
+		return nil, err
 	}
-}
 
-func (s postgresStore) createCommentsTable() {
-	query := `CREATE TABLE IF NOT EXISTS comments (
-    id 				   serial PRIMARY KEY,
-	author 			   varchar references users(username), 
-	user_id 		   int references users(id),
-	post_id 		   int references posts(id),
-    text 			   varchar(255),
-    created_at 		   timestamp
-	)`
-
-	_, err := s.DB.Exec(query)
-	if err != nil {
-		log.Fatal(err.Error())
-	}
+	return migrate.New(db, ms), nil
 }
 
 func postgresStoreDefaultOpts() StoreOpts {
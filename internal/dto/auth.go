@@ -1,6 +1,10 @@
 package dto
 
-import "github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+import (
+	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+)
 
 // Claims is the JWT claims.
 type Claims struct {
@@ -8,12 +12,22 @@ type Claims struct {
 	Role     types.Role `json:"role"`
 	Username string     `json:"username"`
 	Email    string     `json:"email"`
+	// SessionID is the session the token was issued in; revoking it ends the token.
+	SessionID string `json:"sid"`
+	// EmailVerified reports whether the user verified their email.
+	EmailVerified bool `json:"emailVerified"`
+	// TwoFactor reports whether the user has two-factor authentication on.
+	TwoFactor bool `json:"twoFactor"`
+	// APIKeyID is the api key the request was made with, if it was.
+	APIKeyID uint64 `json:"-"`
 }
 
 // LoginRequest is the request body for the user login endpoint.
 type LoginRequest struct {
 	Email    string `json:"email"          validate:"required,email"`
 	Password string `json:"password"       validate:"required,min=6,max=55"`
+	// IP is the address of the client, set by the handler.
+	IP string `json:"-"`
 }
 
 // RegisterRequest is the request body for the user register endpoint.
@@ -25,7 +39,100 @@ type RegisterRequest struct {
 }
 
 // WithTokenResponse is the response body for the user login endpoint.
+// Token is a short lived access token; RefreshToken is single use and trades
+// for a new pair at the refresh endpoint.
 type WithTokenResponse struct {
 	Claims
-	Token string `json:"token"`
+	Token        string    `json:"token"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+	RefreshToken string    `json:"refreshToken"`
+}
+
+// LoginResponse is the response body for the user login endpoint. Users with
+// two-factor authentication get a ChallengeToken to answer at the second step
+// instead of the tokens.
+type LoginResponse struct {
+	*WithTokenResponse
+	ChallengeToken string `json:"challengeToken,omitempty"`
+}
+
+// TwoFactorLoginRequest is the request body for the second login step. It
+// takes a TOTP code, or a recovery code when the authenticator is lost.
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challengeToken" validate:"required"`
+	Code           string `json:"code"           validate:"required_without=RecoveryCode"`
+	RecoveryCode   string `json:"recoveryCode"`
+	// IP is the address of the client, set by the handler.
+	IP string `json:"-"`
+}
+
+// TwoFactorEnrollResponse is the response body for the two-factor enroll
+// endpoint; URI is the otpauth:// link authenticator apps scan.
+type TwoFactorEnrollResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// TwoFactorCodeRequest is the request body for the endpoints taking a TOTP code.
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+	// IP is the address of the client, set by the handler.
+	IP string `json:"-"`
+}
+
+// TwoFactorDisableRequest is the request body for the two-factor disable endpoint.
+type TwoFactorDisableRequest struct {
+	Password     string `json:"password"     validate:"required"`
+	Code         string `json:"code"         validate:"required_without=RecoveryCode"`
+	RecoveryCode string `json:"recoveryCode"`
+	// IP is the address of the client, set by the handler.
+	IP string `json:"-"`
+}
+
+// RecoveryCodesResponse lists new recovery codes; they are shown only once.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// RefreshRequest is the request body for the token refresh and logout endpoints.
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
+
+// ForgotPasswordRequest is the request body for the forgot password endpoint.
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ResetPasswordRequest is the request body for the password reset endpoint.
+type ResetPasswordRequest struct {
+	Token    string `json:"token"    validate:"required"`
+	Password string `json:"password" validate:"required,min=6,max=55"`
+}
+
+// VerifyEmailRequest is the request for the email verification endpoint.
+type VerifyEmailRequest struct {
+	Token string `query:"token" validate:"required"`
+}
+
+// OIDCLoginRequest is the request for the provider sign in endpoint.
+type OIDCLoginRequest struct {
+	Provider string `param:"provider" validate:"required"`
+}
+
+// OIDCCallbackRequest is the request the provider sends the user back with.
+type OIDCCallbackRequest struct {
+	Provider string `param:"provider" validate:"required"`
+	Code     string `query:"code"     validate:"required"`
+	State    string `query:"state"    validate:"required"`
+}
+
+// LoginAttemptResponse is the response body for a locked out account or IP.
+type LoginAttemptResponse struct {
+	ID           uint64                 `json:"id"`
+	Kind         types.LoginAttemptKind `json:"kind"`
+	Key          string                 `json:"key"`
+	Failures     int                    `json:"failures"`
+	LastFailedAt time.Time              `json:"lastFailedAt"`
+	LockedUntil  *time.Time             `json:"lockedUntil,omitempty"`
 }
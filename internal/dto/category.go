@@ -0,0 +1,29 @@
+package dto
+
+import "time"
+
+// CategoryCreateRequest is the request body for the category create endpoint.
+// Categories without a parent are top level.
+type CategoryCreateRequest struct {
+	Name     string  `json:"name"     validate:"required,min=2,max=50"`
+	ParentID *uint64 `json:"parentId"`
+}
+
+// CategoryUpdateRequest is the request body for the category update endpoint.
+type CategoryUpdateRequest struct {
+	ID       string  `param:"id"      validate:"required"`
+	Name     string  `json:"name"     validate:"required,min=2,max=50"`
+	ParentID *uint64 `json:"parentId"`
+}
+
+// CategoryResponse is the response body for the category.
+type CategoryResponse struct {
+	CreatedAt time.Time `json:"createdAt,omitempty"`
+	CreatedBy string    `json:"createdBy,omitempty"`
+	ID        uint64    `json:"id,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	ParentID  *uint64   `json:"parentId,omitempty"`
+	Slug      string    `json:"slug,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt,omitempty"`
+	UpdatedBy string    `json:"updatedBy,omitempty"`
+}
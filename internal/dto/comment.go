@@ -2,28 +2,61 @@ package dto
 
 import (
 	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
 )
 
 // CommentCreateRequest is the request body for the comment create endpoint.
+// ParentID makes the comment a reply to another comment on the same post.
 type CommentCreateRequest struct {
-	Text   string `json:"text"   validate:"required,min=2,max=100"`
-	PostID string `json:"post_id" validate:"required"`
+	Text     string  `json:"text"      validate:"required,min=2,max=100"`
+	PostID   string  `json:"post_id"   validate:"required"`
+	ParentID *uint64 `json:"parent_id"`
+}
+
+// CommentUpdateRequest is the request body for the comment update endpoint.
+type CommentUpdateRequest struct {
+	Precondition
+	ID   string `param:"id"  validate:"required"`
+	Text string `json:"text" validate:"required,min=2,max=100"`
 }
 
 // CommentResponse is the response body for the comment.
 type CommentResponse struct {
-	Author    string     `json:"author"`
-	CreatedAt time.Time  `json:"createdAt,omitempty"`
-	CreatedBy string     `json:"createdBy,omitempty"`
-	DeletedAt *time.Time `json:"deletedAt,omitempty"`
-	ID        uint64     `json:"id,omitempty"`
-	UpdatedAt time.Time  `json:"updatedAt,omitempty"`
-	UpdatedBy string     `json:"updatedBy,omitempty"`
-	Text      string     `json:"text,omitempty"`
-	PostID    uint64     `json:"post_id"`
-	UserID    uint64     `json:"user_id"`
+	Author      string             `json:"author"`
+	CreatedAt   time.Time          `json:"createdAt,omitempty"`
+	CreatedBy   string             `json:"createdBy,omitempty"`
+	DeletedAt   *time.Time         `json:"deletedAt,omitempty"`
+	DeletedBy   string             `json:"deletedBy,omitempty"`
+	ID          uint64             `json:"id,omitempty"`
+	Status      types.Status       `json:"status,omitempty"`
+	UpdatedAt   time.Time          `json:"updatedAt,omitempty"`
+	UpdatedBy   string             `json:"updatedBy,omitempty"`
+	Text        string             `json:"text,omitempty"`
+	PostID      uint64             `json:"post_id"`
+	UserID      uint64             `json:"user_id"`
+	ParentID    *uint64            `json:"parent_id,omitempty"`
+	Depth       int                `json:"depth"`
+	Edited      bool               `json:"edited"`
+	State       types.CommentState `json:"state,omitempty"`
+	ModeratedAt *time.Time         `json:"moderatedAt,omitempty"`
+	ModeratedBy string             `json:"moderatedBy,omitempty"`
+	Replies     []*CommentResponse `json:"replies,omitempty"`
 }
 
+// ByPostIDRequest is the request for the comments of a post. In tree mode
+// only top level comments are paginated and each carries its replies.
 type ByPostIDRequest struct {
-	PostID string `param:"pid" validate:"required"`
+	ReadsRequest
+	PostID string `param:"pid"  validate:"required"`
+	Tree   bool   `query:"tree"`
+}
+
+// CommentRevisionResponse is the response body for a previous version of a comment.
+type CommentRevisionResponse struct {
+	ID        uint64    `json:"id"`
+	CommentID uint64    `json:"comment_id"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"createdAt"`
+	CreatedBy string    `json:"createdBy,omitempty"`
 }
@@ -4,6 +4,23 @@ type RequestWithID struct {
 	ID string `param:"id" validate:"required"`
 }
 
+// Precondition carries the If-Match header of a write request. Handlers fill
+// it in; it isn't bound from the body.
+type Precondition struct {
+	IfMatch string `json:"-"`
+}
+
+// DeleteRequest is the request for the delete endpoints honoring If-Match.
+type DeleteRequest struct {
+	RequestWithID
+	Precondition
+}
+
 type ResponseWithID struct {
 	ID string `json:"id"`
 }
+
+// ReadsRequest is the query for the list endpoints.
+type ReadsRequest struct {
+	IncludeDeleted bool `query:"include_deleted"`
+}
@@ -0,0 +1,21 @@
+package dto
+
+import "github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+
+// ModerationQueueRequest is the request for the comment moderation queue.
+// State defaults to pending.
+type ModerationQueueRequest struct {
+	State string `query:"state" validate:"omitempty,oneof=pending approved rejected spam"`
+}
+
+// ModerationRequest is the request body for the bulk moderation endpoints.
+type ModerationRequest struct {
+	IDs []uint64 `json:"ids" validate:"required,min=1,max=100"`
+}
+
+// ModerationResponse is the response body for the bulk moderation endpoints.
+// IDs holds the comments that were moved to State.
+type ModerationResponse struct {
+	State types.CommentState `json:"state"`
+	IDs   []uint64           `json:"ids"`
+}
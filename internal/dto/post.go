@@ -2,29 +2,129 @@ package dto
 
 import (
 	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
 )
 
 // PostCreateRequest is the request body for the post create endpoint.
 type PostCreateRequest struct {
-	Title string `json:"title" validate:"required,min=3,max=21"`
-	Body  string `json:"body"  validate:"required,min=1"`
+	Title      string     `json:"title"     validate:"required,min=3,max=21"`
+	Body       string     `json:"body"      validate:"required,min=1"`
+	State      string     `json:"state"      validate:"omitempty,oneof=draft scheduled published"`
+	PublishAt  *time.Time `json:"publishAt"`
+	Tags       []string   `json:"tags"       validate:"omitempty,max=10,dive,required"`
+	Categories []string   `json:"categories" validate:"omitempty,max=5,dive,required"`
+}
+
+// PostReadsRequest is the query for the post list endpoints.
+// Category also matches the posts filed under its subcategories.
+type PostReadsRequest struct {
+	ReadsRequest
+	State    string `query:"state"    validate:"omitempty,oneof=draft scheduled published archived"`
+	Tag      string `query:"tag"`
+	Category string `query:"category"`
+}
+
+// PostPublishRequest is the request body for the post publish endpoint.
+// A future PublishAt schedules the post instead of publishing it right away.
+type PostPublishRequest struct {
+	ID        string     `param:"id"       validate:"required"`
+	PublishAt *time.Time `json:"publishAt"`
 }
 
 // PostUpdateRequest is the request body for the post update endpoint.
+// Slug is normalised before it's stored; the previous slug keeps resolving.
+// Tags and Categories replace the post's current ones when present.
 type PostUpdateRequest struct {
-	ID    string `param:"id"   validate:"required"`
-	Title string `json:"title" validate:"required,min=3,max=21"`
-	Body  string `json:"body"  validate:"omitempty,min=1"`
+	Precondition
+	ID         string   `param:"id"        validate:"required"`
+	Title      string   `json:"title"      validate:"required,min=3,max=21"`
+	Body       string   `json:"body"       validate:"omitempty,min=1"`
+	Slug       string   `json:"slug"       validate:"omitempty,max=200"`
+	Tags       []string `json:"tags"       validate:"omitempty,max=10,dive,required"`
+	Categories []string `json:"categories" validate:"omitempty,max=5,dive,required"`
+}
+
+// PostSlugRequest is the request for the post lookup by slug.
+type PostSlugRequest struct {
+	Slug string `param:"slug" validate:"required"`
+}
+
+// ByUserIDRequest is the request for the posts of a single author.
+type ByUserIDRequest struct {
+	PostReadsRequest
+	UserID string `param:"id" validate:"required"`
+}
+
+// AuthorResponse is the summary of the user who wrote a post.
+type AuthorResponse struct {
+	ID       uint64 `json:"id"`
+	Username string `json:"username"`
+}
+
+// PostTagResponse is the summary of a tag on a post.
+type PostTagResponse struct {
+	ID   uint64 `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+// PostCategoryResponse is the summary of a category a post is filed under.
+type PostCategoryResponse struct {
+	ID       uint64  `json:"id"`
+	ParentID *uint64 `json:"parentId,omitempty"`
+	Name     string  `json:"name"`
+	Slug     string  `json:"slug"`
 }
 
 // PostResponse is the response body for the post.
 type PostResponse struct {
-	Body      string     `json:"body,omitempty"`
-	CreatedAt time.Time  `json:"createdAt,omitempty"`
-	CreatedBy string     `json:"createdBy,omitempty"`
-	DeletedAt *time.Time `json:"deletedAt,omitempty"`
-	ID        uint64     `json:"id,omitempty"`
-	UpdatedAt time.Time  `json:"updatedAt,omitempty"`
-	UpdatedBy string     `json:"updatedBy,omitempty"`
-	Title     string     `json:"title,omitempty"`
+	Author     *AuthorResponse         `json:"author,omitempty"`
+	Body       string                  `json:"body,omitempty"`
+	CreatedAt  time.Time               `json:"createdAt,omitempty"`
+	CreatedBy  string                  `json:"createdBy,omitempty"`
+	DeletedAt  *time.Time              `json:"deletedAt,omitempty"`
+	DeletedBy  string                  `json:"deletedBy,omitempty"`
+	ID         uint64                  `json:"id,omitempty"`
+	PublishAt  *time.Time              `json:"publishAt,omitempty"`
+	State      types.PostState         `json:"state,omitempty"`
+	Status     types.Status            `json:"status,omitempty"`
+	UpdatedAt  time.Time               `json:"updatedAt,omitempty"`
+	UpdatedBy  string                  `json:"updatedBy,omitempty"`
+	Title      string                  `json:"title,omitempty"`
+	Slug       string                  `json:"slug,omitempty"`
+	Tags       []*PostTagResponse      `json:"tags,omitempty"`
+	Categories []*PostCategoryResponse `json:"categories,omitempty"`
+}
+
+// PostRevisionResponse is the response body for a previous version of a post.
+type PostRevisionResponse struct {
+	Number    int       `json:"number"`
+	PostID    uint64    `json:"postId"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"createdAt"`
+	CreatedBy string    `json:"createdBy,omitempty"`
+}
+
+// PostRevisionRequest is the request for a single revision of a post.
+type PostRevisionRequest struct {
+	ID  string `param:"id"  validate:"required"`
+	Rev int    `param:"rev" validate:"required,min=1"`
+}
+
+// PostDiffRequest is the query for the diff between two revisions of a post.
+// An empty To compares against the current version.
+type PostDiffRequest struct {
+	ID   string `param:"id"   validate:"required"`
+	From int    `query:"from" validate:"required,min=1"`
+	To   int    `query:"to"   validate:"omitempty,min=1"`
+}
+
+// PostDiffResponse is the response body for the post diff endpoint; To is
+// zero when the diff ends at the current version.
+type PostDiffResponse struct {
+	From int    `json:"from"`
+	To   int    `json:"to"`
+	Diff string `json:"diff"`
 }
@@ -0,0 +1,36 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+)
+
+// RoleRequest is the path of the role endpoints.
+type RoleRequest struct {
+	Name types.Role `param:"name" validate:"required,max=32"`
+}
+
+// RoleCreateRequest is the request body for the role create endpoint. Name
+// must be a slug.
+type RoleCreateRequest struct {
+	Name        types.Role         `json:"name"        validate:"required,max=32"`
+	Permissions []types.Permission `json:"permissions" validate:"required,dive,oneof=post:create post:update post:update-any post:read-unpublished post:delete comment:create comment:moderate comment:delete tag:manage category:manage user:read user:manage role:manage"`
+}
+
+// RoleUpdateRequest is the request body for the role update endpoint; the
+// permissions replace the current ones.
+type RoleUpdateRequest struct {
+	Name        types.Role         `param:"name"       validate:"required,max=32"`
+	Permissions []types.Permission `json:"permissions" validate:"required,dive,oneof=post:create post:update post:update-any post:read-unpublished post:delete comment:create comment:moderate comment:delete tag:manage category:manage user:read user:manage role:manage"`
+}
+
+// RoleResponse is the response body for a role.
+type RoleResponse struct {
+	Name        types.Role         `json:"name"`
+	Permissions []types.Permission `json:"permissions"`
+	CreatedAt   time.Time          `json:"createdAt"`
+	UpdatedAt   time.Time          `json:"updatedAt"`
+	CreatedBy   string             `json:"createdBy"`
+	UpdatedBy   string             `json:"updatedBy"`
+}
@@ -0,0 +1,26 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+)
+
+// SearchRequest is the query for the search endpoint.
+// Type is a comma separated list of post and comment, both when empty.
+type SearchRequest struct {
+	Q    string `query:"q"    validate:"required,min=2,max=100"`
+	Type string `query:"type" validate:"omitempty,max=20"`
+}
+
+// SearchResultResponse is a single search hit. Snippet is the matching part
+// of the content with the query terms wrapped in <mark> tags.
+type SearchResultResponse struct {
+	CreatedAt time.Time        `json:"createdAt,omitempty"`
+	ID        uint64           `json:"id"`
+	PostID    uint64           `json:"postId"`
+	Rank      float64          `json:"rank"`
+	Snippet   string           `json:"snippet"`
+	Title     string           `json:"title"`
+	Type      types.SearchType `json:"type"`
+}
@@ -0,0 +1,25 @@
+package dto
+
+import "time"
+
+// TagCreateRequest is the request body for the tag create endpoint.
+type TagCreateRequest struct {
+	Name string `json:"name" validate:"required,min=2,max=50"`
+}
+
+// TagUpdateRequest is the request body for the tag update endpoint.
+type TagUpdateRequest struct {
+	ID   string `param:"id"  validate:"required"`
+	Name string `json:"name" validate:"required,min=2,max=50"`
+}
+
+// TagResponse is the response body for the tag.
+type TagResponse struct {
+	CreatedAt time.Time `json:"createdAt,omitempty"`
+	CreatedBy string    `json:"createdBy,omitempty"`
+	ID        uint64    `json:"id,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	Slug      string    `json:"slug,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt,omitempty"`
+	UpdatedBy string    `json:"updatedBy,omitempty"`
+}
@@ -15,21 +15,100 @@ type UserCreateRequest struct {
 
 // UserUpdateRequest is the request body for the user update endpoint.
 type UserUpdateRequest struct {
+	Precondition
 	ID       string `param:"id"         validate:"required"`
 	Username string `json:"username"    validate:"required,min=3,max=21"`
 }
 
+// MeUpdateRequest is the request body for the update me endpoint.
+type MeUpdateRequest struct {
+	Precondition
+	Username string `json:"username" validate:"required,min=3,max=21"`
+}
+
+// UserRoleRequest is the request body for the user role endpoint.
+type UserRoleRequest struct {
+	Precondition
+	ID   string     `param:"id"   validate:"required"`
+	Role types.Role `json:"role"  validate:"required,max=32"`
+}
+
+// UserStatusRequest is the request body for the user status endpoint. Passive
+// suspends the user, until Until when it is set.
+type UserStatusRequest struct {
+	Precondition
+	ID     string       `param:"id"     validate:"required"`
+	Status types.Status `json:"status"  validate:"required,oneof=active passive"`
+	Reason string       `json:"reason"  validate:"required_if=Status passive,max=255"`
+	Until  *time.Time   `json:"until"`
+}
+
+// ChangePasswordRequest is the request body for the change password endpoint.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" validate:"required"`
+	NewPassword     string `json:"newPassword"     validate:"required,min=6,max=55"`
+	// IP is the address of the client, set by the handler.
+	IP string `json:"-"`
+}
+
+// ChangeEmailRequest is the request body for the change email endpoint.
+type ChangeEmailRequest struct {
+	Email    string `json:"email"    validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+	// IP is the address of the client, set by the handler.
+	IP string `json:"-"`
+}
+
 // UserResponse is the response body for the user.
 type UserResponse struct {
-	CreatedAt      time.Time    `json:"createdAt,omitempty"`
-	CreatedBy      string       `json:"createdBy,omitempty"`
-	DeletedAt      *time.Time   `json:"deletedAt,omitempty"`
-	Email          string       `json:"email,omitempty"`
-	ID             uint64       `json:"id,omitempty"`
-	Role           types.Role   `json:"role,omitempty"`
-	Status         types.Status `json:"status,omitempty"`
-	TermsOfService bool         `json:"termsOfService,omitempty"`
-	UpdatedAt      time.Time    `json:"updatedAt,omitempty"`
-	UpdatedBy      string       `json:"updatedBy,omitempty"`
-	Username       string       `json:"username,omitempty"`
+	CreatedAt       time.Time    `json:"createdAt,omitempty"`
+	CreatedBy       string       `json:"createdBy,omitempty"`
+	DeletedAt       *time.Time   `json:"deletedAt,omitempty"`
+	DeletedBy       string       `json:"deletedBy,omitempty"`
+	Email           string       `json:"email,omitempty"`
+	EmailVerifiedAt *time.Time   `json:"emailVerifiedAt,omitempty"`
+	ID              uint64       `json:"id,omitempty"`
+	Role            types.Role   `json:"role,omitempty"`
+	Status          types.Status `json:"status,omitempty"`
+	SuspendedReason string       `json:"suspendedReason,omitempty"`
+	SuspendedUntil  *time.Time   `json:"suspendedUntil,omitempty"`
+	TwoFactor       bool         `json:"twoFactor,omitempty"`
+	TermsOfService  bool         `json:"termsOfService,omitempty"`
+	UpdatedAt       time.Time    `json:"updatedAt,omitempty"`
+	UpdatedBy       string       `json:"updatedBy,omitempty"`
+	Username        string       `json:"username,omitempty"`
+}
+
+// MeResponse is the response body for the me endpoints: the caller and how
+// much they have written.
+type MeResponse struct {
+	UserResponse
+	PostCount    int `json:"postCount"`
+	CommentCount int `json:"commentCount"`
+}
+
+// APIKeyCreateRequest is the request body for the api key create endpoint.
+// A scope is a resource and an access, like posts:write.
+type APIKeyCreateRequest struct {
+	Name      string    `json:"name"      validate:"required,max=64"`
+	Scopes    []string  `json:"scopes"    validate:"required,min=1,dive,oneof=posts:read posts:write comments:read comments:write tags:read tags:write categories:read categories:write moderation:read moderation:write"`
+	ExpiresAt time.Time `json:"expiresAt" validate:"required"`
+}
+
+// APIKeyResponse is the response body for an api key.
+type APIKeyResponse struct {
+	ID         uint64     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+}
+
+// APIKeyCreateResponse is the response body for the api key create endpoint;
+// Key is only ever shown here.
+type APIKeyCreateResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
 }
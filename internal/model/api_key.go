@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
+)
+
+// APIKey is a personal key a user hands to scripts in place of their
+// password. Only the hash of the key is kept.
+type APIKey struct {
+	ID         uint64     `json:"id"`
+	UserID     uint64     `json:"userId"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	RevokedAt  *time.Time `json:"revokedAt"`
+}
+
+// Usable reports whether the key is neither revoked nor expired at now.
+func (k *APIKey) Usable(now time.Time) bool {
+	return k.RevokedAt == nil && now.Before(k.ExpiresAt)
+}
+
+func (k *APIKey) ToDTO() *dto.APIKeyResponse {
+	return &dto.APIKeyResponse{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     k.Scopes,
+		CreatedAt:  k.CreatedAt,
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+	}
+}
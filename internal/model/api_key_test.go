@@ -0,0 +1,18 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+)
+
+func TestAPIKeyUsable(t *testing.T) {
+	now := time.Now()
+
+	assert.True(t, (&model.APIKey{ExpiresAt: now.Add(time.Hour)}).Usable(now))
+	assert.False(t, (&model.APIKey{ExpiresAt: now}).Usable(now))
+	assert.False(t, (&model.APIKey{ExpiresAt: now.Add(time.Hour), RevokedAt: &now}).Usable(now))
+}
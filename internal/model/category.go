@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
+)
+
+type Category struct {
+	ID        uint64    `json:"id"`
+	ParentID  *uint64   `json:"parent_id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	CreatedBy string    `json:"created_by"`
+	UpdatedBy string    `json:"updated_by"`
+}
+
+func (c Category) ToDTO() *dto.CategoryResponse {
+	return &dto.CategoryResponse{
+		CreatedAt: c.CreatedAt,
+		CreatedBy: c.CreatedBy,
+		ID:        c.ID,
+		Name:      c.Name,
+		ParentID:  c.ParentID,
+		Slug:      c.Slug,
+		UpdatedAt: c.UpdatedAt,
+		UpdatedBy: c.UpdatedBy,
+	}
+}
@@ -1,26 +1,72 @@
 package model
 
-import "github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
+import (
+	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+)
 
 type Comment struct {
 	BaseModel
-	Author string `json:"author"`
-	PostID uint64 `json:"post_id"`
-	UserID uint64 `json:"user_id"`
-	Text   string `json:"text"`
+	Author   string             `json:"author"`
+	PostID   uint64             `json:"post_id"`
+	UserID   uint64             `json:"user_id"`
+	Text     string             `json:"text"`
+	ParentID *uint64            `json:"parent_id"`
+	Depth    int                `json:"depth"`
+	Edited   bool               `json:"edited"`
+	State    types.CommentState `json:"state"`
+	// ModeratedAt and ModeratedBy record the last moderation decision.
+	ModeratedAt *time.Time `json:"moderated_at"`
+	ModeratedBy string     `json:"moderated_by"`
+	Replies     []*Comment `json:"replies"`
 }
 
 func (p Comment) ToDTO() *dto.CommentResponse {
-	return &dto.CommentResponse{
-		Author:    p.Author,
-		CreatedAt: p.CreatedAt,
-		CreatedBy: p.CreatedBy,
-		DeletedAt: p.DeletedAt,
-		ID:        p.ID,
-		UpdatedAt: p.UpdatedAt,
-		UpdatedBy: p.UpdatedBy,
-		Text:      p.Text,
-		PostID:    p.PostID,
-		UserID:    p.UserID,
+	r := &dto.CommentResponse{
+		Author:      p.Author,
+		CreatedAt:   p.CreatedAt,
+		CreatedBy:   p.CreatedBy,
+		DeletedAt:   p.DeletedAt,
+		DeletedBy:   p.DeletedBy,
+		ID:          p.ID,
+		Status:      p.Status,
+		UpdatedAt:   p.UpdatedAt,
+		UpdatedBy:   p.UpdatedBy,
+		Text:        p.Text,
+		PostID:      p.PostID,
+		UserID:      p.UserID,
+		ParentID:    p.ParentID,
+		Depth:       p.Depth,
+		Edited:      p.Edited,
+		State:       p.State,
+		ModeratedAt: p.ModeratedAt,
+		ModeratedBy: p.ModeratedBy,
+	}
+
+	for _, c := range p.Replies {
+		r.Replies = append(r.Replies, c.ToDTO())
+	}
+
+	return r
+}
+
+// CommentRevision is the text a comment had before one of its edits.
+type CommentRevision struct {
+	ID        uint64    `json:"id"`
+	CommentID uint64    `json:"comment_id"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+	CreatedBy string    `json:"created_by"`
+}
+
+func (r CommentRevision) ToDTO() *dto.CommentRevisionResponse {
+	return &dto.CommentRevisionResponse{
+		ID:        r.ID,
+		CommentID: r.CommentID,
+		Text:      r.Text,
+		CreatedAt: r.CreatedAt,
+		CreatedBy: r.CreatedBy,
 	}
 }
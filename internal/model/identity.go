@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// Identity links a user to the subject an OpenID Connect provider knows them by.
+type Identity struct {
+	ID        uint64    `json:"id"`
+	UserID    uint64    `json:"user_id"`
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OIDCLogin is a sign in waiting for the provider to send the user back.
+// Only the hash of the state handed to the provider is kept.
+type OIDCLogin struct {
+	ID           uint64     `json:"id"`
+	StateHash    string     `json:"-"`
+	Provider     string     `json:"provider"`
+	Nonce        string     `json:"-"`
+	CodeVerifier string     `json:"-"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	UsedAt       *time.Time `json:"used_at"`
+}
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+)
+
+// LoginAttempt counts the recent failed logins of an account or IP.
+type LoginAttempt struct {
+	ID           uint64                 `json:"id"`
+	Kind         types.LoginAttemptKind `json:"kind"`
+	Key          string                 `json:"key"`
+	Failures     int                    `json:"failures"`
+	LastFailedAt time.Time              `json:"last_failed_at"`
+	LockedUntil  *time.Time             `json:"locked_until"`
+}
+
+func (a LoginAttempt) ToDTO() *dto.LoginAttemptResponse {
+	return &dto.LoginAttemptResponse{
+		ID:           a.ID,
+		Kind:         a.Kind,
+		Key:          a.Key,
+		Failures:     a.Failures,
+		LastFailedAt: a.LastFailedAt,
+		LockedUntil:  a.LockedUntil,
+	}
+}
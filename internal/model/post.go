@@ -1,22 +1,127 @@
 package model
 
-import "github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
+import (
+	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
+)
+
+// postTransitions lists the workflow states a post may move to from each state.
+var postTransitions = map[types.PostState][]types.PostState{
+	types.Draft:     {types.Draft, types.Scheduled, types.Published},
+	types.Scheduled: {types.Draft, types.Scheduled, types.Published},
+	types.Published: {types.Draft, types.Archived},
+	types.Archived:  {types.Draft, types.Published},
+}
 
 type Post struct {
 	BaseModel
-	Title string `json:"title"`
-	Body  string `json:"body"`
+	Title      string          `json:"title"`
+	Slug       string          `json:"slug"`
+	Body       string          `json:"body"`
+	UserID     uint64          `json:"user_id"`
+	Author     string          `json:"author"`
+	State      types.PostState `json:"state"`
+	PublishAt  *time.Time      `json:"publish_at"`
+	Tags       []Tag           `json:"tags"`
+	Categories []Category      `json:"categories"`
+}
+
+// SetState moves the post through the draft, scheduled, published and archived
+// workflow. publishAt is required for, and only used by, types.Scheduled.
+func (p *Post) SetState(state types.PostState, publishAt *time.Time, now time.Time) error {
+	from := p.State
+	if from == "" {
+		from = types.Draft
+	}
+
+	allowed := false
+
+	for _, s := range postTransitions[from] {
+		if s == state {
+			allowed = true
+		}
+	}
+
+	if !allowed {
+		return errorutils.New(errorutils.ErrPostState, nil)
+	}
+
+	switch state {
+	case types.Scheduled:
+		if publishAt == nil || !publishAt.After(now) {
+			return errorutils.New(errorutils.ErrPostPublish, nil)
+		}
+
+		p.PublishAt = publishAt
+	case types.Published:
+		p.PublishAt = &now
+	case types.Draft:
+		p.PublishAt = nil
+	case types.Archived:
+	}
+
+	p.State = state
+
+	return nil
 }
 
 func (p Post) ToDTO() *dto.PostResponse {
-	return &dto.PostResponse{
+	r := &dto.PostResponse{
 		Body:      p.Body,
 		CreatedAt: p.CreatedAt,
 		CreatedBy: p.CreatedBy,
 		DeletedAt: p.DeletedAt,
+		DeletedBy: p.DeletedBy,
 		ID:        p.ID,
+		PublishAt: p.PublishAt,
+		State:     p.State,
+		Status:    p.Status,
 		UpdatedAt: p.UpdatedAt,
 		UpdatedBy: p.UpdatedBy,
 		Title:     p.Title,
+		Slug:      p.Slug,
+	}
+
+	for _, t := range p.Tags {
+		r.Tags = append(r.Tags, &dto.PostTagResponse{ID: t.ID, Name: t.Name, Slug: t.Slug})
+	}
+
+	for _, c := range p.Categories {
+		r.Categories = append(r.Categories, &dto.PostCategoryResponse{ID: c.ID, ParentID: c.ParentID, Name: c.Name, Slug: c.Slug})
+	}
+
+	if p.UserID != 0 {
+		r.Author = &dto.AuthorResponse{
+			ID:       p.UserID,
+			Username: p.Author,
+		}
+	}
+
+	return r
+}
+
+// PostRevision is the title and body a post had before one of its edits.
+// Number counts the revisions of each post from 1.
+type PostRevision struct {
+	ID        uint64    `json:"id"`
+	PostID    uint64    `json:"post_id"`
+	Number    int       `json:"number"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+	CreatedBy string    `json:"created_by"`
+}
+
+func (r PostRevision) ToDTO() *dto.PostRevisionResponse {
+	return &dto.PostRevisionResponse{
+		Number:    r.Number,
+		PostID:    r.PostID,
+		Title:     r.Title,
+		Body:      r.Body,
+		CreatedAt: r.CreatedAt,
+		CreatedBy: r.CreatedBy,
 	}
 }
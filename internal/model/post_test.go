@@ -0,0 +1,62 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+)
+
+func TestPostSetState(t *testing.T) {
+	now := time.Now()
+	future := now.Add(time.Hour)
+	past := now.Add(-time.Hour)
+
+	cases := map[string]struct {
+		from      types.PostState
+		to        types.PostState
+		publishAt *time.Time
+		wantErr   bool
+	}{
+		"new post as draft":            {from: "", to: types.Draft},
+		"new post published":           {from: "", to: types.Published},
+		"draft scheduled in future":    {from: types.Draft, to: types.Scheduled, publishAt: &future},
+		"draft scheduled in past":      {from: types.Draft, to: types.Scheduled, publishAt: &past, wantErr: true},
+		"draft scheduled without date": {from: types.Draft, to: types.Scheduled, wantErr: true},
+		"draft archived":               {from: types.Draft, to: types.Archived, wantErr: true},
+		"scheduled published":          {from: types.Scheduled, to: types.Published},
+		"published unpublished":        {from: types.Published, to: types.Draft},
+		"published archived":           {from: types.Published, to: types.Archived},
+		"published scheduled":          {from: types.Published, to: types.Scheduled, publishAt: &future, wantErr: true},
+		"archived republished":         {from: types.Archived, to: types.Published},
+	}
+
+	for desc, tc := range cases {
+		t.Run(desc, func(t *testing.T) {
+			p := &model.Post{State: tc.from}
+
+			err := p.SetState(tc.to, tc.publishAt, now)
+			assert.Equal(t, tc.wantErr, err != nil)
+
+			if tc.wantErr {
+				assert.Equal(t, tc.from, p.State)
+
+				return
+			}
+
+			assert.Equal(t, tc.to, p.State)
+
+			switch tc.to {
+			case types.Published:
+				assert.Equal(t, &now, p.PublishAt)
+			case types.Scheduled:
+				assert.Equal(t, tc.publishAt, p.PublishAt)
+			case types.Draft:
+				assert.Nil(t, p.PublishAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+)
+
+// Role is a named set of permissions users are given.
+type Role struct {
+	Name        types.Role         `json:"name"`
+	Permissions []types.Permission `json:"permissions"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+	CreatedBy   string             `json:"created_by"`
+	UpdatedBy   string             `json:"updated_by"`
+}
+
+func (r Role) ToDTO() *dto.RoleResponse {
+	return &dto.RoleResponse{
+		Name:        r.Name,
+		Permissions: r.Permissions,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+		CreatedBy:   r.CreatedBy,
+		UpdatedBy:   r.UpdatedBy,
+	}
+}
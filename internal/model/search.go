@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+)
+
+// SearchResult is a post or comment matching a search query.
+type SearchResult struct {
+	Type      types.SearchType `json:"type"`
+	ID        uint64           `json:"id"`
+	PostID    uint64           `json:"post_id"`
+	Title     string           `json:"title"`
+	Snippet   string           `json:"snippet"`
+	Rank      float64          `json:"rank"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+func (r SearchResult) ToDTO() *dto.SearchResultResponse {
+	return &dto.SearchResultResponse{
+		CreatedAt: r.CreatedAt,
+		ID:        r.ID,
+		PostID:    r.PostID,
+		Rank:      r.Rank,
+		Snippet:   r.Snippet,
+		Title:     r.Title,
+		Type:      r.Type,
+	}
+}
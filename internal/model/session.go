@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// Session is a login of a user; its refresh tokens rotate within it, and
+// revoking it ends every token issued for it.
+type Session struct {
+	ID        string     `json:"id"`
+	UserID    uint64     `json:"user_id"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+}
+
+// RefreshToken is a single use refresh token of a session. Only the hash of
+// the token handed to the client is kept.
+type RefreshToken struct {
+	ID        uint64     `json:"id"`
+	SessionID string     `json:"session_id"`
+	UserID    uint64     `json:"user_id"`
+	TokenHash string     `json:"-"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
+)
+
+type Tag struct {
+	ID        uint64    `json:"id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	CreatedBy string    `json:"created_by"`
+	UpdatedBy string    `json:"updated_by"`
+}
+
+func (t Tag) ToDTO() *dto.TagResponse {
+	return &dto.TagResponse{
+		CreatedAt: t.CreatedAt,
+		CreatedBy: t.CreatedBy,
+		ID:        t.ID,
+		Name:      t.Name,
+		Slug:      t.Slug,
+		UpdatedAt: t.UpdatedAt,
+		UpdatedBy: t.UpdatedBy,
+	}
+}
@@ -1,6 +1,8 @@
 package model
 
 import (
+	"time"
+
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
 )
@@ -11,19 +13,49 @@ type User struct {
 	Role              types.Role `json:"role"`
 	Username          string     `json:"username"`
 	EncryptedPassword string     `json:"-"`
+	// EmailVerifiedAt is when the user proved they own Email; nil until then.
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	// SuspendedReason tells a passive user why they were suspended.
+	SuspendedReason string `json:"suspended_reason"`
+	// SuspendedUntil lifts the suspension when it passes; nil suspends for good.
+	SuspendedUntil *time.Time `json:"suspended_until"`
+	// TOTPSecret is the two-factor secret, enabled once TOTPEnabledAt is set.
+	TOTPSecret    string     `json:"-"`
+	TOTPEnabledAt *time.Time `json:"totp_enabled_at"`
+}
+
+// TwoFactorEnabled reports whether logging in takes a TOTP code.
+func (u User) TwoFactorEnabled() bool {
+	return u.TOTPEnabledAt != nil
+}
+
+// Suspended reports whether the user is passive at now.
+func (u User) Suspended(now time.Time) bool {
+	return u.Status == types.Passive && (u.SuspendedUntil == nil || now.Before(*u.SuspendedUntil))
 }
 
 func (u User) ToDTO() *dto.UserResponse {
 	return &dto.UserResponse{
-		CreatedAt: u.CreatedAt,
-		CreatedBy: u.CreatedBy,
-		DeletedAt: u.DeletedAt,
-		Email:     u.Email,
-		ID:        u.ID,
-		Role:      u.Role,
-		Status:    u.Status,
-		UpdatedAt: u.UpdatedAt,
-		UpdatedBy: u.UpdatedBy,
-		Username:  u.Username,
+		CreatedAt:       u.CreatedAt,
+		CreatedBy:       u.CreatedBy,
+		DeletedAt:       u.DeletedAt,
+		DeletedBy:       u.DeletedBy,
+		Email:           u.Email,
+		EmailVerifiedAt: u.EmailVerifiedAt,
+		ID:              u.ID,
+		Role:            u.Role,
+		Status:          u.Status,
+		SuspendedReason: u.SuspendedReason,
+		SuspendedUntil:  u.SuspendedUntil,
+		TwoFactor:       u.TwoFactorEnabled(),
+		UpdatedAt:       u.UpdatedAt,
+		UpdatedBy:       u.UpdatedBy,
+		Username:        u.Username,
 	}
 }
+
+// UserStats counts what a user has written, leaving out deleted items.
+type UserStats struct {
+	Posts    int `json:"posts"`
+	Comments int `json:"comments"`
+}
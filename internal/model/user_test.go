@@ -0,0 +1,36 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+)
+
+func TestUserSuspended(t *testing.T) {
+	now := time.Now()
+	future := now.Add(time.Hour)
+	past := now.Add(-time.Hour)
+
+	cases := map[string]struct {
+		status types.Status
+		until  *time.Time
+		want   bool
+	}{
+		"active":                {status: types.Active},
+		"suspended for good":    {status: types.Passive, want: true},
+		"suspended until later": {status: types.Passive, until: &future, want: true},
+		"suspension lapsed":     {status: types.Passive, until: &past},
+	}
+
+	for desc, tc := range cases {
+		t.Run(desc, func(t *testing.T) {
+			u := model.User{BaseModel: model.BaseModel{Status: tc.status}, SuspendedUntil: tc.until}
+
+			assert.Equal(t, tc.want, u.Suspended(now))
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+)
+
+// UserToken is a single use token mailed to a user. Only the hash of the
+// token in the mail is kept.
+type UserToken struct {
+	ID        uint64             `json:"id"`
+	UserID    uint64             `json:"user_id"`
+	Purpose   types.TokenPurpose `json:"purpose"`
+	TokenHash string             `json:"-"`
+	CreatedAt time.Time          `json:"created_at"`
+	ExpiresAt time.Time          `json:"expires_at"`
+	UsedAt    *time.Time         `json:"used_at"`
+}
@@ -2,35 +2,159 @@ package rbac
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	"github.com/labstack/echo/v4"
 
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/appcontext"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/repository"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
 )
 
 type RBAC interface {
 	HasRole(types.Role) func(next echo.HandlerFunc) echo.HandlerFunc
+	RequirePermission(types.Permission) func(next echo.HandlerFunc) echo.HandlerFunc
+	SignedIn() func(next echo.HandlerFunc) echo.HandlerFunc
+	Verified() func(next echo.HandlerFunc) echo.HandlerFunc
+	Can(context.Context, types.Permission) bool
+	CheckOwnerOrPermission(context.Context, uint64, types.Permission) (*dto.Claims, error)
+	// CanGrant returns why the user in ctx may not give role to others or take
+	// it from them, or nil.
+	CanGrant(ctx context.Context, role types.Role) error
+	// CanGrantPermissions returns why the user in ctx may not give perms to a
+	// role, or nil.
+	CanGrantPermissions(ctx context.Context, perms []types.Permission) error
 	CheckHasRole(userRole types.Role, requiredRole types.Role) bool
 	IsAdminAuthorized(ctx context.Context) bool
 	IsModAuthorized(context.Context) bool
 	IsMe(context.Context, uint64) bool
 	CheckRoleAndUser(context.Context, uint64, types.Role) (*dto.Claims, error)
+	// Reload forgets the cached roles, so changes to them apply at once.
+	Reload()
 }
 
-var roleScores = map[types.Role]uint8{
-	types.Admin:      15,
-	types.Mod:        14,
-	types.Registered: 13,
+// roleTTL is how long the permissions of a role are cached; other instances
+// see a changed role after at most this long.
+const roleTTL = time.Minute
+
+// DefaultRoles are the permissions of the built-in roles, used when no role
+// repository is given. The roles migration seeds the same sets.
+var DefaultRoles = map[types.Role][]types.Permission{
+	types.Admin: types.Permissions,
+	types.Mod: {
+		types.PostCreate, types.PostUpdate, types.PostReadUnpublished,
+		types.CommentCreate, types.CommentModerate,
+		types.TagManage, types.CategoryManage,
+		types.UserRead,
+	},
+	types.Registered: {types.CommentCreate},
+}
+
+// memberPermissions never take two-factor authentication; every other
+// permission is a staff one.
+var memberPermissions = map[types.Permission]bool{
+	types.CommentCreate: true,
+}
+
+type permissionSet map[types.Permission]bool
+
+// covers reports whether s has every permission of other.
+func (s permissionSet) covers(other permissionSet) bool {
+	for p := range other {
+		if !s[p] {
+			return false
+		}
+	}
+
+	return true
 }
 
-func New() RBAC {
-	return &rbac{}
+// staff reports whether s has a permission that takes two-factor authentication.
+func (s permissionSet) staff() bool {
+	for p := range s {
+		if !memberPermissions[p] {
+			return true
+		}
+	}
+
+	return false
+}
+
+type cachedRole struct {
+	permissions permissionSet
+	loadedAt    time.Time
+}
+
+type OptsFunc func(*rbac)
+
+// WithStaffTwoFactor keeps users who did not turn on two-factor
+// authentication from using staff permissions when required is true.
+func WithStaffTwoFactor(required bool) OptsFunc {
+	return func(r *rbac) {
+		r.staffTwoFactor = required
+	}
+}
+
+// WithRoles reads the permissions of roles from roles instead of DefaultRoles.
+func WithRoles(roles repository.Role) OptsFunc {
+	return func(r *rbac) {
+		r.roles = roles
+	}
+}
+
+func New(opts ...OptsFunc) RBAC {
+	r := &rbac{cache: map[types.Role]cachedRole{}}
+	for _, fn := range opts {
+		fn(r)
+	}
+
+	return r
+}
+
+type rbac struct {
+	staffTwoFactor bool
+	roles          repository.Role
+
+	mu    sync.Mutex
+	cache map[types.Role]cachedRole
+}
+
+// permissions returns what role may do. Unknown roles may do nothing.
+func (r *rbac) permissions(role types.Role) (permissionSet, error) {
+	if r.roles == nil {
+		return newPermissionSet(DefaultRoles[role]), nil
+	}
+
+	r.mu.Lock()
+	c, ok := r.cache[role]
+	r.mu.Unlock()
+
+	if ok && time.Since(c.loadedAt) < roleTTL {
+		return c.permissions, nil
+	}
+
+	m, err := r.roles.Read(role)
+	if err != nil {
+		return nil, err
+	}
+
+	c = cachedRole{permissions: newPermissionSet(m.Permissions), loadedAt: time.Now()}
+
+	r.mu.Lock()
+	r.cache[role] = c
+	r.mu.Unlock()
+
+	return c.permissions, nil
 }
 
-type rbac struct{}
+func (r *rbac) Reload() {
+	r.mu.Lock()
+	r.cache = map[types.Role]cachedRole{}
+	r.mu.Unlock()
+}
 
 func (r *rbac) IsMe(ctx context.Context, userID uint64) bool {
 	claims, err := appcontext.MtsBlogUser(ctx)
@@ -41,13 +165,91 @@ func (r *rbac) IsMe(ctx context.Context, userID uint64) bool {
 	return userID == claims.UID
 }
 
+// Can reports whether the user in ctx has perm.
+func (r *rbac) Can(ctx context.Context, perm types.Permission) bool {
+	claims, err := appcontext.MtsBlogUser(ctx)
+	if err != nil {
+		return false
+	}
+
+	return r.can(claims, perm) == nil
+}
+
+// can returns why claims may not use perm, or nil.
+func (r *rbac) can(claims *dto.Claims, perm types.Permission) error {
+	ps, err := r.permissions(claims.Role)
+	if err != nil || !ps[perm] {
+		return errorutils.New(errorutils.ErrUnauthorized, err)
+	}
+
+	if !memberPermissions[perm] && !r.twoFactorMet(claims) {
+		return errorutils.New(errorutils.ErrTwoFactorRequired, nil)
+	}
+
+	return nil
+}
+
+// CanGrant lets users hand out only roles whose every permission theirs grants
+// too, so nobody can give themselves or others more than they have.
+func (r *rbac) CanGrant(ctx context.Context, role types.Role) error {
+	granted, err := r.permissions(role)
+	if err != nil {
+		return err
+	}
+
+	return r.canGrant(ctx, granted)
+}
+
+// CanGrantPermissions lets users put only permissions of their own on roles.
+func (r *rbac) CanGrantPermissions(ctx context.Context, perms []types.Permission) error {
+	return r.canGrant(ctx, newPermissionSet(perms))
+}
+
+// canGrant returns why the user in ctx may not hand out granted, or nil.
+func (r *rbac) canGrant(ctx context.Context, granted permissionSet) error {
+	claims, err := appcontext.MtsBlogUser(ctx)
+	if err != nil {
+		return errorutils.New(errorutils.ErrUnauthorized, nil)
+	}
+
+	have, err := r.permissions(claims.Role)
+	if err != nil {
+		return errorutils.New(errorutils.ErrUnauthorized, err)
+	}
+
+	if !have.covers(granted) {
+		return errorutils.New(errorutils.ErrUnauthorized, nil)
+	}
+
+	return nil
+}
+
+// hasRole returns why claims may not act as requiredRole, or nil. A user has
+// a role when theirs grants every permission of it.
+func (r *rbac) hasRole(claims *dto.Claims, requiredRole types.Role) error {
+	if !r.CheckHasRole(claims.Role, requiredRole) {
+		return errorutils.New(errorutils.ErrUnauthorized, nil)
+	}
+
+	required, err := r.permissions(requiredRole)
+	if err != nil {
+		return errorutils.New(errorutils.ErrUnauthorized, err)
+	}
+
+	if required.staff() && !r.twoFactorMet(claims) {
+		return errorutils.New(errorutils.ErrTwoFactorRequired, nil)
+	}
+
+	return nil
+}
+
 func (r *rbac) IsAdminAuthorized(ctx context.Context) bool {
 	claims, err := appcontext.MtsBlogUser(ctx)
 	if err != nil {
 		return false
 	}
 
-	return roleScores[claims.Role] >= roleScores[types.Admin]
+	return r.hasRole(claims, types.Admin) == nil
 }
 
 func (r *rbac) IsModAuthorized(ctx context.Context) bool {
@@ -56,7 +258,7 @@ func (r *rbac) IsModAuthorized(ctx context.Context) bool {
 		return false
 	}
 
-	return roleScores[claims.Role] >= roleScores[types.Mod]
+	return r.hasRole(claims, types.Mod) == nil
 }
 
 func (r *rbac) HasRole(routeRole types.Role) func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -67,7 +269,43 @@ func (r *rbac) HasRole(routeRole types.Role) func(next echo.HandlerFunc) echo.Ha
 				return errorutils.New(errorutils.ErrUnauthorized, nil)
 			}
 
-			if roleScores[userRole] < roleScores[routeRole] {
+			claims, err := appcontext.MtsBlogUser(c.Request().Context())
+			if err != nil {
+				claims = &dto.Claims{Role: userRole}
+			}
+
+			if err = r.hasRole(claims, routeRole); err != nil {
+				return err
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// RequirePermission lets through only users whose role grants perm.
+func (r *rbac) RequirePermission(perm types.Permission) func(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims, err := appcontext.MtsBlogUser(c.Request().Context())
+			if err != nil {
+				return errorutils.New(errorutils.ErrUnauthorized, nil)
+			}
+
+			if err = r.can(claims, perm); err != nil {
+				return err
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// SignedIn lets through every signed in user, whatever their role.
+func (r *rbac) SignedIn() func(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if _, err := appcontext.MtsBlogUser(c.Request().Context()); err != nil {
 				return errorutils.New(errorutils.ErrUnauthorized, nil)
 			}
 
@@ -76,8 +314,57 @@ func (r *rbac) HasRole(routeRole types.Role) func(next echo.HandlerFunc) echo.Ha
 	}
 }
 
+// Verified lets through only users who verified their email.
+func (r *rbac) Verified() func(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims, err := appcontext.MtsBlogUser(c.Request().Context())
+			if err != nil {
+				return errorutils.New(errorutils.ErrUnauthorized, nil)
+			}
+
+			if !claims.EmailVerified {
+				return errorutils.New(errorutils.ErrEmailNotVerified, nil)
+			}
+
+			return next(c)
+		}
+	}
+}
+
 func (r *rbac) CheckHasRole(userRole types.Role, requiredRole types.Role) bool {
-	return roleScores[userRole] >= roleScores[requiredRole]
+	if userRole == requiredRole {
+		return true
+	}
+
+	have, err := r.permissions(userRole)
+	if err != nil {
+		return false
+	}
+
+	required, err := r.permissions(requiredRole)
+	if err != nil || len(required) == 0 {
+		return false
+	}
+
+	return have.covers(required)
+}
+
+// CheckOwnerOrPermission lets the user in ctx act on what ownerID owns, and
+// on what others own when they have perm.
+func (r *rbac) CheckOwnerOrPermission(ctx context.Context, ownerID uint64, perm types.Permission) (*dto.Claims, error) {
+	claims, err := appcontext.MtsBlogUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if ownerID != claims.UID {
+		if err = r.can(claims, perm); err != nil {
+			return nil, err
+		}
+	}
+
+	return claims, nil
 }
 
 func (r *rbac) CheckRoleAndUser(ctx context.Context, userID uint64, requiredRole types.Role) (*dto.Claims, error) {
@@ -86,9 +373,26 @@ func (r *rbac) CheckRoleAndUser(ctx context.Context, userID uint64, requiredRole
 		return nil, err
 	}
 
-	if userID != claims.UID && !r.CheckHasRole(claims.Role, requiredRole) {
-		return nil, errorutils.New(errorutils.ErrUnauthorized, nil)
+	if userID != claims.UID {
+		if err = r.hasRole(claims, requiredRole); err != nil {
+			return nil, err
+		}
 	}
 
 	return claims, nil
 }
+
+// twoFactorMet reports whether claims may use staff permissions, which take
+// two-factor authentication when it is required.
+func (r *rbac) twoFactorMet(claims *dto.Claims) bool {
+	return !r.staffTwoFactor || claims.TwoFactor
+}
+
+func newPermissionSet(perms []types.Permission) permissionSet {
+	s := make(permissionSet, len(perms))
+	for _, p := range perms {
+		s[p] = true
+	}
+
+	return s
+}
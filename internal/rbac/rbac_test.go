@@ -2,12 +2,19 @@ package rbac_test
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/labstack/echo/v4"
+
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/appcontext"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/rbac"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
 )
 
 func TestIsMe(t *testing.T) {
@@ -84,3 +91,336 @@ func TestIsAuthorized(t *testing.T) {
 		})
 	}
 }
+
+func TestVerified(t *testing.T) {
+	r := rbac.New()
+
+	testCases := []struct {
+		name     string
+		claims   *dto.Claims
+		wantCode string
+	}{
+		{
+			name:   "Verified user passes",
+			claims: &dto.Claims{EmailVerified: true},
+		},
+		{
+			name:     "Unverified user is blocked",
+			claims:   &dto.Claims{},
+			wantCode: errorutils.ErrCodeEmailNotVerified,
+		},
+		{
+			name:     "Anonymous user is blocked",
+			wantCode: errorutils.ErrCodeUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			if tc.claims != nil {
+				req = req.WithContext(appcontext.WithMtsBlogUser(req.Context(), tc.claims))
+			}
+
+			c := echo.New().NewContext(req, httptest.NewRecorder())
+
+			err := r.Verified()(func(echo.Context) error { return nil })(c)
+
+			var apiErr *errorutils.APIError
+			if tc.wantCode == "" && err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			if tc.wantCode != "" && (!errors.As(err, &apiErr) || apiErr.Code != tc.wantCode) {
+				t.Errorf("expected %s, got %v", tc.wantCode, err)
+			}
+		})
+	}
+}
+
+func TestStaffTwoFactor(t *testing.T) {
+	r := rbac.New(rbac.WithStaffTwoFactor(true))
+
+	testCases := []struct {
+		name      string
+		routeRole types.Role
+		claims    *dto.Claims
+		wantCode  string
+	}{
+		{
+			name:      "Admin with two-factor passes",
+			routeRole: types.Admin,
+			claims:    &dto.Claims{Role: types.Admin, TwoFactor: true},
+		},
+		{
+			name:      "Admin without two-factor is blocked",
+			routeRole: types.Admin,
+			claims:    &dto.Claims{Role: types.Admin},
+			wantCode:  errorutils.ErrCodeTwoFactorRequired,
+		},
+		{
+			name:      "Mod without two-factor is blocked",
+			routeRole: types.Mod,
+			claims:    &dto.Claims{Role: types.Mod},
+			wantCode:  errorutils.ErrCodeTwoFactorRequired,
+		},
+		{
+			name:      "Admin without two-factor passes registered routes",
+			routeRole: types.Registered,
+			claims:    &dto.Claims{Role: types.Admin},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := appcontext.WithMtsBlogUser(context.Background(), tc.claims)
+			ctx = appcontext.WithMtsBlogRole(ctx, tc.claims.Role)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+			c := echo.New().NewContext(req, httptest.NewRecorder())
+
+			err := r.HasRole(tc.routeRole)(func(echo.Context) error { return nil })(c)
+
+			var apiErr *errorutils.APIError
+			if tc.wantCode == "" && err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			if tc.wantCode != "" && (!errors.As(err, &apiErr) || apiErr.Code != tc.wantCode) {
+				t.Errorf("expected %s, got %v", tc.wantCode, err)
+			}
+
+			if got := r.IsAdminAuthorized(ctx); got != (tc.claims.Role == types.Admin && tc.claims.TwoFactor) {
+				t.Errorf("IsAdminAuthorized = %v", got)
+			}
+		})
+	}
+
+	if !rbac.New().IsAdminAuthorized(appcontext.WithMtsBlogUser(context.Background(), &dto.Claims{Role: types.Admin})) {
+		t.Error("two-factor should not be required by default")
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	r := rbac.New(rbac.WithStaffTwoFactor(true))
+
+	testCases := []struct {
+		name     string
+		perm     types.Permission
+		claims   *dto.Claims
+		wantCode string
+	}{
+		{
+			name:   "Mod with two-factor moderates comments",
+			perm:   types.CommentModerate,
+			claims: &dto.Claims{Role: types.Mod, TwoFactor: true},
+		},
+		{
+			name:     "Mod cannot manage users",
+			perm:     types.UserManage,
+			claims:   &dto.Claims{Role: types.Mod, TwoFactor: true},
+			wantCode: errorutils.ErrCodeUnauthorized,
+		},
+		{
+			name:     "Mod without two-factor is blocked",
+			perm:     types.CommentModerate,
+			claims:   &dto.Claims{Role: types.Mod},
+			wantCode: errorutils.ErrCodeTwoFactorRequired,
+		},
+		{
+			name:   "Registered comments without two-factor",
+			perm:   types.CommentCreate,
+			claims: &dto.Claims{Role: types.Registered},
+		},
+		{
+			name:     "Unknown role can do nothing",
+			perm:     types.CommentCreate,
+			claims:   &dto.Claims{Role: "ghost"},
+			wantCode: errorutils.ErrCodeUnauthorized,
+		},
+		{
+			name:     "Anonymous user is blocked",
+			perm:     types.CommentCreate,
+			wantCode: errorutils.ErrCodeUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.claims != nil {
+				ctx = appcontext.WithMtsBlogUser(ctx, tc.claims)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+			c := echo.New().NewContext(req, httptest.NewRecorder())
+
+			err := r.RequirePermission(tc.perm)(func(echo.Context) error { return nil })(c)
+
+			var apiErr *errorutils.APIError
+			if tc.wantCode == "" && err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+
+			if tc.wantCode != "" && (!errors.As(err, &apiErr) || apiErr.Code != tc.wantCode) {
+				t.Errorf("expected %s, got %v", tc.wantCode, err)
+			}
+
+			if got := r.Can(ctx, tc.perm); got != (tc.wantCode == "") {
+				t.Errorf("Can = %v", got)
+			}
+		})
+	}
+}
+
+func TestCheckOwnerOrPermission(t *testing.T) {
+	r := rbac.New()
+
+	testCases := []struct {
+		name    string
+		ownerID uint64
+		claims  *dto.Claims
+		wantErr bool
+	}{
+		{
+			name:    "Owner passes without the permission",
+			ownerID: 1,
+			claims:  &dto.Claims{UID: 1, Role: types.Registered},
+		},
+		{
+			name:    "Others need the permission",
+			ownerID: 1,
+			claims:  &dto.Claims{UID: 2, Role: types.Registered},
+			wantErr: true,
+		},
+		{
+			name:    "Mod acts on what others own",
+			ownerID: 1,
+			claims:  &dto.Claims{UID: 2, Role: types.Mod},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := appcontext.WithMtsBlogUser(context.Background(), tc.claims)
+
+			_, err := r.CheckOwnerOrPermission(ctx, tc.ownerID, types.CommentModerate)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("expected error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+// roleRepository keeps roles in memory and counts reads.
+type roleRepository struct {
+	roles map[types.Role][]types.Permission
+	reads int
+}
+
+func (r *roleRepository) Create(*model.Role) error { return nil }
+
+func (r *roleRepository) Read(name types.Role) (*model.Role, error) {
+	r.reads++
+
+	perms, ok := r.roles[name]
+	if !ok {
+		return nil, errorutils.New(errorutils.ErrRoleNotFound, nil)
+	}
+
+	return &model.Role{Name: name, Permissions: perms}, nil
+}
+
+func (r *roleRepository) Reads() (*[]model.Role, error) { return &[]model.Role{}, nil }
+
+func (r *roleRepository) Update(*model.Role) error { return nil }
+
+func (r *roleRepository) Delete(types.Role) error { return nil }
+
+func TestWithRoles(t *testing.T) {
+	repo := &roleRepository{roles: map[types.Role][]types.Permission{
+		types.Admin:         types.Permissions,
+		types.Mod:           {types.PostCreate, types.CommentModerate},
+		types.Registered:    {types.CommentCreate},
+		"comment-moderator": {types.CommentModerate},
+	}}
+	r := rbac.New(rbac.WithRoles(repo))
+
+	ctx := appcontext.WithMtsBlogUser(context.Background(), &dto.Claims{Role: "comment-moderator"})
+
+	if !r.Can(ctx, types.CommentModerate) || r.Can(ctx, types.PostCreate) {
+		t.Error("custom role should have exactly its permissions")
+	}
+
+	if r.CheckHasRole("comment-moderator", types.Mod) || !r.CheckHasRole(types.Admin, types.Mod) {
+		t.Error("a role should have another when it covers its permissions")
+	}
+
+	if r.CheckHasRole("comment-moderator", "ghost") {
+		t.Error("nobody should have an unknown role")
+	}
+
+	reads := repo.reads
+	repo.roles["comment-moderator"] = []types.Permission{types.PostCreate}
+
+	if !r.Can(ctx, types.CommentModerate) || repo.reads != reads {
+		t.Error("permissions should be cached")
+	}
+
+	r.Reload()
+
+	if r.Can(ctx, types.CommentModerate) || !r.Can(ctx, types.PostCreate) {
+		t.Error("Reload should pick up the changed role")
+	}
+}
+
+func TestCanGrant(t *testing.T) {
+	repo := &roleRepository{roles: map[types.Role][]types.Permission{
+		types.Admin:      types.Permissions,
+		types.Registered: {types.CommentCreate},
+		"user-manager":   {types.UserManage},
+		"support":        {types.UserManage, types.CommentCreate},
+	}}
+	r := rbac.New(rbac.WithRoles(repo))
+
+	testCases := []struct {
+		name    string
+		role    types.Role
+		grant   types.Role
+		wantErr bool
+	}{
+		{name: "Admin grants any role", role: types.Admin, grant: "user-manager"},
+		{name: "A role grants itself", role: "user-manager", grant: "user-manager"},
+		{name: "A role grants the roles it covers", role: "support", grant: types.Registered},
+		{name: "A role does not grant more than it has", role: "user-manager", grant: types.Admin, wantErr: true},
+		{name: "A role does not grant what it lacks", role: "user-manager", grant: types.Registered, wantErr: true},
+		{name: "Unknown roles are not granted", role: types.Admin, grant: "ghost", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := appcontext.WithMtsBlogUser(context.Background(), &dto.Claims{Role: tc.role})
+
+			if err := r.CanGrant(ctx, tc.grant); (err != nil) != tc.wantErr {
+				t.Errorf("expected error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCanGrantPermissions(t *testing.T) {
+	repo := &roleRepository{roles: map[types.Role][]types.Permission{
+		"role-manager": {types.RoleManage},
+	}}
+	r := rbac.New(rbac.WithRoles(repo))
+
+	ctx := appcontext.WithMtsBlogUser(context.Background(), &dto.Claims{Role: "role-manager"})
+
+	if err := r.CanGrantPermissions(ctx, []types.Permission{types.RoleManage}); err != nil {
+		t.Errorf("a role should grant its own permissions, got %v", err)
+	}
+
+	if err := r.CanGrantPermissions(ctx, []types.Permission{types.RoleManage, types.UserManage}); err == nil {
+		t.Error("a role should not grant permissions it lacks")
+	}
+}
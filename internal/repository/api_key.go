@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+)
+
+type APIKey interface {
+	Create(*model.APIKey) error
+	// ReadByHash returns the key with the hash, revoked and expired ones included.
+	ReadByHash(hash string) (*model.APIKey, error)
+	// ReadsByUserID returns the keys of the user that are not revoked.
+	ReadsByUserID(userID uint64) (*[]model.APIKey, error)
+	// Revoke revokes the key id of the user.
+	Revoke(id, userID uint64, at time.Time) error
+	// RevokeAll revokes every key of the user.
+	RevokeAll(userID uint64, at time.Time) error
+	// Touch records that the key was used at.
+	Touch(id uint64, at time.Time) error
+}
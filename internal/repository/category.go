@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/pagination"
+)
+
+type Category interface {
+	Create(*model.Category) error
+	Read(id uint64) (*model.Category, error)
+	ReadsBySlugs(slugs []string) ([]model.Category, error)
+	Reads(*pagination.Pageable) (*[]model.Category, error)
+	// AncestorIDs returns the ids of the category's parent, grandparent and so on.
+	AncestorIDs(id uint64) ([]uint64, error)
+	Update(*model.Category) error
+	Delete(id uint64) error
+}
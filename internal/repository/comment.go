@@ -1,13 +1,33 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/pagination"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
 )
 
 type Comment interface {
 	Create(*model.Comment) error
 	Read(id uint64) (*model.Comment, error)
-	ReadsByPostID(p *pagination.Pageable, pid string) (*[]model.Comment, error)
-	Delete(id uint64) error
+	ReadsByPostID(p *pagination.Pageable, pid string, f CommentFilter) (*[]model.Comment, error)
+	// Reads returns the comments of every post.
+	Reads(p *pagination.Pageable, f CommentFilter) (*[]model.Comment, error)
+	// ReadReplies returns every reply below the given comments, at any depth.
+	ReadReplies(ids []uint64, f CommentFilter) ([]model.Comment, error)
+	// CountApproved returns how many approved comments the user has written.
+	CountApproved(userID uint64) (int, error)
+	// Moderate moves the given comments to state and returns the ids that changed.
+	// It bumps their updated_at, so edits made against the old state fail.
+	Moderate(ids []uint64, state types.CommentState, actor string, at time.Time) ([]uint64, error)
+	// Update stores c's new text and keeps previous as a revision if its row
+	// is still at version, the updated_at it was read with, and returns
+	// errorutils.ErrPreconditionFailed otherwise.
+	Update(c *model.Comment, previous string, version time.Time) error
+	ReadRevisions(id uint64) ([]model.CommentRevision, error)
+	// Delete soft deletes c if its row is still at version, like Update.
+	Delete(c *model.Comment, version time.Time) error
+	Restore(*model.Comment) error
+	Purge(id uint64) error
 }
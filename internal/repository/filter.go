@@ -0,0 +1,32 @@
+package repository
+
+import "github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+
+// ReadsFilter narrows the rows returned by the list methods.
+type ReadsFilter struct {
+	// IncludeDeleted also returns soft deleted rows.
+	IncludeDeleted bool
+}
+
+// PostFilter narrows the posts returned by Post.Reads.
+type PostFilter struct {
+	ReadsFilter
+	// UserID only returns the posts written by this user when non-zero.
+	UserID uint64
+	// State only returns the posts in this workflow state when set.
+	State types.PostState
+	// Tag only returns the posts tagged with this tag slug when set.
+	Tag string
+	// Category only returns the posts in this category, or one of its
+	// subcategories, when set.
+	Category string
+}
+
+// CommentFilter narrows the comments returned by the Comment list methods.
+type CommentFilter struct {
+	ReadsFilter
+	// TopLevel leaves replies out.
+	TopLevel bool
+	// State only returns the comments in this moderation state when set.
+	State types.CommentState
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+)
+
+type Identity interface {
+	Create(*model.Identity) error
+	// Read returns the identity provider knows by subject.
+	Read(provider, subject string) (*model.Identity, error)
+	CreateLogin(*model.OIDCLogin) error
+	// UseLogin marks the unused login with the state hash used and returns it.
+	UseLogin(stateHash string, at time.Time) (*model.OIDCLogin, error)
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/pagination"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+)
+
+type LoginAttempt interface {
+	// LockedUntil returns when the lockout of the key ends, or nil if it is not locked at now.
+	LockedUntil(kind types.LoginAttemptKind, key string, now time.Time) (*time.Time, error)
+	// Fail counts a failed login of the key at at, forgetting failures before since.
+	Fail(kind types.LoginAttemptKind, key string, at, since time.Time) (*model.LoginAttempt, error)
+	Lock(id uint64, until time.Time) error
+	// Clear forgets the failures of the key.
+	Clear(kind types.LoginAttemptKind, key string) error
+	// ReadsLocked returns the keys locked at now.
+	ReadsLocked(p *pagination.Pageable, now time.Time) (*[]model.LoginAttempt, error)
+	Delete(id uint64) error
+}
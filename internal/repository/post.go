@@ -1,14 +1,40 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/pagination"
 )
 
 type Post interface {
-	Create(*model.Post) error
+	// Create stores p and links it to the tags and categories in one transaction.
+	Create(p *model.Post, tagIDs, categoryIDs []uint64) error
 	Read(id uint64) (*model.Post, error)
-	Reads(*pagination.Pageable) (*[]model.Post, error)
-	Update(*model.Post) error
-	Delete(id uint64) error
+	ReadBySlug(slug string) (*model.Post, error)
+	ReadByOldSlug(slug string) (*model.Post, error)
+	SlugTaken(slug string, exceptID uint64) (bool, error)
+	Reads(*pagination.Pageable, PostFilter) (*[]model.Post, error)
+	// Update stores p if its row is still at version, the updated_at it was
+	// read with, and returns errorutils.ErrPreconditionFailed otherwise. The
+	// rest of change is written in the same transaction.
+	Update(p *model.Post, version time.Time, change PostChange) error
+	ReadRevisions(postID uint64) ([]model.PostRevision, error)
+	ReadRevision(postID uint64, number int) (*model.PostRevision, error)
+	// Delete soft deletes p if its row is still at version, like Update.
+	Delete(p *model.Post, version time.Time) error
+	Restore(*model.Post) error
+	Purge(id uint64) error
+	PublishDue(now time.Time) (int64, error)
+}
+
+// PostChange is what an update of a post changes besides the post itself.
+type PostChange struct {
+	// Revision, when set, is stored as the next revision of the post and gets its Number.
+	Revision *model.PostRevision
+	// OldSlug, when it differs from the new slug, keeps redirecting to the post.
+	OldSlug string
+	// Tags and Categories, when set, replace the links of the post.
+	Tags       *[]uint64
+	Categories *[]uint64
 }
@@ -0,0 +1,11 @@
+package repository
+
+import "time"
+
+type RecoveryCode interface {
+	// Replace swaps the recovery codes of the user for the ones with hashes.
+	Replace(userID uint64, hashes []string, at time.Time) error
+	// Use marks the unused code with the hash used and reports false if there is none.
+	Use(userID uint64, hash string, at time.Time) (bool, error)
+	DeleteAll(userID uint64) error
+}
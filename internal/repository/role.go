@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+)
+
+type Role interface {
+	Create(*model.Role) error
+	Read(name types.Role) (*model.Role, error)
+	Reads() (*[]model.Role, error)
+	Update(*model.Role) error
+	// Delete fails with ErrRoleInUse while users have the role.
+	Delete(name types.Role) error
+}
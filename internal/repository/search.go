@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/pagination"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+)
+
+// SearchFilter is the query passed to Search.Search.
+type SearchFilter struct {
+	// Query is a web search style query, e.g. `go "error handling" -panic`.
+	Query string
+	// Types limits the results to these kinds of content.
+	Types []types.SearchType
+}
+
+type Search interface {
+	Search(*pagination.Pageable, SearchFilter) (*[]model.SearchResult, error)
+	// Reindex rebuilds the search vectors that use another language than the
+	// configured one. Tables already on that language are not touched.
+	Reindex() error
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+)
+
+type Session interface {
+	Create(*model.Session) error
+	// Revoked reports whether the session was revoked; unknown sessions count as revoked.
+	Revoked(id string) (bool, error)
+	Revoke(id string, at time.Time) error
+	// RevokeAll revokes every session of the user.
+	RevokeAll(userID uint64, at time.Time) error
+	// RevokeOthers revokes every session of the user but keep.
+	RevokeOthers(userID uint64, keep string, at time.Time) error
+	CreateRefreshToken(*model.RefreshToken) error
+	ReadRefreshToken(hash string) (*model.RefreshToken, error)
+	// UseRefreshToken marks the token used and reports false if it already was.
+	UseRefreshToken(id uint64, at time.Time) (bool, error)
+}
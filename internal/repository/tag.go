@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/pagination"
+)
+
+type Tag interface {
+	Create(*model.Tag) error
+	Read(id uint64) (*model.Tag, error)
+	ReadsBySlugs(slugs []string) ([]model.Tag, error)
+	Reads(*pagination.Pageable) (*[]model.Tag, error)
+	Update(*model.Tag) error
+	Delete(id uint64) error
+}
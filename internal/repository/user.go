@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/pagination"
 )
@@ -8,8 +10,35 @@ import (
 type User interface {
 	Create(*model.User) error
 	Read(id uint64) (*model.User, error)
+	// ReadAny reads the user with id, deleted or not.
+	ReadAny(id uint64) (*model.User, error)
 	ReadByEmail(email string) (*model.User, error)
-	Reads(*pagination.Pageable) (*[]model.User, error)
-	Update(*model.User) error
-	Delete(id uint64) error
+	Reads(*pagination.Pageable, ReadsFilter) (*[]model.User, error)
+	// Update stores u if its row is still at version, the updated_at it was
+	// read with, and returns errorutils.ErrPreconditionFailed otherwise.
+	Update(u *model.User, version time.Time) error
+	UpdatePassword(*model.User) error
+	UpdateEmail(*model.User) error
+	// VerifyEmail marks the email of the user verified at at.
+	VerifyEmail(id uint64, at time.Time) error
+	ReadStats(id uint64) (*model.UserStats, error)
+	// UpdateRole sets the role of u if its row is still at version, like Update.
+	// It returns errorutils.ErrLastAdmin instead of demoting the last admin
+	// able to act; so do UpdateStatus, Delete and Purge for suspending,
+	// deleting and purging them.
+	UpdateRole(u *model.User, version time.Time) error
+	// UpdateStatus sets the status of u with its suspension reason and end if
+	// its row is still at version, like Update.
+	UpdateStatus(u *model.User, version time.Time) error
+	// SetTOTPSecret stores a new, not yet enabled, two-factor secret for the user.
+	SetTOTPSecret(*model.User) error
+	EnableTOTP(id uint64, at time.Time) error
+	// UseTOTPStep records the time step of an accepted code and reports false
+	// if it is not newer than the last one.
+	UseTOTPStep(id uint64, step int64) (bool, error)
+	DisableTOTP(id uint64) error
+	// Delete soft deletes u if its row is still at version, like Update.
+	Delete(u *model.User, version time.Time) error
+	Restore(*model.User) error
+	Purge(id uint64) error
 }
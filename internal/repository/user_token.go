@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+)
+
+type UserToken interface {
+	Create(*model.UserToken) error
+	// Read returns the token with the hash issued for purpose.
+	Read(hash string, purpose types.TokenPurpose) (*model.UserToken, error)
+	// Use marks the token used and reports false if it already was.
+	Use(id uint64, at time.Time) (bool, error)
+	// UseAll marks every unused token of the user for purpose used.
+	UseAll(userID uint64, purpose types.TokenPurpose, at time.Time) error
+	// LastCreatedAt returns when the user was last issued a token for purpose, or nil.
+	LastCreatedAt(userID uint64, purpose types.TokenPurpose) (*time.Time, error)
+}
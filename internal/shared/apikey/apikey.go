@@ -0,0 +1,83 @@
+// Package apikey makes personal API keys and checks what their scopes allow.
+package apikey
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"strings"
+)
+
+const (
+	// marker starts every key, telling them apart from JWTs.
+	marker = "mtsk_"
+	// PrefixLen is how much of a key is kept in the clear to tell keys apart.
+	PrefixLen = len(marker) + 8
+
+	Read  = "read"
+	Write = "write"
+)
+
+// Generate returns a new key and its prefix.
+func Generate() (key, prefix string, err error) {
+	b := make([]byte, 32)
+	if _, err = rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	key = marker + base64.RawURLEncoding.EncodeToString(b)
+
+	return key, key[:PrefixLen], nil
+}
+
+// Is reports whether token looks like an API key rather than a JWT.
+func Is(token string) bool {
+	return strings.HasPrefix(token, marker)
+}
+
+// Allows reports whether scopes let a key call method on the route path.
+// A scope is a resource, the first segment of the path after the version,
+// and an access; write access includes read.
+func Allows(scopes []string, method, path string) bool {
+	res := resource(path)
+	read := method == http.MethodGet || method == http.MethodHead
+
+	for _, s := range scopes {
+		r, access, _ := strings.Cut(s, ":")
+		if r != res {
+			continue
+		}
+
+		if access == Write || (access == Read && read) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func resource(path string) string {
+	for _, seg := range strings.Split(path, "/") {
+		if seg == "" || version(seg) {
+			continue
+		}
+
+		return seg
+	}
+
+	return ""
+}
+
+func version(seg string) bool {
+	if len(seg) < 2 || seg[0] != 'v' {
+		return false
+	}
+
+	for _, c := range seg[1:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,52 @@
+package apikey_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/apikey"
+)
+
+func TestGenerate(t *testing.T) {
+	key, prefix, err := apikey.Generate()
+	require.NoError(t, err)
+
+	assert.True(t, apikey.Is(key))
+	assert.Len(t, prefix, apikey.PrefixLen)
+	assert.True(t, strings.HasPrefix(key, prefix))
+
+	other, _, err := apikey.Generate()
+	require.NoError(t, err)
+	assert.NotEqual(t, key, other)
+
+	assert.False(t, apikey.Is("eyJhbGciOiJFZERTQSJ9.e30.sig"))
+}
+
+func TestAllows(t *testing.T) {
+	scopes := []string{"posts:write", "comments:read"}
+
+	cases := []struct {
+		method string
+		path   string
+		want   bool
+	}{
+		{http.MethodPost, "/v1/posts", true},
+		{http.MethodPut, "v1/posts/:id", true},
+		{http.MethodGet, "/v1/posts/:id", true},
+		{http.MethodGet, "/v1/comments/:pid", true},
+		{http.MethodPut, "/v1/comments/:id", false},
+		{http.MethodGet, "/v1/tags", false},
+		{http.MethodGet, "/v1/users/me", false},
+		{http.MethodGet, "/v1", false},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.want, apikey.Allows(scopes, c.method, c.path), "%s %s", c.method, c.path)
+	}
+
+	assert.False(t, apikey.Allows(nil, http.MethodGet, "/v1/posts"))
+}
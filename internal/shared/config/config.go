@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 
@@ -16,6 +17,10 @@ type Config struct {
 		Port    string `yaml:"port"`
 		BaseURL string `yaml:"base_url"`
 		Version string `yaml:"version"`
+		// TrustedProxies are the CIDRs of the proxies in front of the API. The
+		// client IP is read from X-Forwarded-For only on requests they forward;
+		// otherwise it is the address the request came from.
+		TrustedProxies []string `yaml:"trustedproxies"`
 	} ` yaml:"rest"`
 
 	DB struct {
@@ -26,10 +31,92 @@ type Config struct {
 	} `yaml:"db"`
 	Env string `yaml:"env"`
 	JWT struct {
+		// Secret signs HS256 tokens when no keys are configured.
 		Secret string `yaml:"secret"`
-		Exp    string `yaml:"exp"`
+		// Exp and RefreshTTL are the lifetimes of access and refresh tokens.
+		Exp        time.Duration `yaml:"exp"`
+		RefreshTTL time.Duration `yaml:"refreshttl"`
+		Issuer     string        `yaml:"issuer"`
+		Audience   string        `yaml:"audience"`
+		// Keys sign and verify tokens by kid; the first one signs, the rest
+		// only verify so they can be retired without logging users out.
+		Keys []struct {
+			ID string `yaml:"id"`
+			// Algorithm is HS256, RS256 or EdDSA.
+			Algorithm string `yaml:"algorithm"`
+			// Secret is the HS256 secret; File is the PEM key of the others.
+			Secret string `yaml:"secret"`
+			File   string `yaml:"file"`
+		} `yaml:"keys"`
 	} `yaml:"jwt"`
-	Version bool `yaml:"version"`
+	Auth struct {
+		// ResetTTL is how long a password reset link stays valid.
+		ResetTTL time.Duration `yaml:"resetttl"`
+		// ResetURL is the page reset links point to, with the token in its query.
+		ResetURL string `yaml:"reseturl"`
+		// VerifyTTL is how long an email verification link stays valid.
+		VerifyTTL time.Duration `yaml:"verifyttl"`
+		// VerifyURL is where verification links point to, with the token in its query.
+		VerifyURL string `yaml:"verifyurl"`
+		// ResendCooldown is the least time between two verification mails to a user.
+		ResendCooldown time.Duration `yaml:"resendcooldown"`
+		// RequireVerified keeps users from commenting until they verify their email.
+		RequireVerified bool `yaml:"requireverified"`
+		// RequireTwoFactor keeps admins and mods from using their role until they
+		// turn on two-factor authentication.
+		RequireTwoFactor bool `yaml:"requiretwofactor"`
+		// ChallengeTTL is how long a login waits for the second factor.
+		ChallengeTTL time.Duration `yaml:"challengettl"`
+		// TOTPIssuer names the account in authenticator apps.
+		TOTPIssuer string `yaml:"totpissuer"`
+		// Lockout locks out an email after Threshold failed logins and a client IP
+		// after IPThreshold, for Base doubling with each further failure up to Max.
+		// Failures older than Window are forgotten.
+		Lockout struct {
+			Threshold   int           `yaml:"threshold"`
+			IPThreshold int           `yaml:"ipthreshold"`
+			Base        time.Duration `yaml:"base"`
+			Max         time.Duration `yaml:"max"`
+			Window      time.Duration `yaml:"window"`
+		} `yaml:"lockout"`
+	} `yaml:"auth"`
+	OIDC struct {
+		// LoginTTL is how long a sign in waits for the provider.
+		LoginTTL time.Duration `yaml:"loginttl"`
+		// Providers are the OpenID Connect providers users can sign in with, by
+		// name. RedirectURL is the callback of the API for the provider.
+		Providers map[string]struct {
+			Issuer       string   `yaml:"issuer"`
+			ClientID     string   `yaml:"clientid"`
+			ClientSecret string   `yaml:"clientsecret"`
+			RedirectURL  string   `yaml:"redirecturl"`
+			Scopes       []string `yaml:"scopes"`
+		} `yaml:"providers"`
+	} `yaml:"oidc"`
+	Mail struct {
+		// Driver is smtp, or log to write mails to File (stdout when empty).
+		Driver   string `yaml:"driver"`
+		From     string `yaml:"from"`
+		Host     string `yaml:"host"`
+		Port     string `yaml:"port"`
+		Username string `yaml:"username"`
+		Password string `yaml:"password"`
+		File     string `yaml:"file"`
+	} `yaml:"mail"`
+	Version   bool `yaml:"version"`
+	Scheduler struct {
+		Interval time.Duration `yaml:"interval"`
+	} `yaml:"scheduler"`
+	Search struct {
+		Language string `yaml:"language"`
+	} `yaml:"search"`
+	Comment struct {
+		MaxDepth int `yaml:"maxdepth"`
+		// Moderation is the policy for new comments: none, trusted or all.
+		Moderation string `yaml:"moderation"`
+		// TrustAfter is how many approved comments make a user trusted.
+		TrustAfter int `yaml:"trustafter"`
+	} `yaml:"comment"`
 }
 
 func Init() *Config {
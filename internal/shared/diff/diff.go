@@ -0,0 +1,222 @@
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// Context is the number of unchanged lines shown around each change.
+	Context = 3
+	// MaxLines caps the lines of each text; diffing takes time in proportion
+	// to their length times the number of changes.
+	MaxLines = 5000
+)
+
+// ErrTooLong is returned for texts longer than MaxLines.
+var ErrTooLong = errors.New("diff: text has too many lines")
+
+type op struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// Unified returns the unified line diff turning a into b, labelled with the
+// from and to names. It returns an empty string when a and b are equal.
+func Unified(from, to, a, b string) (string, error) {
+	al, bl := lines(a), lines(b)
+	if len(al) > MaxLines || len(bl) > MaxLines {
+		return "", ErrTooLong
+	}
+
+	ops := lineOps(al, bl)
+
+	var out strings.Builder
+
+	// aBefore[i] and bBefore[i] count the lines of a and b before ops[i].
+	aBefore := make([]int, len(ops)+1)
+	bBefore := make([]int, len(ops)+1)
+
+	for i, o := range ops {
+		aBefore[i+1], bBefore[i+1] = aBefore[i], bBefore[i]
+		if o.kind != '+' {
+			aBefore[i+1]++
+		}
+
+		if o.kind != '-' {
+			bBefore[i+1]++
+		}
+	}
+
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+
+			continue
+		}
+
+		start := max(i-Context, 0)
+		end := i
+
+		// Grow the hunk while the next change is close enough to share context.
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != ' ' {
+				end = j + 1
+			} else if j-end >= 2*Context {
+				break
+			}
+		}
+
+		end = min(end+Context, len(ops))
+
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n", from, to)
+		}
+
+		aLen := aBefore[end] - aBefore[start]
+		bLen := bBefore[end] - bBefore[start]
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(aBefore[start], aLen), hunkRange(bBefore[start], bLen))
+
+		for _, o := range ops[start:end] {
+			out.WriteByte(o.kind)
+			out.WriteString(o.line)
+			out.WriteByte('\n')
+		}
+
+		i = end
+	}
+
+	return out.String(), nil
+}
+
+// hunkRange formats a hunk range; empty ranges point at the line before them.
+func hunkRange(before, n int) string {
+	if n == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+
+	return fmt.Sprintf("%d,%d", before+1, n)
+}
+
+func lines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// lineOps returns a shortest edit script turning a into b, found with Myers'
+// linear space algorithm.
+func lineOps(a, b []string) []op {
+	return script(make([]op, 0, len(a)+len(b)), a, b)
+}
+
+// script appends the edit script turning a into b to ops. Common ends are
+// kept as they are; the rest is split at a middle snake and done in halves.
+func script(ops []op, a, b []string) []op {
+	p := 0
+	for p < len(a) && p < len(b) && a[p] == b[p] {
+		ops = append(ops, op{' ', a[p]})
+		p++
+	}
+
+	a, b = a[p:], b[p:]
+
+	s := 0
+	for s < len(a) && s < len(b) && a[len(a)-1-s] == b[len(b)-1-s] {
+		s++
+	}
+
+	suffix := a[len(a)-s:]
+	a, b = a[:len(a)-s], b[:len(b)-s]
+
+	switch {
+	case len(a) == 0:
+		for _, l := range b {
+			ops = append(ops, op{'+', l})
+		}
+	case len(b) == 0:
+		for _, l := range a {
+			ops = append(ops, op{'-', l})
+		}
+	default:
+		x, y, u, v := middleSnake(a, b)
+
+		ops = script(ops, a[:x], b[:y])
+		for _, l := range a[x:u] {
+			ops = append(ops, op{' ', l})
+		}
+
+		ops = script(ops, a[u:], b[v:])
+	}
+
+	for _, l := range suffix {
+		ops = append(ops, op{' ', l})
+	}
+
+	return ops
+}
+
+// middleSnake returns the snake (x, y)-(u, v) in the middle of a shortest
+// edit path from (0, 0) to (len(a), len(b)), searching from both ends at
+// once. Diagonal k holds the points where x-y == k; vf and vb keep the
+// furthest x reached on each, vb counting from the far end.
+func middleSnake(a, b []string) (x, y, u, v int) {
+	n, m := len(a), len(b)
+	delta := n - m
+	odd := delta%2 != 0
+	dMax := (n + m + 1) / 2
+	off := dMax + 1
+
+	vf := make([]int, 2*dMax+3)
+	vb := make([]int, 2*dMax+3)
+
+	for d := 0; d <= dMax; d++ {
+		for k := -d; k <= d; k += 2 {
+			x := vf[off+k-1] + 1
+			if k == -d || (k != d && vf[off+k-1] < vf[off+k+1]) {
+				x = vf[off+k+1]
+			}
+
+			y := x - k
+			x0, y0 := x, y
+
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+
+			vf[off+k] = x
+
+			if kb := delta - k; odd && kb >= -(d-1) && kb <= d-1 && x+vb[off+kb] >= n {
+				return x0, y0, x, y
+			}
+		}
+
+		for k := -d; k <= d; k += 2 {
+			x := vb[off+k-1] + 1
+			if k == -d || (k != d && vb[off+k-1] < vb[off+k+1]) {
+				x = vb[off+k+1]
+			}
+
+			y := x - k
+			x0, y0 := x, y
+
+			for x < n && y < m && a[n-1-x] == b[m-1-y] {
+				x++
+				y++
+			}
+
+			vb[off+k] = x
+
+			if kf := delta - k; !odd && kf >= -d && kf <= d && x+vf[off+kf] >= n {
+				return n - x, m - y, n - x0, m - y0
+			}
+		}
+	}
+
+	// Unreachable: a shortest path is at most n+m long.
+	return 0, 0, n, m
+}
@@ -0,0 +1,160 @@
+package diff_test
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/diff"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want string
+	}{
+		{name: "equal", a: "a\nb\n", b: "a\nb\n", want: ""},
+		{name: "both empty", a: "", b: "", want: ""},
+		{
+			name: "changed line",
+			a:    "a\nb\nc",
+			b:    "a\nB\nc",
+			want: "--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name: "from empty",
+			a:    "",
+			b:    "a\nb",
+			want: "--- old\n+++ new\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name: "to empty",
+			a:    "a",
+			b:    "",
+			want: "--- old\n+++ new\n@@ -1,1 +0,0 @@\n-a\n",
+		},
+		{
+			name: "trailing newline is ignored",
+			a:    "a\nb\n",
+			b:    "a\nb",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := diff.Unified("old", "new", tt.a, tt.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tt.want {
+				t.Errorf("Unified() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnifiedHunks(t *testing.T) {
+	var a []string
+	for i := 1; i <= 20; i++ {
+		a = append(a, strings.Repeat("x", i))
+	}
+
+	b := append([]string(nil), a...)
+	b[1] = "changed"
+	b[17] = "changed"
+
+	got, err := diff.Unified("old", "new", strings.Join(a, "\n"), strings.Join(b, "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := strings.Count(got, "@@ -"); n != 2 {
+		t.Fatalf("got %d hunks, want 2:\n%s", n, got)
+	}
+
+	if !strings.Contains(got, "@@ -1,5 +1,5 @@\n") || !strings.Contains(got, "@@ -15,6 +15,6 @@\n") {
+		t.Errorf("unexpected hunk ranges:\n%s", got)
+	}
+
+	// Changes with little context between them share a hunk.
+	b = append([]string(nil), a...)
+	b[1] = "changed"
+	b[7] = "changed"
+
+	got, err = diff.Unified("old", "new", strings.Join(a, "\n"), strings.Join(b, "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := strings.Count(got, "@@ -"); n != 1 {
+		t.Errorf("got %d hunks, want 1:\n%s", n, got)
+	}
+}
+
+func TestUnifiedShortest(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	text := func() []string {
+		l := make([]string, rnd.Intn(30))
+		for i := range l {
+			l[i] = string(rune('a' + rnd.Intn(4)))
+		}
+
+		return l
+	}
+
+	for i := 0; i < 500; i++ {
+		a, b := text(), text()
+
+		got, err := diff.Unified("old", "new", strings.Join(a, "\n"), strings.Join(b, "\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		edits := 0
+
+		for _, l := range strings.Split(got, "\n") {
+			if (strings.HasPrefix(l, "-") && !strings.HasPrefix(l, "---")) || (strings.HasPrefix(l, "+") && !strings.HasPrefix(l, "+++")) {
+				edits++
+			}
+		}
+
+		if want := len(a) + len(b) - 2*lcs(a, b); edits != want {
+			t.Fatalf("%q -> %q: got %d edits, want %d:\n%s", a, b, edits, want, got)
+		}
+	}
+}
+
+// lcs returns the length of the longest common subsequence of a and b.
+func lcs(a, b []string) int {
+	prev := make([]int, len(b)+1)
+
+	for i := range a {
+		cur := make([]int, len(b)+1)
+
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+
+		prev = cur
+	}
+
+	return prev[len(b)]
+}
+
+func TestUnifiedTooLong(t *testing.T) {
+	long := strings.Repeat("x\n", diff.MaxLines+1)
+
+	if _, err := diff.Unified("old", "new", long, "x"); !errors.Is(err, diff.ErrTooLong) {
+		t.Errorf("expected ErrTooLong, got %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package etag
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
+)
+
+const (
+	HeaderETag        = "ETag"
+	HeaderIfMatch     = "If-Match"
+	HeaderIfNoneMatch = "If-None-Match"
+)
+
+// Make returns the strong ETag of the resource with the given id as it was at
+// version, its updated_at. Versions are compared at the microsecond precision
+// and wall clock postgres stores them with, so a freshly written row and the
+// same row read back share a tag.
+func Make(id uint64, version time.Time) string {
+	return fmt.Sprintf(`"%d-%s"`, id, version.Round(time.Microsecond).Format("20060102150405.000000"))
+}
+
+// Weak returns a weak ETag over parts, for lists and other composed responses.
+func Weak(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+
+	return `W/"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// Match reports whether tag is listed in the If-Match or If-None-Match header
+// value h. Tags are compared weakly, and "*" matches any tag.
+func Match(h, tag string) bool {
+	tag = strings.TrimPrefix(tag, "W/")
+
+	for _, t := range strings.Split(h, ",") {
+		t = strings.TrimSpace(t)
+		if t == "*" || strings.TrimPrefix(t, "W/") == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Check returns errorutils.ErrPreconditionFailed unless the If-Match header
+// value ifMatch is empty or lists tag.
+func Check(ifMatch, tag string) error {
+	if ifMatch == "" || Match(ifMatch, tag) {
+		return nil
+	}
+
+	return errorutils.New(errorutils.ErrPreconditionFailed, nil)
+}
@@ -0,0 +1,82 @@
+package etag_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/etag"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
+)
+
+func TestMake(t *testing.T) {
+	v := time.Date(2023, 1, 2, 3, 4, 5, 6, time.UTC)
+
+	if etag.Make(1, v) != etag.Make(1, v) {
+		t.Error("same version should give the same tag")
+	}
+
+	if etag.Make(1, v) == etag.Make(1, v.Add(time.Microsecond)) {
+		t.Error("new version should give a new tag")
+	}
+
+	if etag.Make(1, v) != etag.Make(1, v.Add(400*time.Nanosecond)) {
+		t.Error("versions within a microsecond should give the same tag")
+	}
+
+	if etag.Make(1, v) == etag.Make(2, v) {
+		t.Error("other resource should give another tag")
+	}
+}
+
+func TestWeak(t *testing.T) {
+	if etag.Weak("a", "b") == etag.Weak("ab") {
+		t.Error("parts should not run into each other")
+	}
+
+	if etag.Weak("a", "b") != etag.Weak("a", "b") {
+		t.Error("same parts should give the same tag")
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		tag    string
+		want   bool
+	}{
+		{name: "equal", header: `"1-a"`, tag: `"1-a"`, want: true},
+		{name: "different", header: `"1-a"`, tag: `"1-b"`, want: false},
+		{name: "list", header: `"1-b", "1-a"`, tag: `"1-a"`, want: true},
+		{name: "any", header: `*`, tag: `"1-a"`, want: true},
+		{name: "weak header", header: `W/"1-a"`, tag: `"1-a"`, want: true},
+		{name: "weak tag", header: `"1-a"`, tag: `W/"1-a"`, want: true},
+		{name: "empty", header: ``, tag: `"1-a"`, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := etag.Match(tt.header, tt.tag); got != tt.want {
+				t.Errorf("Match(%q, %q) = %v, want %v", tt.header, tt.tag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	if err := etag.Check("", `"1-a"`); err != nil {
+		t.Errorf("no If-Match should pass, got %v", err)
+	}
+
+	if err := etag.Check(`"1-a"`, `"1-a"`); err != nil {
+		t.Errorf("matching If-Match should pass, got %v", err)
+	}
+
+	err := etag.Check(`"1-a"`, `"1-b"`)
+
+	var apiErr *errorutils.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != errorutils.ErrCodePreconditionFailed {
+		t.Errorf("stale If-Match should fail with %s, got %v", errorutils.ErrCodePreconditionFailed, err)
+	}
+}
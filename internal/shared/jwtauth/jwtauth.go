@@ -0,0 +1,292 @@
+package jwtauth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
+)
+
+// Supported signing algorithms.
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	EdDSA = "EdDSA"
+)
+
+// DefaultTTL is the access token lifetime used when none is configured.
+const DefaultTTL = 15 * time.Minute
+
+// Key is a signing key identified by the kid header of the tokens it signs.
+// Keys holding only a public key verify tokens but can not sign them.
+type Key struct {
+	ID      string
+	method  jwt.SigningMethod
+	private crypto.PrivateKey
+	public  crypto.PublicKey
+}
+
+// NewKey parses a key for alg. HS256 takes the raw secret; RS256 and EdDSA
+// take a PEM encoded private key, or a public key for verify only keys.
+func NewKey(id, alg string, material []byte) (*Key, error) {
+	if id == "" {
+		return nil, errors.New("jwtauth: key id is required")
+	}
+
+	k := &Key{ID: id}
+
+	switch alg {
+	case HS256:
+		if len(material) == 0 {
+			return nil, fmt.Errorf("jwtauth: key %s: empty secret", id)
+		}
+
+		k.method = jwt.SigningMethodHS256
+		k.private = material
+		k.public = material
+	case RS256:
+		k.method = jwt.SigningMethodRS256
+
+		if pk, err := jwt.ParseRSAPrivateKeyFromPEM(material); err == nil {
+			k.private = pk
+			k.public = &pk.PublicKey
+
+			break
+		}
+
+		pub, err := jwt.ParseRSAPublicKeyFromPEM(material)
+		if err != nil {
+			return nil, fmt.Errorf("jwtauth: key %s: %w", id, err)
+		}
+
+		k.public = pub
+	case EdDSA:
+		k.method = jwt.SigningMethodEdDSA
+
+		if pk, err := jwt.ParseEdPrivateKeyFromPEM(material); err == nil {
+			k.private = pk
+			k.public = pk.(ed25519.PrivateKey).Public()
+
+			break
+		}
+
+		pub, err := jwt.ParseEdPublicKeyFromPEM(material)
+		if err != nil {
+			return nil, fmt.Errorf("jwtauth: key %s: %w", id, err)
+		}
+
+		k.public = pub
+	default:
+		return nil, fmt.Errorf("jwtauth: key %s: unsupported algorithm %q", id, alg)
+	}
+
+	return k, nil
+}
+
+// Signer issues and verifies access tokens. The first key signs new tokens;
+// the others only verify, so a retired key keeps its tokens valid until they
+// expire.
+type Signer struct {
+	keys     []*Key
+	issuer   string
+	audience string
+	ttl      time.Duration
+}
+
+// New returns a Signer for keys, stamping tokens with issuer and audience and
+// letting them live for ttl.
+func New(issuer, audience string, ttl time.Duration, keys ...*Key) (*Signer, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("jwtauth: no keys")
+	}
+
+	if keys[0].private == nil {
+		return nil, fmt.Errorf("jwtauth: key %s can not sign", keys[0].ID)
+	}
+
+	seen := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		if seen[k.ID] {
+			return nil, fmt.Errorf("jwtauth: duplicate key id %s", k.ID)
+		}
+
+		seen[k.ID] = true
+	}
+
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+
+	return &Signer{keys: keys, issuer: issuer, audience: audience, ttl: ttl}, nil
+}
+
+// claims are the registered claims together with the application ones.
+type claims struct {
+	jwt.StandardClaims
+	dto.Claims
+}
+
+// Sign returns an access token for c issued at now, and when it expires.
+func (s *Signer) Sign(c *dto.Claims, now time.Time) (string, time.Time, error) {
+	k := s.keys[0]
+	expiresAt := now.Add(s.ttl)
+
+	token := jwt.NewWithClaims(k.method, &claims{
+		StandardClaims: jwt.StandardClaims{
+			Subject:   strconv.FormatUint(c.UID, 10),
+			Issuer:    s.issuer,
+			Audience:  s.audience,
+			IssuedAt:  now.Unix(),
+			NotBefore: now.Unix(),
+			ExpiresAt: expiresAt.Unix(),
+		},
+		Claims: *c,
+	})
+	token.Header["kid"] = k.ID
+
+	signed, err := token.SignedString(k.private)
+	if err != nil {
+		return "", time.Time{}, errorutils.New(errorutils.ErrUnexpected, err)
+	}
+
+	return signed, expiresAt, nil
+}
+
+// Verify checks the signature, lifetime, issuer and audience of token and
+// returns its claims.
+func (s *Signer) Verify(token string) (*dto.Claims, error) {
+	c := new(claims)
+
+	_, err := jwt.ParseWithClaims(token, c, s.keyFunc)
+	if err != nil {
+		var ve *jwt.ValidationError
+		if errors.As(err, &ve) && ve.Errors&jwt.ValidationErrorExpired != 0 {
+			return nil, errorutils.New(errorutils.ErrExpiredToken, err)
+		}
+
+		return nil, errorutils.New(errorutils.ErrInvalidToken, err)
+	}
+
+	if c.ExpiresAt == 0 {
+		return nil, errorutils.New(errorutils.ErrInvalidToken, errors.New("missing exp claim"))
+	}
+
+	if s.issuer != "" && !c.VerifyIssuer(s.issuer, true) {
+		return nil, errorutils.New(errorutils.ErrInvalidToken, errors.New("unexpected issuer"))
+	}
+
+	if s.audience != "" && !c.VerifyAudience(s.audience, true) {
+		return nil, errorutils.New(errorutils.ErrInvalidToken, errors.New("unexpected audience"))
+	}
+
+	return &c.Claims, nil
+}
+
+// keyFunc picks the key named by the kid header. The token must use the
+// algorithm of that key, so a public key is never taken for an HMAC secret.
+func (s *Signer) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	for _, k := range s.keys {
+		if k.ID != kid {
+			continue
+		}
+
+		if token.Method.Alg() != k.method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+
+		return k.public, nil
+	}
+
+	return nil, fmt.Errorf("unknown key id: %q", kid)
+}
+
+// JWK is a public key in JSON Web Key form.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// PublicKey returns the RSA or Ed25519 key k holds.
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	switch {
+	case k.Kty == "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("jwtauth: jwk %s: %w", k.Kid, err)
+		}
+
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("jwtauth: jwk %s: %w", k.Kid, err)
+		}
+
+		exp := new(big.Int).SetBytes(e)
+		if !exp.IsInt64() || exp.Int64() < 3 || exp.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("jwtauth: jwk %s: bad exponent", k.Kid)
+		}
+
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+	case k.Kty == "OKP" && k.Crv == "Ed25519":
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("jwtauth: jwk %s: %w", k.Kid, err)
+		}
+
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("jwtauth: jwk %s: bad key size", k.Kid)
+		}
+
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("jwtauth: jwk %s: unsupported key type %q", k.Kid, k.Kty)
+	}
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys of the signer. HMAC secrets are never published.
+func (s *Signer) JWKS() *JWKS {
+	set := &JWKS{Keys: []JWK{}}
+
+	for _, k := range s.keys {
+		jwk := JWK{Kid: k.ID, Use: "sig", Alg: k.method.Alg()}
+
+		switch pub := k.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
@@ -0,0 +1,292 @@
+package jwtauth_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/jwtauth"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
+)
+
+var claims = &dto.Claims{UID: 7, Username: "samil", Email: "samil@samilov.com", SessionID: "s1"}
+
+func privatePEM(t *testing.T, key any) []byte {
+	t.Helper()
+
+	b, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: b})
+}
+
+func publicPEM(t *testing.T, key any) []byte {
+	t.Helper()
+
+	b, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: b})
+}
+
+func newKey(t *testing.T, id, alg string, material []byte) *jwtauth.Key {
+	t.Helper()
+
+	k, err := jwtauth.NewKey(id, alg, material)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return k
+}
+
+func newSigner(t *testing.T, keys ...*jwtauth.Key) *jwtauth.Signer {
+	t.Helper()
+
+	s, err := jwtauth.New("mts-blog-api", "mts-blog-web", time.Minute, keys...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func wantCode(t *testing.T, err error, code string) {
+	t.Helper()
+
+	var apiErr *errorutils.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != code {
+		t.Fatalf("err = %v, want %s", err, code)
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := map[string]*jwtauth.Key{
+		jwtauth.HS256: newKey(t, "hs", jwtauth.HS256, []byte("secret")),
+		jwtauth.RS256: newKey(t, "rs", jwtauth.RS256, privatePEM(t, rsaKey)),
+		jwtauth.EdDSA: newKey(t, "ed", jwtauth.EdDSA, privatePEM(t, edKey)),
+	}
+
+	for alg, k := range keys {
+		t.Run(alg, func(t *testing.T) {
+			s := newSigner(t, k)
+
+			token, exp, err := s.Sign(claims, time.Now())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if time.Until(exp) > time.Minute {
+				t.Errorf("expiry %v is past the ttl", exp)
+			}
+
+			got, err := s.Verify(token)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if *got != *claims {
+				t.Errorf("claims = %+v, want %+v", got, claims)
+			}
+		})
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	k := newKey(t, "k1", jwtauth.HS256, []byte("secret"))
+	s := newSigner(t, k)
+
+	expired, _, err := s.Sign(claims, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = s.Verify(expired)
+	wantCode(t, err, errorutils.ErrCodeExpiredToken)
+
+	other, err := jwtauth.New("someone-else", "mts-blog-web", time.Minute, k)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, _, err := other.Sign(claims, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = s.Verify(token)
+	wantCode(t, err, errorutils.ErrCodeInvalidToken)
+
+	other, err = jwtauth.New("mts-blog-api", "someone-else", time.Minute, k)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, _, err = other.Sign(claims, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = s.Verify(token)
+	wantCode(t, err, errorutils.ErrCodeInvalidToken)
+
+	unknown := newSigner(t, newKey(t, "k2", jwtauth.HS256, []byte("secret")))
+
+	token, _, err = unknown.Sign(claims, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = s.Verify(token)
+	wantCode(t, err, errorutils.ErrCodeInvalidToken)
+}
+
+func TestRotation(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	old := newKey(t, "old", jwtauth.HS256, []byte("secret"))
+	current := newKey(t, "new", jwtauth.EdDSA, privatePEM(t, edKey))
+
+	token, _, err := newSigner(t, old).Sign(claims, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = newSigner(t, current, old).Verify(token); err != nil {
+		t.Errorf("token of a retired key should verify: %v", err)
+	}
+
+	if _, err = newSigner(t, current).Verify(token); err == nil {
+		t.Error("token of a removed key should not verify")
+	}
+}
+
+func TestAlgorithmConfusion(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	material := publicPEM(t, pub)
+
+	// A token signed with HMAC over the published key must not pass as EdDSA.
+	forged, _, err := newSigner(t, newKey(t, "ed", jwtauth.HS256, material)).Sign(claims, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := newSigner(t, newKey(t, "hs", jwtauth.HS256, []byte("secret")), newKey(t, "ed", jwtauth.EdDSA, material))
+
+	_, err = s.Verify(forged)
+	wantCode(t, err, errorutils.ErrCodeInvalidToken)
+}
+
+func TestNew(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verifyOnly := newKey(t, "ed", jwtauth.EdDSA, publicPEM(t, pub))
+	if _, err = jwtauth.New("", "", 0, verifyOnly); err == nil {
+		t.Error("a public key should not sign")
+	}
+
+	hs := newKey(t, "hs", jwtauth.HS256, []byte("secret"))
+	if _, err = jwtauth.New("", "", 0, hs, hs); err == nil {
+		t.Error("duplicate key ids should fail")
+	}
+
+	if _, err = jwtauth.New("", "", 0); err == nil {
+		t.Error("an empty key set should fail")
+	}
+
+	if _, err = jwtauth.NewKey("x", "none", []byte("secret")); err == nil {
+		t.Error("unsupported algorithms should fail")
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := newSigner(t,
+		newKey(t, "ed", jwtauth.EdDSA, privatePEM(t, edKey)),
+		newKey(t, "rs", jwtauth.RS256, publicPEM(t, &rsaKey.PublicKey)),
+		newKey(t, "hs", jwtauth.HS256, []byte("secret")),
+	)
+
+	set := s.JWKS()
+	if len(set.Keys) != 2 {
+		t.Fatalf("got %d keys, want 2", len(set.Keys))
+	}
+
+	if k := set.Keys[0]; k.Kid != "ed" || k.Kty != "OKP" || k.Crv != "Ed25519" || k.Alg != jwtauth.EdDSA || len(k.X) != 43 {
+		t.Errorf("ed key = %+v", k)
+	}
+
+	if k := set.Keys[1]; k.Kid != "rs" || k.Kty != "RSA" || k.Alg != jwtauth.RS256 || k.E != "AQAB" || k.N == "" {
+		t.Errorf("rsa key = %+v", k)
+	}
+}
+
+func TestJWKPublicKey(t *testing.T) {
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set := newSigner(t,
+		newKey(t, "ed", jwtauth.EdDSA, privatePEM(t, edKey)),
+		newKey(t, "rs", jwtauth.RS256, publicPEM(t, &rsaKey.PublicKey)),
+	).JWKS()
+
+	pub, err := set.Keys[0].PublicKey()
+	if err != nil || !edPub.Equal(pub) {
+		t.Errorf("ed key = %v, %v", pub, err)
+	}
+
+	pub, err = set.Keys[1].PublicKey()
+	if err != nil || !rsaKey.PublicKey.Equal(pub) {
+		t.Errorf("rsa key = %v, %v", pub, err)
+	}
+
+	if _, err = (jwtauth.JWK{Kid: "ec", Kty: "EC"}).PublicKey(); err == nil {
+		t.Error("unsupported key types should fail")
+	}
+}
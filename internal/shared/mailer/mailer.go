@@ -0,0 +1,110 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/smtp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
+)
+
+// Message is a plain text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails.
+type Mailer interface {
+	Send(ctx context.Context, m *Message) error
+}
+
+// Format renders m as an RFC 5322 message from the sender from.
+func Format(from string, m *Message, date time.Time) ([]byte, error) {
+	for _, h := range []string{from, m.To, m.Subject} {
+		if strings.ContainsAny(h, "\r\n") {
+			return nil, errors.New("mailer: header contains a line break")
+		}
+	}
+
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", m.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", m.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(m.Body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+
+	return b.Bytes(), nil
+}
+
+type smtpMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// NewSMTP returns a Mailer that sends through the SMTP server at host:port,
+// authenticating with username and password when a username is given.
+func NewSMTP(host, port, username, password, from string) Mailer {
+	m := &smtpMailer{addr: net.JoinHostPort(host, port), from: from}
+
+	if username != "" {
+		m.auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return m
+}
+
+func (s *smtpMailer) Send(_ context.Context, m *Message) error {
+	msg, err := Format(s.from, m, time.Now())
+	if err != nil {
+		return errorutils.New(errorutils.ErrMailSend, err)
+	}
+
+	if err = smtp.SendMail(s.addr, s.auth, s.from, []string{m.To}, msg); err != nil {
+		return errorutils.New(errorutils.ErrMailSend, err)
+	}
+
+	return nil
+}
+
+type logMailer struct {
+	mu   sync.Mutex
+	w    io.Writer
+	from string
+}
+
+// NewLog returns a Mailer that writes messages to w instead of sending them,
+// for local development and tests.
+func NewLog(w io.Writer, from string) Mailer {
+	return &logMailer{w: w, from: from}
+}
+
+func (l *logMailer) Send(_ context.Context, m *Message) error {
+	msg, err := Format(l.from, m, time.Now())
+	if err != nil {
+		return errorutils.New(errorutils.ErrMailSend, err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, err = l.w.Write(append(msg, '\n')); err != nil {
+		return errorutils.New(errorutils.ErrMailSend, err)
+	}
+
+	return nil
+}
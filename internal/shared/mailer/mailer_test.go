@@ -0,0 +1,53 @@
+package mailer_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/mailer"
+)
+
+func TestFormat(t *testing.T) {
+	date := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	got, err := mailer.Format("blog@example.com", &mailer.Message{To: "samil@samilov.com", Subject: "Hi", Body: "line 1\nline 2"}, date)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "From: blog@example.com\r\n" +
+		"To: samil@samilov.com\r\n" +
+		"Subject: Hi\r\n" +
+		"Date: Mon, 02 Jan 2023 03:04:05 +0000\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"\r\n" +
+		"line 1\r\nline 2\r\n"
+
+	if string(got) != want {
+		t.Errorf("Format() = %q, want %q", got, want)
+	}
+}
+
+func TestFormatHeaderInjection(t *testing.T) {
+	_, err := mailer.Format("blog@example.com", &mailer.Message{To: "samil@samilov.com\r\nBcc: x@example.com"}, time.Now())
+	if err == nil {
+		t.Error("line breaks in headers should fail")
+	}
+}
+
+func TestLog(t *testing.T) {
+	var buf bytes.Buffer
+
+	m := mailer.NewLog(&buf, "blog@example.com")
+	if err := m.Send(context.Background(), &mailer.Message{To: "samil@samilov.com", Subject: "Hi", Body: "token"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buf.String(), "To: samil@samilov.com") || !strings.Contains(buf.String(), "token") {
+		t.Errorf("message not written: %q", buf.String())
+	}
+}
@@ -0,0 +1,377 @@
+// Package oidc signs users in with OpenID Connect providers through the
+// authorization code flow with PKCE.
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/jwtauth"
+)
+
+const (
+	// leeway is the clock skew allowed when checking ID token lifetimes.
+	leeway = time.Minute
+	// maxBody caps what is read from the provider.
+	maxBody = 1 << 20
+)
+
+// Config is a provider users can sign in with.
+type Config struct {
+	// Issuer is the provider URL, under which its discovery document is served.
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the callback the provider sends users back to.
+	RedirectURL string
+	// Scopes are asked for besides openid.
+	Scopes []string
+}
+
+// Identity is the user the provider signed in.
+type Identity struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+}
+
+// metadata is the part of the discovery document the flow needs.
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type key struct {
+	alg    string
+	public crypto.PublicKey
+}
+
+// Provider is an OpenID Connect provider. Its discovery document and keys are
+// fetched on first use, and the keys again when a token names an unknown one.
+type Provider struct {
+	config Config
+	client *http.Client
+
+	mu   sync.Mutex
+	meta *metadata
+	keys map[string]key
+}
+
+// New returns the provider for cfg, reached through client.
+func New(cfg Config, client *http.Client) *Provider {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	return &Provider{config: cfg, client: client}
+}
+
+// Challenge returns the S256 PKCE challenge for verifier.
+func Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthCodeURL returns the page to send the user to. The same nonce and
+// verifier must be passed to Exchange.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	m, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(m.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("oidc: authorization endpoint: %w", err)
+	}
+
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", p.config.ClientID)
+	q.Set("redirect_uri", p.config.RedirectURL)
+	q.Set("scope", strings.Join(append([]string{"openid"}, p.config.Scopes...), " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", Challenge(verifier))
+	q.Set("code_challenge_method", "S256")
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+// Exchange trades code for tokens and returns who the ID token, which must
+// carry nonce, says signed in.
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string, now time.Time) (*Identity, error) {
+	m, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"client_id":     {p.config.ClientID},
+		"code_verifier": {verifier},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("oidc: token request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	if p.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	var tokens struct {
+		IDToken string `json:"id_token"`
+	}
+
+	if err = p.do(req, &tokens); err != nil {
+		return nil, err
+	}
+
+	if tokens.IDToken == "" {
+		return nil, errors.New("oidc: no id token")
+	}
+
+	return p.verify(ctx, m, tokens.IDToken, nonce, now)
+}
+
+// idClaims are the claims of an ID token. They are checked in verify.
+type idClaims struct {
+	Issuer            string   `json:"iss"`
+	Subject           string   `json:"sub"`
+	Audience          audience `json:"aud"`
+	AuthorizedParty   string   `json:"azp"`
+	ExpiresAt         int64    `json:"exp"`
+	Nonce             string   `json:"nonce"`
+	Email             string   `json:"email"`
+	EmailVerified     bool     `json:"email_verified"`
+	Name              string   `json:"name"`
+	PreferredUsername string   `json:"preferred_username"`
+}
+
+func (c *idClaims) Valid() error {
+	return nil
+}
+
+// audience is the aud claim, a string or a list of them.
+type audience []string
+
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*a = audience{s}
+
+		return nil
+	}
+
+	return json.Unmarshal(b, (*[]string)(a))
+}
+
+func (a audience) contains(s string) bool {
+	for _, v := range a {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *Provider) verify(ctx context.Context, m *metadata, raw, nonce string, now time.Time) (*Identity, error) {
+	c := new(idClaims)
+	parser := &jwt.Parser{ValidMethods: []string{jwtauth.RS256, jwtauth.EdDSA}}
+
+	if _, err := parser.ParseWithClaims(raw, c, p.keyFunc(ctx)); err != nil {
+		return nil, fmt.Errorf("oidc: id token: %w", err)
+	}
+
+	switch {
+	case c.Issuer != m.Issuer:
+		return nil, errors.New("oidc: id token: unexpected issuer")
+	case !c.Audience.contains(p.config.ClientID):
+		return nil, errors.New("oidc: id token: unexpected audience")
+	case len(c.Audience) > 1 && c.AuthorizedParty != p.config.ClientID:
+		return nil, errors.New("oidc: id token: unexpected authorized party")
+	case c.ExpiresAt == 0 || now.After(time.Unix(c.ExpiresAt, 0).Add(leeway)):
+		return nil, errors.New("oidc: id token: expired")
+	case subtle.ConstantTimeCompare([]byte(c.Nonce), []byte(nonce)) != 1:
+		return nil, errors.New("oidc: id token: unexpected nonce")
+	case c.Subject == "":
+		return nil, errors.New("oidc: id token: no subject")
+	}
+
+	return &Identity{
+		Subject:           c.Subject,
+		Email:             c.Email,
+		EmailVerified:     c.EmailVerified,
+		Name:              c.Name,
+		PreferredUsername: c.PreferredUsername,
+	}, nil
+}
+
+// keyFunc picks the provider key named by the kid header; the token must use
+// the algorithm of that key.
+func (p *Provider) keyFunc(ctx context.Context) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+
+		k, err := p.key(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+
+		if token.Method.Alg() != k.alg {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+
+		return k.public, nil
+	}
+}
+
+func (p *Provider) key(ctx context.Context, kid string) (key, error) {
+	p.mu.Lock()
+	k, ok := p.keys[kid]
+	p.mu.Unlock()
+
+	if ok {
+		return k, nil
+	}
+
+	if err := p.fetchKeys(ctx); err != nil {
+		return key{}, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if k, ok = p.keys[kid]; !ok {
+		return key{}, fmt.Errorf("unknown key id: %q", kid)
+	}
+
+	return k, nil
+}
+
+// fetchKeys replaces the keys with the RSA and Ed25519 signing keys of the provider.
+func (p *Provider) fetchKeys(ctx context.Context) error {
+	m, err := p.discover(ctx)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.JWKSURI, nil)
+	if err != nil {
+		return fmt.Errorf("oidc: jwks request: %w", err)
+	}
+
+	var set jwtauth.JWKS
+	if err = p.do(req, &set); err != nil {
+		return err
+	}
+
+	keys := make(map[string]key, len(set.Keys))
+
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		pub, err := jwk.PublicKey()
+		if err != nil {
+			continue
+		}
+
+		alg := jwk.Alg
+		if alg == "" {
+			alg = jwtauth.RS256
+			if jwk.Kty == "OKP" {
+				alg = jwtauth.EdDSA
+			}
+		}
+
+		keys[jwk.Kid] = key{alg: alg, public: pub}
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.mu.Unlock()
+
+	return nil
+}
+
+// discover fetches the discovery document once; its issuer must be the configured one.
+func (p *Provider) discover(ctx context.Context) (*metadata, error) {
+	p.mu.Lock()
+	m := p.meta
+	p.mu.Unlock()
+
+	if m != nil {
+		return m, nil
+	}
+
+	issuer := strings.TrimSuffix(p.config.Issuer, "/")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: discovery request: %w", err)
+	}
+
+	m = new(metadata)
+	if err = p.do(req, m); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSuffix(m.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("oidc: discovery: issuer %q does not match %q", m.Issuer, p.config.Issuer)
+	}
+
+	p.mu.Lock()
+	p.meta = m
+	p.mu.Unlock()
+
+	return m, nil
+}
+
+// do sends req and decodes the JSON response into v.
+func (p *Provider) do(req *http.Request, v any) error {
+	res, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("oidc: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("oidc: %s %s: %s", req.Method, req.URL.Path, res.Status)
+	}
+
+	if err = json.NewDecoder(io.LimitReader(res.Body, maxBody)).Decode(v); err != nil {
+		return fmt.Errorf("oidc: %s %s: %w", req.Method, req.URL.Path, err)
+	}
+
+	return nil
+}
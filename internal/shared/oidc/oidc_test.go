@@ -0,0 +1,98 @@
+package oidc_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/oidc"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/oidc/oidctest"
+)
+
+const (
+	redirectURL = "http://localhost/callback"
+	verifier    = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+)
+
+var user = oidc.Identity{Subject: "42", Email: "kamil@kamilov.com", EmailVerified: true, PreferredUsername: "kamil"}
+
+// authorize runs the sign in at the stub and returns the code it redirects back with.
+func authorize(t *testing.T, p *oidc.Provider, state, nonce string) string {
+	t.Helper()
+
+	authURL, err := p.AuthCodeURL(context.Background(), state, nonce, verifier)
+	require.NoError(t, err)
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+
+	res, err := client.Get(authURL)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	require.Equal(t, http.StatusFound, res.StatusCode)
+
+	loc, err := url.Parse(res.Header.Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, state, loc.Query().Get("state"))
+
+	return loc.Query().Get("code")
+}
+
+func TestExchange(t *testing.T) {
+	stub := oidctest.New("client")
+	defer stub.Close()
+
+	stub.SetUser(user)
+
+	p := oidc.New(stub.Config(redirectURL), nil)
+	code := authorize(t, p, "state", "nonce")
+
+	got, err := p.Exchange(context.Background(), code, verifier, "nonce", time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, &user, got)
+
+	_, err = p.Exchange(context.Background(), code, verifier, "nonce", time.Now())
+	assert.Error(t, err, "codes are single use")
+}
+
+func TestExchangeRejects(t *testing.T) {
+	stub := oidctest.New("client")
+	defer stub.Close()
+
+	stub.SetUser(user)
+
+	p := oidc.New(stub.Config(redirectURL), nil)
+
+	_, err := p.Exchange(context.Background(), authorize(t, p, "s", "nonce"), "another-verifier", "nonce", time.Now())
+	assert.Error(t, err, "wrong verifier")
+
+	_, err = p.Exchange(context.Background(), authorize(t, p, "s", "nonce"), verifier, "another-nonce", time.Now())
+	assert.Error(t, err, "wrong nonce")
+
+	_, err = p.Exchange(context.Background(), authorize(t, p, "s", "nonce"), verifier, "nonce", time.Now().Add(2*time.Hour))
+	assert.Error(t, err, "expired id token")
+
+	other := oidc.New(oidc.Config{Issuer: stub.URL, ClientID: "another-client", RedirectURL: redirectURL}, nil)
+	_, err = other.Exchange(context.Background(), authorize(t, p, "s", "nonce"), verifier, "nonce", time.Now())
+	assert.Error(t, err, "wrong client")
+}
+
+func TestDiscoveryIssuer(t *testing.T) {
+	stub := oidctest.New("client")
+	defer stub.Close()
+
+	cfg := stub.Config(redirectURL)
+	cfg.Issuer += "/tenant"
+
+	_, err := oidc.New(cfg, nil).AuthCodeURL(context.Background(), "state", "nonce", verifier)
+	assert.Error(t, err)
+}
+
+func TestChallenge(t *testing.T) {
+	// RFC 7636 appendix B.
+	assert.Equal(t, "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", oidc.Challenge(verifier))
+}
@@ -0,0 +1,191 @@
+// Package oidctest provides a stub OpenID Connect provider for tests.
+package oidctest
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/jwtauth"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/oidc"
+)
+
+const kid = "oidctest"
+
+// grant is an authorization code waiting to be exchanged.
+type grant struct {
+	redirectURI string
+	challenge   string
+	nonce       string
+	user        oidc.Identity
+}
+
+// Provider is a stub provider that signs in User without asking, for the
+// client ClientID.
+type Provider struct {
+	*httptest.Server
+	ClientID string
+
+	mu     sync.Mutex
+	user   oidc.Identity
+	key    ed25519.PrivateKey
+	grants map[string]grant
+}
+
+// New starts a provider for clientID; Close it when done.
+func New(clientID string) *Provider {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+
+	p := &Provider{ClientID: clientID, key: key, grants: map[string]grant{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("/authorize", p.authorize)
+	mux.HandleFunc("/token", p.token)
+	mux.HandleFunc("/jwks", p.jwks)
+	p.Server = httptest.NewServer(mux)
+
+	return p
+}
+
+// Config returns the client config for the provider, calling back redirectURL.
+func (p *Provider) Config(redirectURL string) oidc.Config {
+	return oidc.Config{Issuer: p.URL, ClientID: p.ClientID, RedirectURL: redirectURL, Scopes: []string{"email", "profile"}}
+}
+
+// SetUser sets who signs in next.
+func (p *Provider) SetUser(u oidc.Identity) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.user = u
+}
+
+// IDToken returns an ID token for u signed by the provider.
+func (p *Provider) IDToken(u oidc.Identity, audience, nonce string, expiresAt time.Time) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{
+		"iss":                p.URL,
+		"sub":                u.Subject,
+		"aud":                audience,
+		"exp":                expiresAt.Unix(),
+		"iat":                time.Now().Unix(),
+		"nonce":              nonce,
+		"email":              u.Email,
+		"email_verified":     u.EmailVerified,
+		"name":               u.Name,
+		"preferred_username": u.PreferredUsername,
+	})
+	token.Header["kid"] = kid
+
+	signed, err := token.SignedString(p.key)
+	if err != nil {
+		panic(err)
+	}
+
+	return signed
+}
+
+func (p *Provider) discovery(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 p.URL,
+		"authorization_endpoint": p.URL + "/authorize",
+		"token_endpoint":         p.URL + "/token",
+		"jwks_uri":               p.URL + "/jwks",
+	})
+}
+
+func (p *Provider) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	if q.Get("client_id") != p.ClientID || q.Get("response_type") != "code" || q.Get("code_challenge_method") != "S256" {
+		http.Error(w, "invalid_request", http.StatusBadRequest)
+
+		return
+	}
+
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil {
+		http.Error(w, "invalid_request", http.StatusBadRequest)
+
+		return
+	}
+
+	code := randomString()
+
+	p.mu.Lock()
+	p.grants[code] = grant{redirectURI: q.Get("redirect_uri"), challenge: q.Get("code_challenge"), nonce: q.Get("nonce"), user: p.user}
+	p.mu.Unlock()
+
+	rq := redirect.Query()
+	rq.Set("code", code)
+	rq.Set("state", q.Get("state"))
+	redirect.RawQuery = rq.Encode()
+
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (p *Provider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+
+		return
+	}
+
+	code := r.PostForm.Get("code")
+
+	p.mu.Lock()
+	g, ok := p.grants[code]
+	delete(p.grants, code)
+	p.mu.Unlock()
+
+	if !ok || r.PostForm.Get("grant_type") != "authorization_code" || r.PostForm.Get("client_id") != p.ClientID ||
+		r.PostForm.Get("redirect_uri") != g.redirectURI || oidc.Challenge(r.PostForm.Get("code_verifier")) != g.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     p.IDToken(g.user, p.ClientID, g.nonce, time.Now().Add(time.Hour)),
+	})
+}
+
+func (p *Provider) jwks(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, jwtauth.JWKS{Keys: []jwtauth.JWK{{
+		Kty: "OKP",
+		Kid: kid,
+		Use: "sig",
+		Alg: jwtauth.EdDSA,
+		Crv: "Ed25519",
+		X:   base64.RawURLEncoding.EncodeToString(p.key.Public().(ed25519.PublicKey)),
+	}}})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func randomString() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
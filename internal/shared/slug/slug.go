@@ -0,0 +1,71 @@
+package slug
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// MaxLength keeps generated slugs well inside the posts.slug column.
+const MaxLength = 200
+
+var transliterations = map[rune]string{
+	'ç': "c", 'ğ': "g", 'ı': "i", 'İ': "i", 'ö': "o", 'ş': "s", 'ü': "u",
+	'Ç': "c", 'Ğ': "g", 'Ö': "o", 'Ş': "s", 'Ü': "u",
+	'â': "a", 'î': "i", 'û': "u", 'Â': "a", 'Î': "i", 'Û': "u",
+	'á': "a", 'à': "a", 'ä': "a", 'ã': "a", 'å': "a", 'Á': "a", 'À': "a", 'Ä': "a", 'Ã': "a", 'Å': "a",
+	'é': "e", 'è': "e", 'ê': "e", 'ë': "e", 'É': "e", 'È': "e", 'Ê': "e", 'Ë': "e",
+	'í': "i", 'ì': "i", 'ï': "i", 'Í': "i", 'Ì': "i", 'Ï': "i",
+	'ó': "o", 'ò': "o", 'ô': "o", 'õ': "o", 'ø': "o", 'Ó': "o", 'Ò': "o", 'Ô': "o", 'Õ': "o", 'Ø': "o",
+	'ú': "u", 'ù': "u", 'Ú': "u", 'Ù': "u",
+	'ñ': "n", 'Ñ': "n", 'ß': "ss", 'æ': "ae", 'Æ': "ae", 'œ': "oe", 'Œ': "oe",
+}
+
+// Make turns s into a lowercase, hyphen separated slug. Non-ASCII letters are
+// transliterated where possible and dropped otherwise.
+func Make(s string) string {
+	var b strings.Builder
+
+	dash := false
+
+	for _, r := range s {
+		if t, ok := transliterations[r]; ok {
+			b.WriteString(t)
+
+			dash = false
+
+			continue
+		}
+
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(unicode.ToLower(r))
+
+			dash = false
+
+			continue
+		}
+
+		if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+
+			dash = true
+		}
+	}
+
+	out := b.String()
+	if len(out) > MaxLength {
+		out = out[:MaxLength]
+	}
+
+	return strings.Trim(out, "-")
+}
+
+// WithSuffix returns the n-th candidate for base, used to resolve collisions:
+// base, base-2, base-3, ...
+func WithSuffix(base string, n int) string {
+	if n < 2 {
+		return base
+	}
+
+	return base + "-" + strconv.Itoa(n)
+}
@@ -0,0 +1,52 @@
+package slug_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/slug"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "plain title", in: "Hello World", want: "hello-world"},
+		{name: "turkish characters", in: "Çalışkan Öğrenci Şükrü İle Ilgaz", want: "caliskan-ogrenci-sukru-ile-ilgaz"},
+		{name: "punctuation and spaces", in: "  Go 1.21: what's new?!  ", want: "go-1-21-what-s-new"},
+		{name: "accents", in: "Crème brûlée à la française", want: "creme-brulee-a-la-francaise"},
+		{name: "untransliterable only", in: "日本語", want: ""},
+		{name: "empty", in: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := slug.Make(tt.in); got != tt.want {
+				t.Errorf("Make(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMakeMaxLength(t *testing.T) {
+	got := slug.Make(strings.Repeat("ab ", 200))
+	if len(got) > slug.MaxLength {
+		t.Errorf("len = %d, want <= %d", len(got), slug.MaxLength)
+	}
+
+	if strings.HasSuffix(got, "-") {
+		t.Errorf("slug %q ends with a hyphen", got)
+	}
+}
+
+func TestWithSuffix(t *testing.T) {
+	if got := slug.WithSuffix("hello", 1); got != "hello" {
+		t.Errorf("WithSuffix(hello, 1) = %q", got)
+	}
+
+	if got := slug.WithSuffix("hello", 3); got != "hello-3" {
+		t.Errorf("WithSuffix(hello, 3) = %q", got)
+	}
+}
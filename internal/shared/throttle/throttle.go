@@ -0,0 +1,52 @@
+package throttle
+
+import "time"
+
+// Policy locks a key out once it has Threshold failures, for Base at first
+// and twice as long with every further failure, up to Max. Failures older
+// than Window are forgotten.
+type Policy struct {
+	Threshold int
+	Base      time.Duration
+	Max       time.Duration
+	Window    time.Duration
+}
+
+// Delay returns how long a key with failures failures is locked out.
+func (p Policy) Delay(failures int) time.Duration {
+	if p.Threshold <= 0 || failures < p.Threshold {
+		return 0
+	}
+
+	d := p.Base
+	for i := p.Threshold; i < failures && d < p.Max; i++ {
+		d *= 2
+	}
+
+	if d > p.Max {
+		return p.Max
+	}
+
+	return d
+}
+
+// Or returns p with its zero fields taken from def.
+func (p Policy) Or(def Policy) Policy {
+	if p.Threshold <= 0 {
+		p.Threshold = def.Threshold
+	}
+
+	if p.Base <= 0 {
+		p.Base = def.Base
+	}
+
+	if p.Max <= 0 {
+		p.Max = def.Max
+	}
+
+	if p.Window <= 0 {
+		p.Window = def.Window
+	}
+
+	return p
+}
@@ -0,0 +1,38 @@
+package throttle_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/throttle"
+)
+
+func TestDelay(t *testing.T) {
+	p := throttle.Policy{Threshold: 3, Base: time.Minute, Max: 10 * time.Minute}
+
+	cases := map[int]time.Duration{
+		0:   0,
+		2:   0,
+		3:   time.Minute,
+		4:   2 * time.Minute,
+		5:   4 * time.Minute,
+		6:   8 * time.Minute,
+		7:   10 * time.Minute,
+		100: 10 * time.Minute,
+	}
+
+	for failures, want := range cases {
+		assert.Equal(t, want, p.Delay(failures), "failures %d", failures)
+	}
+
+	assert.Zero(t, throttle.Policy{}.Delay(100))
+}
+
+func TestOr(t *testing.T) {
+	def := throttle.Policy{Threshold: 5, Base: time.Second, Max: time.Hour, Window: time.Hour}
+
+	assert.Equal(t, def, throttle.Policy{}.Or(def))
+	assert.Equal(t, 10, throttle.Policy{Threshold: 10}.Or(def).Threshold)
+}
@@ -0,0 +1,84 @@
+package totp_test
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/totp"
+)
+
+// secret is the SHA-1 key of the RFC 6238 test vectors.
+var secret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestCode(t *testing.T) {
+	// The RFC lists 8 digit codes; these are their last 6 digits.
+	cases := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+
+	for unix, want := range cases {
+		got, err := totp.Code(secret, totp.Step(time.Unix(unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, want, got, "time %d", unix)
+	}
+
+	_, err := totp.Code("not base32!", 1)
+	assert.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+
+	step, ok := totp.Validate(secret, "050471", now)
+	assert.True(t, ok)
+	assert.Equal(t, totp.Step(now), step)
+
+	prev, err := totp.Code(secret, totp.Step(now)-1)
+	require.NoError(t, err)
+
+	step, ok = totp.Validate(secret, prev, now)
+	assert.True(t, ok, "a code of the previous period should pass")
+	assert.Equal(t, totp.Step(now)-1, step)
+
+	old, err := totp.Code(secret, totp.Step(now)-2)
+	require.NoError(t, err)
+
+	_, ok = totp.Validate(secret, old, now)
+	assert.False(t, ok, "a code two periods old should fail")
+
+	_, ok = totp.Validate(secret, "50471", now)
+	assert.False(t, ok)
+}
+
+func TestNewSecret(t *testing.T) {
+	a, err := totp.NewSecret()
+	require.NoError(t, err)
+
+	b, err := totp.NewSecret()
+	require.NoError(t, err)
+
+	assert.NotEqual(t, a, b)
+	assert.Len(t, a, 32)
+}
+
+func TestURI(t *testing.T) {
+	u, err := url.Parse(totp.URI("mts-blog", "samil@samilov.com", "JBSWY3DPEHPK3PXP"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "otpauth", u.Scheme)
+	assert.Equal(t, "totp", u.Host)
+	assert.Equal(t, "/mts-blog:samil@samilov.com", u.Path)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", u.Query().Get("secret"))
+	assert.Equal(t, "mts-blog", u.Query().Get("issuer"))
+	assert.Equal(t, "6", u.Query().Get("digits"))
+}
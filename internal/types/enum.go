@@ -14,3 +14,95 @@ var (
 	Active  Status = "active"
 	Passive Status = "passive"
 )
+
+// PostState is the editorial workflow state of a post.
+type PostState string
+
+var (
+	Draft     PostState = "draft"
+	Scheduled PostState = "scheduled"
+	Published PostState = "published"
+	Archived  PostState = "archived"
+)
+
+// SearchType is the kind of content a search result points at.
+type SearchType string
+
+var (
+	SearchPost    SearchType = "post"
+	SearchComment SearchType = "comment"
+)
+
+// CommentState is the moderation state of a comment; only approved ones are public.
+type CommentState string
+
+var (
+	Pending  CommentState = "pending"
+	Approved CommentState = "approved"
+	Rejected CommentState = "rejected"
+	Spam     CommentState = "spam"
+)
+
+// ModerationPolicy decides which new comments skip the moderation queue.
+type ModerationPolicy string
+
+var (
+	// ModerateNone approves every comment.
+	ModerateNone ModerationPolicy = "none"
+	// ModerateTrusted approves the comments of users with enough approved comments.
+	ModerateTrusted ModerationPolicy = "trusted"
+	// ModerateAll holds every comment for a mod.
+	ModerateAll ModerationPolicy = "all"
+)
+
+// TokenPurpose is what a mailed user token can be used for.
+type TokenPurpose string
+
+var (
+	PasswordReset     TokenPurpose = "password-reset"
+	EmailVerification TokenPurpose = "email-verification"
+	// TwoFactorChallenge is handed out by a login waiting for a second factor.
+	TwoFactorChallenge TokenPurpose = "two-factor-challenge"
+)
+
+// LoginAttemptKind is what failed logins are counted against.
+type LoginAttemptKind string
+
+var (
+	LoginAccount LoginAttemptKind = "account"
+	LoginIP      LoginAttemptKind = "ip"
+)
+
+// Permission is an action a role can be granted, named resource:action.
+type Permission string
+
+var (
+	PostCreate Permission = "post:create"
+	// PostUpdate edits, publishes and restores the revisions of one's own posts.
+	PostUpdate Permission = "post:update"
+	// PostUpdateAny does what PostUpdate does to the posts of others.
+	PostUpdateAny Permission = "post:update-any"
+	// PostReadUnpublished shows drafts, scheduled and archived posts.
+	PostReadUnpublished Permission = "post:read-unpublished"
+	// PostDelete deletes, restores and purges posts and lists deleted ones.
+	PostDelete    Permission = "post:delete"
+	CommentCreate Permission = "comment:create"
+	// CommentModerate works the moderation queue and edits and deletes the comments of others.
+	CommentModerate Permission = "comment:moderate"
+	// CommentDelete restores and purges comments and lists deleted ones.
+	CommentDelete  Permission = "comment:delete"
+	TagManage      Permission = "tag:manage"
+	CategoryManage Permission = "category:manage"
+	UserRead       Permission = "user:read"
+	// UserManage creates, edits, suspends and deletes users and changes their roles.
+	UserManage Permission = "user:manage"
+	RoleManage Permission = "role:manage"
+)
+
+// Permissions are all the permissions, in the order they are listed.
+var Permissions = []Permission{
+	PostCreate, PostUpdate, PostUpdateAny, PostReadUnpublished, PostDelete,
+	CommentCreate, CommentModerate, CommentDelete,
+	TagManage, CategoryManage,
+	UserRead, UserManage, RoleManage,
+}
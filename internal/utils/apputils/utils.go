@@ -2,15 +2,15 @@ package apputils
 
 import (
 	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
-	"os"
 	"strconv"
-	"time"
 
-	"github.com/golang-jwt/jwt"
 	"golang.org/x/crypto/bcrypt"
 
-	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
 )
 
@@ -44,20 +44,21 @@ func InterfaceUnmarshal(in, out interface{}) error {
 	return nil
 }
 
-func CreateJWT(u *model.User) (string, error) {
-	claims := &jwt.MapClaims{
-		"expiresAt": time.Now().Add(48 * time.Hour),
-		"email":     u.Email,
-		"username":  u.Username,
-		"role":      u.Role,
-		"uid":       u.ID,
-		"status":    u.Status,
+// RandomToken returns an unguessable url safe token made of n random bytes.
+func RandomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", errorutils.New(errorutils.ErrUnexpected, err)
 	}
 
-	secret := os.Getenv("JWT_SECRET")
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken returns the hex sha256 of token, the form opaque tokens are stored in.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
 
-	return token.SignedString([]byte(secret))
+	return hex.EncodeToString(sum[:])
 }
 
 func EncryptPassword(password string) (string, error) {
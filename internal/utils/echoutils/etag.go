@@ -0,0 +1,26 @@
+package echoutils
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/etag"
+)
+
+// JSONWithETag sends i with the given ETag, or an empty 304 when the
+// request's If-None-Match already lists the tag.
+func JSONWithETag(c echo.Context, code int, tag string, i any) error {
+	c.Response().Header().Set(etag.HeaderETag, tag)
+
+	if inm := c.Request().Header.Get(etag.HeaderIfNoneMatch); inm != "" && etag.Match(inm, tag) {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	return c.JSON(code, i)
+}
+
+// IfMatch returns the request's If-Match header.
+func IfMatch(c echo.Context) string {
+	return c.Request().Header.Get(etag.HeaderIfMatch)
+}
@@ -13,10 +13,19 @@ const (
 	ErrCodeShortPassword        = "auth/short-password"
 	ErrCodeShortUsername        = "auth/short-username"
 	ErrCodeUserDisabled         = "auth/user-disabled"
+	ErrCodeLoginLocked          = "auth/login-locked"
+	ErrCodeTwoFactorRequired    = "auth/two-factor-required"
+	ErrCodeTwoFactorEnabled     = "auth/two-factor-enabled"
+	ErrCodeTwoFactorNotEnrolled = "auth/two-factor-not-enrolled"
+	ErrCodeInvalidTwoFactorCode = "auth/invalid-two-factor-code"
 	ErrCodeEmailNotFound        = "auth/email-not-found"
 	ErrCodeUsernameAlreadyTaken = "auth/username-taken"
 	ErrCodeUsernameRequired     = "auth/username-required"
 	ErrCodeWeakPassword         = "auth/weak-password"
+	ErrCodeRevokedToken         = "auth/revoked-token"
+	ErrCodeEmailNotVerified     = "auth/email-not-verified"
+	ErrCodeEmailAlreadyVerified = "auth/email-already-verified"
+	ErrCodeVerificationCooldown = "auth/verification-cooldown"
 )
 
 // Common Error Codes.
@@ -38,39 +47,165 @@ const (
 	ErrCodeURLInvalid           = "com/url-invalid"
 	ErrCodeURLRequired          = "com/url-required"
 	ErrCodeUserAgentReadFile    = "com/user-agent-read"
+	ErrCodePreconditionFailed   = "req/precondition-failed"
 )
 
 // User Error Codes.
 const (
-	ErrCodeUserCount    = "user/count-failed"
-	ErrCodeUserCreate   = "user/create-failed"
-	ErrCodeUserDelete   = "user/delete-failed"
-	ErrCodeUserRead     = "user/read-failed"
-	ErrCodeUserReads    = "user/reads-failed"
-	ErrCodeUserSearch   = "user/search-failed"
-	ErrCodeUserUpdate   = "user/update-failed"
-	ErrCodeUserNotFound = "user/user-not-found"
+	ErrCodeUserCount          = "user/count-failed"
+	ErrCodeUserCreate         = "user/create-failed"
+	ErrCodeUserDelete         = "user/delete-failed"
+	ErrCodeUserRead           = "user/read-failed"
+	ErrCodeUserReads          = "user/reads-failed"
+	ErrCodeUserSearch         = "user/search-failed"
+	ErrCodeUserUpdate         = "user/update-failed"
+	ErrCodeUserNotFound       = "user/user-not-found"
+	ErrCodeUserPurge          = "user/purge-failed"
+	ErrCodeUserRestore        = "user/restore-failed"
+	ErrCodeUserPasswordUpdate = "user/password-update-failed"
+	ErrCodeUserRoleUpdate     = "user/role-update-failed"
+	ErrCodeUserStatusUpdate   = "user/status-update-failed"
+	ErrCodeLastAdmin          = "user/last-admin"
 )
 
 // Post Error Codes.
 const (
-	ErrCodePostCount    = "post/count-failed"
-	ErrCodePostCreate   = "post/create-failed"
-	ErrCodePostDelete   = "post/delete-failed"
-	ErrCodePostRead     = "post/read-failed"
-	ErrCodePostReads    = "post/reads-failed"
-	ErrCodePostUpdate   = "post/update-failed"
-	ErrCodePostNotFound = "post/not-found"
+	ErrCodePostCount            = "post/count-failed"
+	ErrCodePostCreate           = "post/create-failed"
+	ErrCodePostDelete           = "post/delete-failed"
+	ErrCodePostRead             = "post/read-failed"
+	ErrCodePostReads            = "post/reads-failed"
+	ErrCodePostUpdate           = "post/update-failed"
+	ErrCodePostNotFound         = "post/not-found"
+	ErrCodePostPurge            = "post/purge-failed"
+	ErrCodePostRestore          = "post/restore-failed"
+	ErrCodePostState            = "post/invalid-state"
+	ErrCodePostPublish          = "post/invalid-publish-at"
+	ErrCodePostSlug             = "post/invalid-slug"
+	ErrCodePostSlugUsed         = "post/slug-taken"
+	ErrCodePostRevisionCreate   = "post/revision-create-failed"
+	ErrCodePostRevisions        = "post/revisions-failed"
+	ErrCodePostRevisionNotFound = "post/revision-not-found"
+	ErrCodePostDiffTooLong      = "post/diff-too-long"
 )
 
 // Comment Error Codes.
 const (
-	ErrCodeCommentCount    = "comment/count-failed"
-	ErrCodeCommentCreate   = "comment/create-failed"
-	ErrCodeCommentDelete   = "comment/delete-failed"
-	ErrCodeCommentRead     = "comment/read-failed"
-	ErrCodeCommentReads    = "comment/reads-failed"
-	ErrCodeCommentNotFound = "comment/not-found"
+	ErrCodeCommentCount     = "comment/count-failed"
+	ErrCodeCommentCreate    = "comment/create-failed"
+	ErrCodeCommentDelete    = "comment/delete-failed"
+	ErrCodeCommentRead      = "comment/read-failed"
+	ErrCodeCommentReads     = "comment/reads-failed"
+	ErrCodeCommentNotFound  = "comment/not-found"
+	ErrCodeCommentPurge     = "comment/purge-failed"
+	ErrCodeCommentRestore   = "comment/restore-failed"
+	ErrCodeCommentParent    = "comment/invalid-parent"
+	ErrCodeCommentDepth     = "comment/too-deep"
+	ErrCodeCommentUpdate    = "comment/update-failed"
+	ErrCodeCommentRevisions = "comment/revisions-failed"
+	ErrCodeCommentModerate  = "comment/moderate-failed"
+)
+
+// Tag Error Codes.
+const (
+	ErrCodeTagCreate        = "tag/create-failed"
+	ErrCodeTagDelete        = "tag/delete-failed"
+	ErrCodeTagRead          = "tag/read-failed"
+	ErrCodeTagReads         = "tag/reads-failed"
+	ErrCodeTagUpdate        = "tag/update-failed"
+	ErrCodeTagNotFound      = "tag/not-found"
+	ErrCodeTagAlreadyExists = "tag/already-exists"
+)
+
+// Category Error Codes.
+const (
+	ErrCodeCategoryCreate        = "category/create-failed"
+	ErrCodeCategoryDelete        = "category/delete-failed"
+	ErrCodeCategoryRead          = "category/read-failed"
+	ErrCodeCategoryReads         = "category/reads-failed"
+	ErrCodeCategoryUpdate        = "category/update-failed"
+	ErrCodeCategoryNotFound      = "category/not-found"
+	ErrCodeCategoryAlreadyExists = "category/already-exists"
+	ErrCodeCategoryParent        = "category/invalid-parent"
+)
+
+// Search Error Codes.
+const (
+	ErrCodeSearch         = "search/failed"
+	ErrCodeSearchLanguage = "search/invalid-language"
+)
+
+// Session Error Codes.
+const (
+	ErrCodeSessionCreate = "session/create-failed"
+	ErrCodeSessionRead   = "session/read-failed"
+	ErrCodeSessionRevoke = "session/revoke-failed"
+)
+
+// UserToken Error Codes.
+const (
+	ErrCodeUserTokenCreate = "user-token/create-failed"
+	ErrCodeUserTokenRead   = "user-token/read-failed"
+	ErrCodeUserTokenUpdate = "user-token/update-failed"
+)
+
+// Mail Error Codes.
+const (
+	ErrCodeMailSend = "mail/send-failed"
+)
+
+// LoginAttempt Error Codes.
+const (
+	ErrCodeLoginAttemptRead     = "login-attempt/read-failed"
+	ErrCodeLoginAttemptUpdate   = "login-attempt/update-failed"
+	ErrCodeLoginAttemptDelete   = "login-attempt/delete-failed"
+	ErrCodeLoginAttemptNotFound = "login-attempt/not-found"
+)
+
+// RecoveryCode Error Codes.
+const (
+	ErrCodeRecoveryCodeCreate = "recovery-code/create-failed"
+	ErrCodeRecoveryCodeUpdate = "recovery-code/update-failed"
+)
+
+// APIKey Error Codes.
+const (
+	ErrCodeAPIKeyCreate   = "api-key/create-failed"
+	ErrCodeAPIKeyRead     = "api-key/read-failed"
+	ErrCodeAPIKeyUpdate   = "api-key/update-failed"
+	ErrCodeAPIKeyNotFound = "api-key/not-found"
+	ErrCodeAPIKeyScope    = "api-key/scope"
+)
+
+// OIDC Error Codes.
+const (
+	ErrCodeOIDCProviderNotFound  = "oidc/provider-not-found"
+	ErrCodeOIDCInvalidState      = "oidc/invalid-state"
+	ErrCodeOIDCLoginFailed       = "oidc/login-failed"
+	ErrCodeOIDCEmailUnverified   = "oidc/email-unverified"
+	ErrCodeOIDCAccountUnverified = "oidc/account-unverified"
+	ErrCodeOIDCLoginCreate       = "oidc/create-failed"
+	ErrCodeOIDCLoginUpdate       = "oidc/update-failed"
+)
+
+// Identity Error Codes.
+const (
+	ErrCodeIdentityCreate   = "identity/create-failed"
+	ErrCodeIdentityRead     = "identity/read-failed"
+	ErrCodeIdentityNotFound = "identity/not-found"
+)
+
+// Role Error Codes.
+const (
+	ErrCodeRoleCreate        = "role/create-failed"
+	ErrCodeRoleRead          = "role/read-failed"
+	ErrCodeRoleReads         = "role/reads-failed"
+	ErrCodeRoleUpdate        = "role/update-failed"
+	ErrCodeRoleDelete        = "role/delete-failed"
+	ErrCodeRoleNotFound      = "role/not-found"
+	ErrCodeRoleAlreadyExists = "role/already-exists"
+	ErrCodeRoleBuiltIn       = "role/built-in"
+	ErrCodeRoleInUse         = "role/in-use"
 )
 
 // Unorganized Error Codes.
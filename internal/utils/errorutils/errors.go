@@ -21,6 +21,16 @@ var (
 	ErrEmailNotFound        = errors.New("email not found")
 	ErrUsernameAlreadyTaken = errors.New("username already taken")
 	ErrUsernameRequired     = errors.New("username is required")
+	ErrRevokedToken         = errors.New("token has been revoked")
+	ErrEmailNotVerified     = errors.New("email not verified")
+	ErrEmailAlreadyVerified = errors.New("email already verified")
+	ErrVerificationCooldown = errors.New("verification mail sent recently")
+	ErrUserDisabled         = errors.New("user is suspended")
+	ErrLoginLocked          = errors.New("too many failed logins, try again later")
+	ErrTwoFactorRequired    = errors.New("two-factor authentication is required for this role")
+	ErrTwoFactorEnabled     = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnrolled = errors.New("two-factor authentication is not enrolled")
+	ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
 )
 
 // Common Errors.
@@ -36,39 +46,165 @@ var (
 	ErrLongPaginationSize  = errors.New("size should be less than 100")
 	ErrShortPaginationSize = errors.New("size should be more than 1")
 	ErrUnexpected          = errors.New("unexpected error")
+	ErrPreconditionFailed  = errors.New("resource was changed since it was read")
 )
 
 // User Errors.
 var (
-	ErrUserCount    = errors.New("user count failed")
-	ErrUserCreate   = errors.New("user create failed")
-	ErrUserDelete   = errors.New("user delete failed")
-	ErrUserRead     = errors.New("user read failed")
-	ErrUserReads    = errors.New("user reads failed")
-	ErrUserSearch   = errors.New("user search failed")
-	ErrUserUpdate   = errors.New("user update failed")
-	ErrUserNotFound = errors.New("user not found")
+	ErrUserCount          = errors.New("user count failed")
+	ErrUserCreate         = errors.New("user create failed")
+	ErrUserDelete         = errors.New("user delete failed")
+	ErrUserRead           = errors.New("user read failed")
+	ErrUserReads          = errors.New("user reads failed")
+	ErrUserSearch         = errors.New("user search failed")
+	ErrUserUpdate         = errors.New("user update failed")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrUserPurge          = errors.New("user purge failed")
+	ErrUserRestore        = errors.New("user restore failed")
+	ErrUserPasswordUpdate = errors.New("user password update failed")
+	ErrUserRoleUpdate     = errors.New("user role update failed")
+	ErrUserStatusUpdate   = errors.New("user status update failed")
+	ErrLastAdmin          = errors.New("the last admin can not be demoted, suspended or deleted")
 )
 
 // Post Errors.
 var (
-	ErrPostCount    = errors.New("post count failed")
-	ErrPostCreate   = errors.New("post create failed")
-	ErrPostDelete   = errors.New("post delete failed")
-	ErrPostRead     = errors.New("post read failed")
-	ErrPostReads    = errors.New("post reads failed")
-	ErrPostUpdate   = errors.New("post update failed")
-	ErrPostNotFound = errors.New("post not found")
+	ErrPostCount            = errors.New("post count failed")
+	ErrPostCreate           = errors.New("post create failed")
+	ErrPostDelete           = errors.New("post delete failed")
+	ErrPostRead             = errors.New("post read failed")
+	ErrPostReads            = errors.New("post reads failed")
+	ErrPostUpdate           = errors.New("post update failed")
+	ErrPostNotFound         = errors.New("post not found")
+	ErrPostPurge            = errors.New("post purge failed")
+	ErrPostRestore          = errors.New("post restore failed")
+	ErrPostState            = errors.New("post state change is not allowed")
+	ErrPostPublish          = errors.New("scheduled posts need a future publish date")
+	ErrPostSlug             = errors.New("post slug is invalid")
+	ErrPostSlugUsed         = errors.New("post slug is already taken")
+	ErrPostRevisionCreate   = errors.New("post revision create failed")
+	ErrPostRevisions        = errors.New("post revisions read failed")
+	ErrPostRevisionNotFound = errors.New("post revision not found")
+	ErrPostDiffTooLong      = errors.New("post versions are too long to diff")
 )
 
 // Comment Errors.
 var (
-	ErrCommentCount    = errors.New("comment count failed")
-	ErrCommentCreate   = errors.New("comment create failed")
-	ErrCommentDelete   = errors.New("comment delete failed")
-	ErrCommentRead     = errors.New("comment read failed")
-	ErrCommentReads    = errors.New("comment reads failed")
-	ErrCommentNotFound = errors.New("comment not found")
+	ErrCommentCount     = errors.New("comment count failed")
+	ErrCommentCreate    = errors.New("comment create failed")
+	ErrCommentDelete    = errors.New("comment delete failed")
+	ErrCommentRead      = errors.New("comment read failed")
+	ErrCommentReads     = errors.New("comment reads failed")
+	ErrCommentNotFound  = errors.New("comment not found")
+	ErrCommentPurge     = errors.New("comment purge failed")
+	ErrCommentRestore   = errors.New("comment restore failed")
+	ErrCommentParent    = errors.New("parent comment belongs to another post")
+	ErrCommentDepth     = errors.New("comment replies are nested too deep")
+	ErrCommentUpdate    = errors.New("comment update failed")
+	ErrCommentRevisions = errors.New("comment revisions read failed")
+	ErrCommentModerate  = errors.New("comment moderation failed")
+)
+
+// Tag Errors.
+var (
+	ErrTagCreate        = errors.New("tag create failed")
+	ErrTagDelete        = errors.New("tag delete failed")
+	ErrTagRead          = errors.New("tag read failed")
+	ErrTagReads         = errors.New("tag reads failed")
+	ErrTagUpdate        = errors.New("tag update failed")
+	ErrTagNotFound      = errors.New("tag not found")
+	ErrTagAlreadyExists = errors.New("tag already exists")
+)
+
+// Category Errors.
+var (
+	ErrCategoryCreate        = errors.New("category create failed")
+	ErrCategoryDelete        = errors.New("category delete failed")
+	ErrCategoryRead          = errors.New("category read failed")
+	ErrCategoryReads         = errors.New("category reads failed")
+	ErrCategoryUpdate        = errors.New("category update failed")
+	ErrCategoryNotFound      = errors.New("category not found")
+	ErrCategoryAlreadyExists = errors.New("category already exists")
+	ErrCategoryParent        = errors.New("category can not be nested under itself")
+)
+
+// Search Errors.
+var (
+	ErrSearch         = errors.New("search failed")
+	ErrSearchLanguage = errors.New("unknown text search language")
+)
+
+// Session Errors.
+var (
+	ErrSessionCreate = errors.New("session create failed")
+	ErrSessionRead   = errors.New("session read failed")
+	ErrSessionRevoke = errors.New("session revoke failed")
+)
+
+// UserToken Errors.
+var (
+	ErrUserTokenCreate = errors.New("user token create failed")
+	ErrUserTokenRead   = errors.New("user token read failed")
+	ErrUserTokenUpdate = errors.New("user token update failed")
+)
+
+// Mail Errors.
+var (
+	ErrMailSend = errors.New("mail send failed")
+)
+
+// LoginAttempt Errors.
+var (
+	ErrLoginAttemptRead     = errors.New("login attempt read failed")
+	ErrLoginAttemptUpdate   = errors.New("login attempt update failed")
+	ErrLoginAttemptDelete   = errors.New("login attempt delete failed")
+	ErrLoginAttemptNotFound = errors.New("login attempt not found")
+)
+
+// RecoveryCode Errors.
+var (
+	ErrRecoveryCodeCreate = errors.New("recovery code create failed")
+	ErrRecoveryCodeUpdate = errors.New("recovery code update failed")
+)
+
+// APIKey Errors.
+var (
+	ErrAPIKeyCreate   = errors.New("api key create failed")
+	ErrAPIKeyRead     = errors.New("api key read failed")
+	ErrAPIKeyUpdate   = errors.New("api key update failed")
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	ErrAPIKeyScope    = errors.New("api key lacks the scope for this request")
+)
+
+// OIDC Errors.
+var (
+	ErrOIDCProviderNotFound  = errors.New("sign in provider not found")
+	ErrOIDCInvalidState      = errors.New("sign in request is invalid or expired")
+	ErrOIDCLoginFailed       = errors.New("sign in with the provider failed")
+	ErrOIDCEmailUnverified   = errors.New("the provider has not verified the email")
+	ErrOIDCAccountUnverified = errors.New("verify the email of your account before signing in with a provider")
+	ErrOIDCLoginCreate       = errors.New("sign in request create failed")
+	ErrOIDCLoginUpdate       = errors.New("sign in request update failed")
+)
+
+// Identity Errors.
+var (
+	ErrIdentityCreate   = errors.New("identity create failed")
+	ErrIdentityRead     = errors.New("identity read failed")
+	ErrIdentityNotFound = errors.New("identity not found")
+)
+
+// Role Errors.
+var (
+	ErrRoleCreate        = errors.New("role create failed")
+	ErrRoleRead          = errors.New("role read failed")
+	ErrRoleReads         = errors.New("role reads failed")
+	ErrRoleUpdate        = errors.New("role update failed")
+	ErrRoleDelete        = errors.New("role delete failed")
+	ErrRoleNotFound      = errors.New("role not found")
+	ErrRoleAlreadyExists = errors.New("role already exists")
+	ErrRoleBuiltIn       = errors.New("built-in roles cannot be changed this way")
+	ErrRoleInUse         = errors.New("role is still given to users")
 )
 
 // Unorganized Errors.
@@ -99,6 +235,16 @@ var errorCodes = map[error]string{
 	ErrEmailNotFound:        ErrCodeEmailNotFound,
 	ErrUsernameAlreadyTaken: ErrCodeUsernameAlreadyTaken,
 	ErrUsernameRequired:     ErrCodeUsernameRequired,
+	ErrRevokedToken:         ErrCodeRevokedToken,
+	ErrEmailNotVerified:     ErrCodeEmailNotVerified,
+	ErrEmailAlreadyVerified: ErrCodeEmailAlreadyVerified,
+	ErrVerificationCooldown: ErrCodeVerificationCooldown,
+	ErrUserDisabled:         ErrCodeUserDisabled,
+	ErrLoginLocked:          ErrCodeLoginLocked,
+	ErrTwoFactorRequired:    ErrCodeTwoFactorRequired,
+	ErrTwoFactorEnabled:     ErrCodeTwoFactorEnabled,
+	ErrTwoFactorNotEnrolled: ErrCodeTwoFactorNotEnrolled,
+	ErrInvalidTwoFactorCode: ErrCodeInvalidTwoFactorCode,
 
 	// Common
 	ErrBadRequest:          ErrCodeBadRequest,
@@ -111,32 +257,134 @@ var errorCodes = map[error]string{
 	ErrJSONUnmarshal:       ErrCodeJSONUnmarshal,
 	ErrLongPaginationSize:  ErrCodeLongPaginationSize,
 	ErrShortPaginationSize: ErrCodeShortPaginationSize,
+	ErrPreconditionFailed:  ErrCodePreconditionFailed,
 
 	// Users
-	ErrUserCount:  ErrCodeUserCount,
-	ErrUserCreate: ErrCodeUserCreate,
-	ErrUserDelete: ErrCodeUserDelete,
-	ErrUserRead:   ErrCodeUserRead,
-	ErrUserReads:  ErrCodeUserReads,
-	ErrUserSearch: ErrCodeUserSearch,
-	ErrUserUpdate: ErrCodeUserUpdate,
+	ErrUserCount:          ErrCodeUserCount,
+	ErrUserCreate:         ErrCodeUserCreate,
+	ErrUserDelete:         ErrCodeUserDelete,
+	ErrUserRead:           ErrCodeUserRead,
+	ErrUserReads:          ErrCodeUserReads,
+	ErrUserSearch:         ErrCodeUserSearch,
+	ErrUserUpdate:         ErrCodeUserUpdate,
+	ErrUserPurge:          ErrCodeUserPurge,
+	ErrUserRestore:        ErrCodeUserRestore,
+	ErrUserPasswordUpdate: ErrCodeUserPasswordUpdate,
+	ErrUserRoleUpdate:     ErrCodeUserRoleUpdate,
+	ErrUserStatusUpdate:   ErrCodeUserStatusUpdate,
+	ErrLastAdmin:          ErrCodeLastAdmin,
 
 	// Posts
-	ErrPostCount:    ErrCodePostCount,
-	ErrPostCreate:   ErrCodePostCreate,
-	ErrPostDelete:   ErrCodePostDelete,
-	ErrPostRead:     ErrCodePostRead,
-	ErrPostReads:    ErrCodePostReads,
-	ErrPostUpdate:   ErrCodePostUpdate,
-	ErrPostNotFound: ErrCodePostNotFound,
+	ErrPostCount:            ErrCodePostCount,
+	ErrPostCreate:           ErrCodePostCreate,
+	ErrPostDelete:           ErrCodePostDelete,
+	ErrPostRead:             ErrCodePostRead,
+	ErrPostReads:            ErrCodePostReads,
+	ErrPostUpdate:           ErrCodePostUpdate,
+	ErrPostNotFound:         ErrCodePostNotFound,
+	ErrPostPurge:            ErrCodePostPurge,
+	ErrPostRestore:          ErrCodePostRestore,
+	ErrPostState:            ErrCodePostState,
+	ErrPostPublish:          ErrCodePostPublish,
+	ErrPostSlug:             ErrCodePostSlug,
+	ErrPostSlugUsed:         ErrCodePostSlugUsed,
+	ErrPostRevisionCreate:   ErrCodePostRevisionCreate,
+	ErrPostRevisions:        ErrCodePostRevisions,
+	ErrPostRevisionNotFound: ErrCodePostRevisionNotFound,
+	ErrPostDiffTooLong:      ErrCodePostDiffTooLong,
 
 	// Comments
-	ErrCommentCount:    ErrCodeCommentCount,
-	ErrCommentCreate:   ErrCodeCommentCreate,
-	ErrCommentDelete:   ErrCodeCommentDelete,
-	ErrCommentRead:     ErrCodeCommentRead,
-	ErrCommentReads:    ErrCodeCommentReads,
-	ErrCommentNotFound: ErrCodeCommentNotFound,
+	ErrCommentCount:     ErrCodeCommentCount,
+	ErrCommentCreate:    ErrCodeCommentCreate,
+	ErrCommentDelete:    ErrCodeCommentDelete,
+	ErrCommentRead:      ErrCodeCommentRead,
+	ErrCommentReads:     ErrCodeCommentReads,
+	ErrCommentNotFound:  ErrCodeCommentNotFound,
+	ErrCommentPurge:     ErrCodeCommentPurge,
+	ErrCommentRestore:   ErrCodeCommentRestore,
+	ErrCommentParent:    ErrCodeCommentParent,
+	ErrCommentDepth:     ErrCodeCommentDepth,
+	ErrCommentUpdate:    ErrCodeCommentUpdate,
+	ErrCommentRevisions: ErrCodeCommentRevisions,
+	ErrCommentModerate:  ErrCodeCommentModerate,
+
+	// Tags
+	ErrTagCreate:        ErrCodeTagCreate,
+	ErrTagDelete:        ErrCodeTagDelete,
+	ErrTagRead:          ErrCodeTagRead,
+	ErrTagReads:         ErrCodeTagReads,
+	ErrTagUpdate:        ErrCodeTagUpdate,
+	ErrTagNotFound:      ErrCodeTagNotFound,
+	ErrTagAlreadyExists: ErrCodeTagAlreadyExists,
+
+	// Categories
+	ErrCategoryCreate:        ErrCodeCategoryCreate,
+	ErrCategoryDelete:        ErrCodeCategoryDelete,
+	ErrCategoryRead:          ErrCodeCategoryRead,
+	ErrCategoryReads:         ErrCodeCategoryReads,
+	ErrCategoryUpdate:        ErrCodeCategoryUpdate,
+	ErrCategoryNotFound:      ErrCodeCategoryNotFound,
+	ErrCategoryAlreadyExists: ErrCodeCategoryAlreadyExists,
+	ErrCategoryParent:        ErrCodeCategoryParent,
+
+	// Search
+	ErrSearch:         ErrCodeSearch,
+	ErrSearchLanguage: ErrCodeSearchLanguage,
+
+	// Session
+	ErrSessionCreate: ErrCodeSessionCreate,
+	ErrSessionRead:   ErrCodeSessionRead,
+	ErrSessionRevoke: ErrCodeSessionRevoke,
+
+	// UserToken
+	ErrUserTokenCreate: ErrCodeUserTokenCreate,
+	ErrUserTokenRead:   ErrCodeUserTokenRead,
+	ErrUserTokenUpdate: ErrCodeUserTokenUpdate,
+
+	// Mail
+	ErrMailSend: ErrCodeMailSend,
+
+	// LoginAttempt
+	ErrLoginAttemptRead:     ErrCodeLoginAttemptRead,
+	ErrLoginAttemptUpdate:   ErrCodeLoginAttemptUpdate,
+	ErrLoginAttemptDelete:   ErrCodeLoginAttemptDelete,
+	ErrLoginAttemptNotFound: ErrCodeLoginAttemptNotFound,
+
+	// RecoveryCode
+	ErrRecoveryCodeCreate: ErrCodeRecoveryCodeCreate,
+	ErrRecoveryCodeUpdate: ErrCodeRecoveryCodeUpdate,
+
+	// APIKey
+	ErrAPIKeyCreate:   ErrCodeAPIKeyCreate,
+	ErrAPIKeyRead:     ErrCodeAPIKeyRead,
+	ErrAPIKeyUpdate:   ErrCodeAPIKeyUpdate,
+	ErrAPIKeyNotFound: ErrCodeAPIKeyNotFound,
+	ErrAPIKeyScope:    ErrCodeAPIKeyScope,
+
+	// OIDC
+	ErrOIDCProviderNotFound:  ErrCodeOIDCProviderNotFound,
+	ErrOIDCInvalidState:      ErrCodeOIDCInvalidState,
+	ErrOIDCLoginFailed:       ErrCodeOIDCLoginFailed,
+	ErrOIDCEmailUnverified:   ErrCodeOIDCEmailUnverified,
+	ErrOIDCAccountUnverified: ErrCodeOIDCAccountUnverified,
+	ErrOIDCLoginCreate:       ErrCodeOIDCLoginCreate,
+	ErrOIDCLoginUpdate:       ErrCodeOIDCLoginUpdate,
+
+	// Identity
+	ErrIdentityCreate:   ErrCodeIdentityCreate,
+	ErrIdentityRead:     ErrCodeIdentityRead,
+	ErrIdentityNotFound: ErrCodeIdentityNotFound,
+
+	// Role
+	ErrRoleCreate:        ErrCodeRoleCreate,
+	ErrRoleRead:          ErrCodeRoleRead,
+	ErrRoleReads:         ErrCodeRoleReads,
+	ErrRoleUpdate:        ErrCodeRoleUpdate,
+	ErrRoleDelete:        ErrCodeRoleDelete,
+	ErrRoleNotFound:      ErrCodeRoleNotFound,
+	ErrRoleAlreadyExists: ErrCodeRoleAlreadyExists,
+	ErrRoleBuiltIn:       ErrCodeRoleBuiltIn,
+	ErrRoleInUse:         ErrCodeRoleInUse,
 
 	// Others
 	ErrFailedRead:        ErrCodeFailedRead,
@@ -172,11 +420,21 @@ var statusCodeMap = map[string]int{
 	ErrCodeShortUsername:        http.StatusBadRequest,
 	ErrCodeUnauthorized:         http.StatusUnauthorized,
 	ErrCodeUserDisabled:         http.StatusUnauthorized,
+	ErrCodeLoginLocked:          http.StatusTooManyRequests,
+	ErrCodeTwoFactorRequired:    http.StatusForbidden,
+	ErrCodeTwoFactorEnabled:     http.StatusConflict,
+	ErrCodeTwoFactorNotEnrolled: http.StatusConflict,
+	ErrCodeInvalidTwoFactorCode: http.StatusUnauthorized,
 	ErrCodeUserNotFound:         http.StatusNotFound,
 	ErrCodeEmailNotFound:        http.StatusBadRequest,
+	ErrCodeInvalidPassword:      http.StatusBadRequest,
 	ErrCodeUsernameAlreadyTaken: http.StatusBadRequest,
 	ErrCodeUsernameRequired:     http.StatusBadRequest,
 	ErrCodeWeakPassword:         http.StatusBadRequest,
+	ErrCodeRevokedToken:         http.StatusUnauthorized,
+	ErrCodeEmailNotVerified:     http.StatusForbidden,
+	ErrCodeEmailAlreadyVerified: http.StatusConflict,
+	ErrCodeVerificationCooldown: http.StatusTooManyRequests,
 
 	// Common
 	ErrCodeBadRequest:           http.StatusBadRequest,
@@ -196,32 +454,134 @@ var statusCodeMap = map[string]int{
 	ErrCodeURLInvalid:           http.StatusBadRequest,
 	ErrCodeURLRequired:          http.StatusBadRequest,
 	ErrCodeUserAgentReadFile:    http.StatusUnprocessableEntity,
+	ErrCodePreconditionFailed:   http.StatusPreconditionFailed,
 
 	// User
-	ErrCodeUserCount:  http.StatusUnprocessableEntity,
-	ErrCodeUserCreate: http.StatusUnprocessableEntity,
-	ErrCodeUserDelete: http.StatusUnprocessableEntity,
-	ErrCodeUserRead:   http.StatusUnprocessableEntity,
-	ErrCodeUserReads:  http.StatusUnprocessableEntity,
-	ErrCodeUserSearch: http.StatusUnprocessableEntity,
-	ErrCodeUserUpdate: http.StatusUnprocessableEntity,
+	ErrCodeUserCount:          http.StatusUnprocessableEntity,
+	ErrCodeUserCreate:         http.StatusUnprocessableEntity,
+	ErrCodeUserDelete:         http.StatusUnprocessableEntity,
+	ErrCodeUserRead:           http.StatusUnprocessableEntity,
+	ErrCodeUserReads:          http.StatusUnprocessableEntity,
+	ErrCodeUserSearch:         http.StatusUnprocessableEntity,
+	ErrCodeUserUpdate:         http.StatusUnprocessableEntity,
+	ErrCodeUserPurge:          http.StatusUnprocessableEntity,
+	ErrCodeUserRestore:        http.StatusUnprocessableEntity,
+	ErrCodeUserPasswordUpdate: http.StatusUnprocessableEntity,
+	ErrCodeUserRoleUpdate:     http.StatusUnprocessableEntity,
+	ErrCodeUserStatusUpdate:   http.StatusUnprocessableEntity,
+	ErrCodeLastAdmin:          http.StatusConflict,
 
 	// Post
-	ErrCodePostCount:    http.StatusUnprocessableEntity,
-	ErrCodePostCreate:   http.StatusUnprocessableEntity,
-	ErrCodePostDelete:   http.StatusUnprocessableEntity,
-	ErrCodePostRead:     http.StatusUnprocessableEntity,
-	ErrCodePostReads:    http.StatusUnprocessableEntity,
-	ErrCodePostUpdate:   http.StatusUnprocessableEntity,
-	ErrCodePostNotFound: http.StatusNotFound,
+	ErrCodePostCount:            http.StatusUnprocessableEntity,
+	ErrCodePostCreate:           http.StatusUnprocessableEntity,
+	ErrCodePostDelete:           http.StatusUnprocessableEntity,
+	ErrCodePostRead:             http.StatusUnprocessableEntity,
+	ErrCodePostReads:            http.StatusUnprocessableEntity,
+	ErrCodePostUpdate:           http.StatusUnprocessableEntity,
+	ErrCodePostNotFound:         http.StatusNotFound,
+	ErrCodePostPurge:            http.StatusUnprocessableEntity,
+	ErrCodePostRestore:          http.StatusUnprocessableEntity,
+	ErrCodePostState:            http.StatusBadRequest,
+	ErrCodePostPublish:          http.StatusBadRequest,
+	ErrCodePostSlug:             http.StatusBadRequest,
+	ErrCodePostSlugUsed:         http.StatusBadRequest,
+	ErrCodePostRevisionCreate:   http.StatusUnprocessableEntity,
+	ErrCodePostRevisions:        http.StatusUnprocessableEntity,
+	ErrCodePostRevisionNotFound: http.StatusNotFound,
+	ErrCodePostDiffTooLong:      http.StatusUnprocessableEntity,
 
 	// Comment
-	ErrCodeCommentCount:    http.StatusUnprocessableEntity,
-	ErrCodeCommentCreate:   http.StatusUnprocessableEntity,
-	ErrCodeCommentDelete:   http.StatusUnprocessableEntity,
-	ErrCodeCommentRead:     http.StatusUnprocessableEntity,
-	ErrCodeCommentReads:    http.StatusUnprocessableEntity,
-	ErrCodeCommentNotFound: http.StatusNotFound,
+	ErrCodeCommentCount:     http.StatusUnprocessableEntity,
+	ErrCodeCommentCreate:    http.StatusUnprocessableEntity,
+	ErrCodeCommentDelete:    http.StatusUnprocessableEntity,
+	ErrCodeCommentRead:      http.StatusUnprocessableEntity,
+	ErrCodeCommentReads:     http.StatusUnprocessableEntity,
+	ErrCodeCommentNotFound:  http.StatusNotFound,
+	ErrCodeCommentPurge:     http.StatusUnprocessableEntity,
+	ErrCodeCommentRestore:   http.StatusUnprocessableEntity,
+	ErrCodeCommentParent:    http.StatusBadRequest,
+	ErrCodeCommentDepth:     http.StatusBadRequest,
+	ErrCodeCommentUpdate:    http.StatusUnprocessableEntity,
+	ErrCodeCommentRevisions: http.StatusUnprocessableEntity,
+	ErrCodeCommentModerate:  http.StatusUnprocessableEntity,
+
+	// Tag
+	ErrCodeTagCreate:        http.StatusUnprocessableEntity,
+	ErrCodeTagDelete:        http.StatusUnprocessableEntity,
+	ErrCodeTagRead:          http.StatusUnprocessableEntity,
+	ErrCodeTagReads:         http.StatusUnprocessableEntity,
+	ErrCodeTagUpdate:        http.StatusUnprocessableEntity,
+	ErrCodeTagNotFound:      http.StatusNotFound,
+	ErrCodeTagAlreadyExists: http.StatusBadRequest,
+
+	// Category
+	ErrCodeCategoryCreate:        http.StatusUnprocessableEntity,
+	ErrCodeCategoryDelete:        http.StatusUnprocessableEntity,
+	ErrCodeCategoryRead:          http.StatusUnprocessableEntity,
+	ErrCodeCategoryReads:         http.StatusUnprocessableEntity,
+	ErrCodeCategoryUpdate:        http.StatusUnprocessableEntity,
+	ErrCodeCategoryNotFound:      http.StatusNotFound,
+	ErrCodeCategoryAlreadyExists: http.StatusBadRequest,
+	ErrCodeCategoryParent:        http.StatusBadRequest,
+
+	// Search
+	ErrCodeSearch:         http.StatusUnprocessableEntity,
+	ErrCodeSearchLanguage: http.StatusUnprocessableEntity,
+
+	// Session
+	ErrCodeSessionCreate: http.StatusUnprocessableEntity,
+	ErrCodeSessionRead:   http.StatusUnprocessableEntity,
+	ErrCodeSessionRevoke: http.StatusUnprocessableEntity,
+
+	// UserToken
+	ErrCodeUserTokenCreate: http.StatusUnprocessableEntity,
+	ErrCodeUserTokenRead:   http.StatusUnprocessableEntity,
+	ErrCodeUserTokenUpdate: http.StatusUnprocessableEntity,
+
+	// Mail
+	ErrCodeMailSend: http.StatusInternalServerError,
+
+	// LoginAttempt
+	ErrCodeLoginAttemptRead:     http.StatusUnprocessableEntity,
+	ErrCodeLoginAttemptUpdate:   http.StatusUnprocessableEntity,
+	ErrCodeLoginAttemptDelete:   http.StatusUnprocessableEntity,
+	ErrCodeLoginAttemptNotFound: http.StatusNotFound,
+
+	// RecoveryCode
+	ErrCodeRecoveryCodeCreate: http.StatusUnprocessableEntity,
+	ErrCodeRecoveryCodeUpdate: http.StatusUnprocessableEntity,
+
+	// APIKey
+	ErrCodeAPIKeyCreate:   http.StatusUnprocessableEntity,
+	ErrCodeAPIKeyRead:     http.StatusUnprocessableEntity,
+	ErrCodeAPIKeyUpdate:   http.StatusUnprocessableEntity,
+	ErrCodeAPIKeyNotFound: http.StatusNotFound,
+	ErrCodeAPIKeyScope:    http.StatusForbidden,
+
+	// OIDC
+	ErrCodeOIDCProviderNotFound:  http.StatusNotFound,
+	ErrCodeOIDCInvalidState:      http.StatusBadRequest,
+	ErrCodeOIDCLoginFailed:       http.StatusUnauthorized,
+	ErrCodeOIDCEmailUnverified:   http.StatusForbidden,
+	ErrCodeOIDCAccountUnverified: http.StatusConflict,
+	ErrCodeOIDCLoginCreate:       http.StatusUnprocessableEntity,
+	ErrCodeOIDCLoginUpdate:       http.StatusUnprocessableEntity,
+
+	// Identity
+	ErrCodeIdentityCreate:   http.StatusUnprocessableEntity,
+	ErrCodeIdentityRead:     http.StatusUnprocessableEntity,
+	ErrCodeIdentityNotFound: http.StatusNotFound,
+
+	// Role
+	ErrCodeRoleCreate:        http.StatusUnprocessableEntity,
+	ErrCodeRoleRead:          http.StatusUnprocessableEntity,
+	ErrCodeRoleReads:         http.StatusUnprocessableEntity,
+	ErrCodeRoleUpdate:        http.StatusUnprocessableEntity,
+	ErrCodeRoleDelete:        http.StatusUnprocessableEntity,
+	ErrCodeRoleNotFound:      http.StatusNotFound,
+	ErrCodeRoleAlreadyExists: http.StatusBadRequest,
+	ErrCodeRoleBuiltIn:       http.StatusConflict,
+	ErrCodeRoleInUse:         http.StatusConflict,
 }
 
 // StatusCode gets HTTP status code from error code.
@@ -15,13 +15,13 @@ func InsertUsers(us []*model.User, db *sql.DB) {
 		log.Fatal(err)
 	}
 
-	stmt, err := txn.Prepare(pq.CopyIn("users", "id", "email", "username", "encrypted_password", "user_role", "created_at", "updated_at"))
+	stmt, err := txn.Prepare(pq.CopyIn("users", "id", "email", "username", "encrypted_password", "user_role", "created_at", "updated_at", "status", "created_by", "updated_by", "email_verified_at"))
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	for _, user := range us {
-		_, err = stmt.Exec(user.ID, user.Email, user.Username, user.EncryptedPassword, user.Role, user.CreatedAt, user.UpdatedAt)
+		_, err = stmt.Exec(user.ID, user.Email, user.Username, user.EncryptedPassword, user.Role, user.CreatedAt, user.UpdatedAt, user.Status, user.CreatedBy, user.UpdatedBy, user.EmailVerifiedAt)
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -44,34 +44,14 @@ func InsertUsers(us []*model.User, db *sql.DB) {
 }
 
 func DeleteUsers(db *sql.DB) {
-	dcq := "DROP TABLE comments;"
+	// CASCADE also empties the tables referencing users, e.g. comments. Failed
+	// logins are keyed by email, so they go too.
+	tq := "TRUNCATE TABLE users, login_attempts CASCADE"
 
-	_, err := db.Exec(dcq)
+	_, err := db.Exec(tq)
 	if err != nil {
 		log.Fatalf(err.Error())
 	}
-
-	tuq := "TRUNCATE TABLE users"
-
-	_, err = db.Exec(tuq)
-	if err != nil {
-		log.Fatalf(err.Error())
-	}
-
-	ctc := `CREATE TABLE IF NOT EXISTS comments (
-    id 				   serial PRIMARY KEY,
-    text 			   varchar(255),
-	author 			   varchar references users(username), 
-	user_id 		   int references users(id),
-	post_id 		   int references posts(id),
-    created_at 		   timestamp,
-    updated_at 		   timestamp
-	)`
-
-	_, err = db.Exec(ctc)
-	if err != nil {
-		log.Fatal(err.Error())
-	}
 }
 
 func InsertPosts(ps []*model.Post, db *sql.DB) {
@@ -80,13 +60,19 @@ func InsertPosts(ps []*model.Post, db *sql.DB) {
 		log.Fatal(err)
 	}
 
-	stmt, err := txn.Prepare(pq.CopyIn("posts", "id", "title", "body", "created_at", "updated_at"))
+	stmt, err := txn.Prepare(pq.CopyIn("posts", "id", "title", "body", "created_at", "updated_at", "status", "created_by", "updated_by", "user_id", "state", "publish_at", "slug"))
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	for _, post := range ps {
-		_, err = stmt.Exec(post.ID, post.Title, post.Body, post.CreatedAt, post.UpdatedAt)
+		// Posts without an author are stored with a NULL user_id.
+		var uid any
+		if post.UserID != 0 {
+			uid = post.UserID
+		}
+
+		_, err = stmt.Exec(post.ID, post.Title, post.Body, post.CreatedAt, post.UpdatedAt, post.Status, post.CreatedBy, post.UpdatedBy, uid, post.State, post.PublishAt, post.Slug)
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -109,32 +95,65 @@ func InsertPosts(ps []*model.Post, db *sql.DB) {
 }
 
 func DeletePosts(db *sql.DB) {
-	dcq := "DROP TABLE comments;"
+	// CASCADE also empties the tables referencing posts, e.g. comments.
+	tq := "TRUNCATE TABLE posts CASCADE"
 
-	_, err := db.Exec(dcq)
+	_, err := db.Exec(tq)
 	if err != nil {
 		log.Fatalf(err.Error())
 	}
+}
+
+// InsertComments inserts cs in order, so parents have to come before their replies.
+func InsertComments(cs []*model.Comment, db *sql.DB) {
+	for _, c := range cs {
+		_, err := db.Exec(`INSERT INTO comments (id, author, user_id, post_id, text, created_at, status, created_by, updated_by, parent_id, depth, updated_at, edited, state)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`,
+			c.ID, c.Author, c.UserID, c.PostID, c.Text, c.CreatedAt, c.Status, c.CreatedBy, c.UpdatedBy, c.ParentID, c.Depth,
+			c.UpdatedAt, c.Edited, c.State)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func InsertTags(ts []*model.Tag, db *sql.DB) {
+	for _, t := range ts {
+		_, err := db.Exec("INSERT INTO tags (id, name, slug, created_at, updated_at) VALUES ($1, $2, $3, $4, $5)",
+			t.ID, t.Name, t.Slug, t.CreatedAt, t.UpdatedAt)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// InsertCategories inserts cs in order, so parents have to come before their children.
+func InsertCategories(cs []*model.Category, db *sql.DB) {
+	for _, c := range cs {
+		_, err := db.Exec("INSERT INTO categories (id, parent_id, name, slug, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6)",
+			c.ID, c.ParentID, c.Name, c.Slug, c.CreatedAt, c.UpdatedAt)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+}
 
-	tuq := "TRUNCATE TABLE posts"
+func DeleteTags(db *sql.DB) {
+	// CASCADE also empties post_tags.
+	tq := "TRUNCATE TABLE tags CASCADE"
 
-	_, err = db.Exec(tuq)
+	_, err := db.Exec(tq)
 	if err != nil {
 		log.Fatalf(err.Error())
 	}
+}
 
-	ctc := `CREATE TABLE IF NOT EXISTS comments (
-    id 				   serial PRIMARY KEY,
-    text 			   varchar(255),
-	author 			   varchar references users(username), 
-	user_id 		   int references users(id),
-	post_id 		   int references posts(id),
-    created_at 		   timestamp,
-    updated_at 		   timestamp
-	)`
+func DeleteCategories(db *sql.DB) {
+	// CASCADE also empties post_categories.
+	tq := "TRUNCATE TABLE categories CASCADE"
 
-	_, err = db.Exec(ctc)
+	_, err := db.Exec(tq)
 	if err != nil {
-		log.Fatal(err.Error())
+		log.Fatalf(err.Error())
 	}
 }
@@ -6,12 +6,30 @@ import (
 	"github.com/labstack/echo/v4"
 
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/pagination"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/echoutils"
 )
 
 type Handler interface {
 	Login() echo.HandlerFunc
+	LoginTwoFactor() echo.HandlerFunc
+	EnrollTwoFactor() echo.HandlerFunc
+	ConfirmTwoFactor() echo.HandlerFunc
+	DisableTwoFactor() echo.HandlerFunc
+	RegenerateRecoveryCodes() echo.HandlerFunc
+	ReadsLockouts() echo.HandlerFunc
+	DeleteLockout() echo.HandlerFunc
 	Register() echo.HandlerFunc
+	Refresh() echo.HandlerFunc
+	Logout() echo.HandlerFunc
+	LogoutAll() echo.HandlerFunc
+	JWKS() echo.HandlerFunc
+	ForgotPassword() echo.HandlerFunc
+	ResetPassword() echo.HandlerFunc
+	VerifyEmail() echo.HandlerFunc
+	ResendVerification() echo.HandlerFunc
+	OIDCLogin() echo.HandlerFunc
+	OIDCCallback() echo.HandlerFunc
 }
 
 type handler struct {
@@ -31,6 +49,8 @@ func (h *handler) Login() echo.HandlerFunc {
 			return err
 		}
 
+		r.IP = c.RealIP()
+
 		resp, err := h.service.Login(r)
 		if err != nil {
 			return err
@@ -47,7 +67,7 @@ func (h *handler) Register() echo.HandlerFunc {
 			return err
 		}
 
-		resp, err := h.service.Register(r)
+		resp, err := h.service.Register(c.Request().Context(), r)
 		if err != nil {
 			return err
 		}
@@ -55,3 +75,250 @@ func (h *handler) Register() echo.HandlerFunc {
 		return c.JSON(http.StatusCreated, resp)
 	}
 }
+
+func (h *handler) Refresh() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := new(dto.RefreshRequest)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		resp, err := h.service.Refresh(r)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, resp)
+	}
+}
+
+func (h *handler) Logout() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := new(dto.RefreshRequest)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		if err := h.service.Logout(r); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+func (h *handler) LogoutAll() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if err := h.service.LogoutAll(c.Request().Context()); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+func (h *handler) JWKS() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.JSON(http.StatusOK, h.service.JWKS())
+	}
+}
+
+func (h *handler) ForgotPassword() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := new(dto.ForgotPasswordRequest)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		if err := h.service.ForgotPassword(c.Request().Context(), r); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusAccepted)
+	}
+}
+
+func (h *handler) ResetPassword() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := new(dto.ResetPasswordRequest)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		if err := h.service.ResetPassword(r); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+func (h *handler) VerifyEmail() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := new(dto.VerifyEmailRequest)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		if err := h.service.VerifyEmail(r); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+func (h *handler) ResendVerification() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if err := h.service.ResendVerification(c.Request().Context()); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusAccepted)
+	}
+}
+
+func (h *handler) ReadsLockouts() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		p := pagination.NewPagination()
+		if err := echoutils.BindAndValidate(c, p); err != nil {
+			return err
+		}
+
+		res, err := h.service.ReadsLockouts(p)
+		if err != nil {
+			return err
+		}
+
+		p.PaginationHeader(c)
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+func (h *handler) DeleteLockout() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := new(dto.RequestWithID)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		if err := h.service.DeleteLockout(r); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+func (h *handler) LoginTwoFactor() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := new(dto.TwoFactorLoginRequest)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		r.IP = c.RealIP()
+
+		resp, err := h.service.LoginTwoFactor(r)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, resp)
+	}
+}
+
+func (h *handler) EnrollTwoFactor() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		resp, err := h.service.EnrollTwoFactor(c.Request().Context())
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, resp)
+	}
+}
+
+func (h *handler) ConfirmTwoFactor() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := new(dto.TwoFactorCodeRequest)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		resp, err := h.service.ConfirmTwoFactor(c.Request().Context(), r)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, resp)
+	}
+}
+
+func (h *handler) DisableTwoFactor() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := new(dto.TwoFactorDisableRequest)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		r.IP = c.RealIP()
+
+		if err := h.service.DisableTwoFactor(c.Request().Context(), r); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+func (h *handler) RegenerateRecoveryCodes() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := new(dto.TwoFactorCodeRequest)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		r.IP = c.RealIP()
+
+		resp, err := h.service.RegenerateRecoveryCodes(c.Request().Context(), r)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, resp)
+	}
+}
+
+func (h *handler) OIDCLogin() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := new(dto.OIDCLoginRequest)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		url, err := h.service.OIDCLogin(c.Request().Context(), r)
+		if err != nil {
+			return err
+		}
+
+		return c.Redirect(http.StatusFound, url)
+	}
+}
+
+func (h *handler) OIDCCallback() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := new(dto.OIDCCallbackRequest)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		resp, err := h.service.OIDCCallback(c.Request().Context(), r)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, resp)
+	}
+}
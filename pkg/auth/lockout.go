@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/repository"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/throttle"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
+)
+
+var (
+	// defaultAccountLockout and defaultIPLockout fill in the unset lockout settings.
+	defaultAccountLockout = throttle.Policy{Threshold: 5, Base: 30 * time.Second, Max: time.Hour, Window: time.Hour}
+	defaultIPLockout      = throttle.Policy{Threshold: 50, Base: 30 * time.Second, Max: time.Hour, Window: time.Hour}
+)
+
+// Lockout locks out an account and a client IP after too many wrong
+// passwords or codes.
+type Lockout interface {
+	// Check fails when the account or the IP is locked out at now.
+	Check(account, ip string, now time.Time) error
+	// Fail counts a failed attempt of the account and the IP.
+	Fail(account, ip string, now time.Time) error
+}
+
+type lockout struct {
+	attempts repository.LoginAttempt
+	account  throttle.Policy
+	ip       throttle.Policy
+}
+
+// NewLockout returns a Lockout counting failures in attempts; unset settings
+// of the account and ip policies use the defaults.
+func NewLockout(attempts repository.LoginAttempt, account, ip throttle.Policy) Lockout {
+	return &lockout{
+		attempts: attempts,
+		account:  account.Or(defaultAccountLockout),
+		ip:       ip.Or(defaultIPLockout),
+	}
+}
+
+func (l *lockout) Check(account, ip string, now time.Time) error {
+	for _, k := range []struct {
+		kind types.LoginAttemptKind
+		key  string
+	}{{types.LoginAccount, account}, {types.LoginIP, ip}} {
+		until, err := l.attempts.LockedUntil(k.kind, k.key, now)
+		if err != nil {
+			return err
+		}
+
+		if until != nil {
+			return errorutils.New(errorutils.ErrLoginLocked, nil)
+		}
+	}
+
+	return nil
+}
+
+func (l *lockout) Fail(account, ip string, now time.Time) error {
+	if err := l.countFailure(types.LoginAccount, account, l.account, now); err != nil {
+		return err
+	}
+
+	return l.countFailure(types.LoginIP, ip, l.ip, now)
+}
+
+// countFailure counts a failed attempt of the key and locks it out once policy says so.
+func (l *lockout) countFailure(kind types.LoginAttemptKind, key string, policy throttle.Policy, now time.Time) error {
+	a, err := l.attempts.Fail(kind, key, now, now.Add(-policy.Window))
+	if err != nil {
+		return err
+	}
+
+	if d := policy.Delay(a.Failures); d > 0 {
+		return l.attempts.Lock(a.ID, now.Add(d))
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/appcontext"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/repository"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/apikey"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/jwtauth"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/apputils"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
+)
+
+// Authenticator authenticates requests by the access token or API key in
+// their Authorization header.
+type Authenticator struct {
+	signer            *jwtauth.Signer
+	userRepository    repository.User
+	sessionRepository repository.Session
+	apiKeyRepository  repository.APIKey
+}
+
+func NewAuthenticator(signer *jwtauth.Signer, userRepository repository.User, sessionRepository repository.Session,
+	apiKeyRepository repository.APIKey,
+) *Authenticator {
+	return &Authenticator{
+		signer:            signer,
+		userRepository:    userRepository,
+		sessionRepository: sessionRepository,
+		apiKeyRepository:  apiKeyRepository,
+	}
+}
+
+// Authenticate lets through only authenticated requests.
+func (a *Authenticator) Authenticate() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if err := a.authenticateRequest(c); err != nil {
+				return err
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// OptionalAuthenticate authenticates requests carrying an Authorization header
+// and lets anonymous requests through, for public routes that show more to staff.
+func (a *Authenticator) OptionalAuthenticate() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Request().Header.Get("Authorization") == "" {
+				return next(c)
+			}
+
+			if err := a.authenticateRequest(c); err != nil {
+				return err
+			}
+
+			return next(c)
+		}
+	}
+}
+
+func (a *Authenticator) authenticateRequest(c echo.Context) error {
+	authHeader := c.Request().Header.Get("Authorization")
+	if authHeader == "" {
+		return errorutils.New(errorutils.ErrMissingAuthHeader, errorutils.ErrMissingAuthHeader)
+	}
+
+	ts := strings.Replace(authHeader, "Bearer ", "", 1)
+
+	var (
+		claims *dto.Claims
+		err    error
+	)
+
+	if apikey.Is(ts) {
+		claims, err = a.verifyAPIKey(c, ts)
+	} else {
+		claims, err = a.signer.Verify(ts)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	u, err := a.userRepository.Read(claims.UID)
+	if err != nil {
+		return errorutils.New(errorutils.ErrLoginFailed, err)
+	}
+
+	if u.Suspended(time.Now()) {
+		return errorutils.New(errorutils.ErrUserDisabled, nil)
+	}
+
+	if claims.APIKeyID != 0 {
+		// A key acts as its user does now.
+		claims.Role = u.Role
+		claims.Username = u.Username
+		claims.Email = u.Email
+	}
+
+	if u.Role != claims.Role {
+		return errorutils.New(errorutils.ErrInvalidRequest, nil)
+	}
+
+	// The token may predate the verification or the two-factor change.
+	claims.EmailVerified = u.EmailVerifiedAt != nil
+	claims.TwoFactor = u.TwoFactorEnabled()
+
+	// Logging out revokes the session, and with it every access token issued in it.
+	if claims.APIKeyID == 0 {
+		revoked, err := a.sessionRepository.Revoked(claims.SessionID)
+		if err != nil {
+			return err
+		}
+
+		if revoked {
+			return errorutils.New(errorutils.ErrRevokedToken, nil)
+		}
+	}
+
+	// Use custom context functions to store values
+	ctx := appcontext.WithMtsBlogUser(c.Request().Context(), claims)
+	ctx = appcontext.WithMtsBlogRole(ctx, claims.Role)
+	ctx = appcontext.WithMtsBlogUserID(ctx, claims.UID)
+
+	// Update the request context
+	c.SetRequest(c.Request().WithContext(ctx))
+
+	return nil
+}
+
+// verifyAPIKey returns the claims for the owner of key, if the key is usable
+// and its scopes allow the route of c.
+func (a *Authenticator) verifyAPIKey(c echo.Context, key string) (*dto.Claims, error) {
+	k, err := a.apiKeyRepository.ReadByHash(apputils.HashToken(key))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	if k.RevokedAt != nil {
+		return nil, errorutils.New(errorutils.ErrRevokedToken, nil)
+	}
+
+	if !k.Usable(now) {
+		return nil, errorutils.New(errorutils.ErrExpiredToken, nil)
+	}
+
+	if !apikey.Allows(k.Scopes, c.Request().Method, c.Path()) {
+		return nil, errorutils.New(errorutils.ErrAPIKeyScope, nil)
+	}
+
+	if err = a.apiKeyRepository.Touch(k.ID, now); err != nil {
+		return nil, err
+	}
+
+	return &dto.Claims{UID: k.UserID, APIKeyID: k.ID}, nil
+}
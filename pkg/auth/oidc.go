@@ -0,0 +1,228 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/oidc"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/apputils"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
+)
+
+const (
+	// usernameBaseLen leaves room in a username for the suffix added when it is taken.
+	usernameBaseLen = 16
+	// usernameTries is how many usernames are tried for a new user.
+	usernameTries = 5
+)
+
+// OIDCLogin starts a sign in with the provider and returns the page to send
+// the user to.
+func (s *service) OIDCLogin(ctx context.Context, req *dto.OIDCLoginRequest) (string, error) {
+	p, ok := s.providers[req.Provider]
+	if !ok {
+		return "", errorutils.New(errorutils.ErrOIDCProviderNotFound, nil)
+	}
+
+	var secrets [3]string
+
+	for i := range secrets {
+		t, err := apputils.RandomToken(userTokenSize)
+		if err != nil {
+			return "", err
+		}
+
+		secrets[i] = t
+	}
+
+	state, nonce, verifier := secrets[0], secrets[1], secrets[2]
+
+	url, err := p.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return "", errorutils.New(errorutils.ErrOIDCLoginFailed, err)
+	}
+
+	now := time.Now()
+
+	err = s.identityRepository.CreateLogin(&model.OIDCLogin{
+		StateHash:    apputils.HashToken(state),
+		Provider:     req.Provider,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(s.config.OIDCLoginTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return url, nil
+}
+
+// OIDCCallback finishes a sign in with the provider. The user is the one the
+// identity is linked to, else the one with its verified email, else a new
+// one. Users with two-factor authentication on get a challenge as in Login.
+func (s *service) OIDCCallback(ctx context.Context, req *dto.OIDCCallbackRequest) (*dto.LoginResponse, error) {
+	p, ok := s.providers[req.Provider]
+	if !ok {
+		return nil, errorutils.New(errorutils.ErrOIDCProviderNotFound, nil)
+	}
+
+	now := time.Now()
+
+	l, err := s.identityRepository.UseLogin(apputils.HashToken(req.State), now)
+	if err != nil {
+		return nil, err
+	}
+
+	if l.Provider != req.Provider || now.After(l.ExpiresAt) {
+		return nil, errorutils.New(errorutils.ErrOIDCInvalidState, nil)
+	}
+
+	id, err := p.Exchange(ctx, req.Code, l.CodeVerifier, l.Nonce, now)
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrOIDCLoginFailed, err)
+	}
+
+	u, err := s.oidcUser(req.Provider, id, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Suspended(now) {
+		return nil, errorutils.New(errorutils.ErrUserDisabled, nil)
+	}
+
+	return s.finishLogin(u)
+}
+
+// oidcUser returns the user id signs in as. The first time, the identity is
+// linked to the user with its email, or to a new user.
+func (s *service) oidcUser(provider string, id *oidc.Identity, now time.Time) (*model.User, error) {
+	var apiErr *errorutils.APIError
+
+	ident, err := s.identityRepository.Read(provider, id.Subject)
+	if err == nil {
+		return s.userRepository.Read(ident.UserID)
+	}
+
+	if !errors.As(err, &apiErr) || apiErr.Code != errorutils.ErrCodeIdentityNotFound {
+		return nil, err
+	}
+
+	if id.Email == "" || !id.EmailVerified {
+		return nil, errorutils.New(errorutils.ErrOIDCEmailUnverified, nil)
+	}
+
+	u, err := s.userRepository.ReadByEmail(id.Email)
+
+	switch {
+	case err == nil:
+		// Anyone could have registered an email they do not own; only a
+		// verified one proves the account and the identity are the same person.
+		if u.EmailVerifiedAt == nil {
+			return nil, errorutils.New(errorutils.ErrOIDCAccountUnverified, nil)
+		}
+	case errors.As(err, &apiErr) && apiErr.Code == errorutils.ErrCodeEmailNotFound:
+		if u, err = s.createOIDCUser(id, now); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	err = s.identityRepository.Create(&model.Identity{UserID: u.ID, Provider: provider, Subject: id.Subject, Email: id.Email, CreatedAt: now})
+	if err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
+// createOIDCUser creates a verified user for id. Its password is random; the
+// user can set one with a password reset.
+func (s *service) createOIDCUser(id *oidc.Identity, now time.Time) (*model.User, error) {
+	password, err := apputils.RandomToken(userTokenSize)
+	if err != nil {
+		return nil, err
+	}
+
+	ep, err := apputils.EncryptPassword(password)
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrUnexpected, err)
+	}
+
+	u := &model.User{
+		BaseModel:         model.BaseModel{CreatedAt: now, UpdatedAt: now, Status: types.Active},
+		Email:             id.Email,
+		EncryptedPassword: ep,
+		Role:              types.Registered,
+	}
+
+	base := username(id)
+
+	for i := 0; ; i++ {
+		u.Username = base
+
+		if i > 0 {
+			suffix := make([]byte, 2)
+			if _, err = rand.Read(suffix); err != nil {
+				return nil, errorutils.New(errorutils.ErrUnexpected, err)
+			}
+
+			u.Username = base + hex.EncodeToString(suffix)
+		}
+
+		err = s.userRepository.Create(u)
+		if err == nil {
+			break
+		}
+
+		var apiErr *errorutils.APIError
+		if i == usernameTries-1 || !errors.As(err, &apiErr) || apiErr.Code != errorutils.ErrCodeUsernameAlreadyTaken {
+			return nil, err
+		}
+	}
+
+	if err = s.userRepository.VerifyEmail(u.ID, now); err != nil {
+		return nil, err
+	}
+
+	u.EmailVerifiedAt = &now
+
+	return u, nil
+}
+
+// username makes a username from the preferred username of id, or from its email.
+func username(id *oidc.Identity) string {
+	name := id.PreferredUsername
+	if name == "" {
+		name, _, _ = strings.Cut(id.Email, "@")
+	}
+
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '.', r == '-':
+			return r
+		default:
+			return -1
+		}
+	}, name)
+
+	if len(name) > usernameBaseLen {
+		name = name[:usernameBaseLen]
+	}
+
+	if len(name) < 3 {
+		name = "user"
+	}
+
+	return name
+}
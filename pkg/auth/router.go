@@ -3,20 +3,56 @@ package auth
 import (
 	"github.com/labstack/echo/v4"
 
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/rbac"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/repository"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/jwtauth"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/mailer"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
 )
 
 type Router struct {
-	RouterGroup    *echo.Group
-	UserRepository repository.User
+	Authenticate echo.MiddlewareFunc
+	RBAC         rbac.RBAC
+	RouterGroup  *echo.Group
+	// WellKnownGroup serves /.well-known, outside the versioned API.
+	WellKnownGroup      *echo.Group
+	UserRepository      repository.User
+	SessionRepository   repository.Session
+	UserTokenRepository repository.UserToken
+	// LoginAttemptRepository counts failed logins for lockouts.
+	LoginAttemptRepository repository.LoginAttempt
+	RecoveryCodeRepository repository.RecoveryCode
+	IdentityRepository     repository.Identity
+	APIKeyRepository       repository.APIKey
+	Signer                 *jwtauth.Signer
+	Mailer                 mailer.Mailer
+	Config                 Config
 }
 
 func (r *Router) New() {
-	as := NewService(r.UserRepository)
+	as := NewService(r.UserRepository, r.SessionRepository, r.UserTokenRepository, r.LoginAttemptRepository, r.RecoveryCodeRepository, r.IdentityRepository, r.APIKeyRepository, r.Signer, r.Mailer, r.Config)
 	ah := NewHandler(as)
 
 	ugr := r.RouterGroup.Group("/auth")
 
 	ugr.POST("/login", ah.Login())
+	ugr.POST("/login/2fa", ah.LoginTwoFactor())
 	ugr.POST("/register", ah.Register())
+	ugr.GET("/oidc/:provider", ah.OIDCLogin())
+	ugr.GET("/oidc/:provider/callback", ah.OIDCCallback())
+	ugr.POST("/refresh", ah.Refresh())
+	ugr.POST("/logout", ah.Logout())
+	ugr.POST("/logout-all", ah.LogoutAll(), r.Authenticate)
+	ugr.POST("/password/forgot", ah.ForgotPassword())
+	ugr.POST("/password/reset", ah.ResetPassword())
+	ugr.GET("/verify", ah.VerifyEmail())
+	ugr.POST("/verify/resend", ah.ResendVerification(), r.Authenticate)
+	ugr.POST("/2fa/enroll", ah.EnrollTwoFactor(), r.Authenticate)
+	ugr.POST("/2fa/confirm", ah.ConfirmTwoFactor(), r.Authenticate)
+	ugr.POST("/2fa/disable", ah.DisableTwoFactor(), r.Authenticate)
+	ugr.POST("/2fa/recovery-codes", ah.RegenerateRecoveryCodes(), r.Authenticate)
+	ugr.GET("/lockouts", ah.ReadsLockouts(), r.Authenticate, r.RBAC.RequirePermission(types.UserManage))
+	ugr.DELETE("/lockouts/:id", ah.DeleteLockout(), r.Authenticate, r.RBAC.RequirePermission(types.UserManage))
+
+	r.WellKnownGroup.GET("/jwks.json", ah.JWKS())
 }
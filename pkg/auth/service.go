@@ -1,60 +1,272 @@
 package auth
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
 
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/appcontext"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/repository"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/jwtauth"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/mailer"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/oidc"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/pagination"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/throttle"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/apputils"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
 )
 
 type Service interface {
-	Login(*dto.LoginRequest) (*dto.WithTokenResponse, error)
-	Register(*dto.RegisterRequest) (*dto.WithTokenResponse, error)
+	Login(*dto.LoginRequest) (*dto.LoginResponse, error)
+	LoginTwoFactor(*dto.TwoFactorLoginRequest) (*dto.WithTokenResponse, error)
+	EnrollTwoFactor(context.Context) (*dto.TwoFactorEnrollResponse, error)
+	ConfirmTwoFactor(context.Context, *dto.TwoFactorCodeRequest) (*dto.RecoveryCodesResponse, error)
+	DisableTwoFactor(context.Context, *dto.TwoFactorDisableRequest) error
+	RegenerateRecoveryCodes(context.Context, *dto.TwoFactorCodeRequest) (*dto.RecoveryCodesResponse, error)
+	ReadsLockouts(*pagination.Pageable) ([]*dto.LoginAttemptResponse, error)
+	DeleteLockout(*dto.RequestWithID) error
+	Register(context.Context, *dto.RegisterRequest) (*dto.WithTokenResponse, error)
+	Refresh(*dto.RefreshRequest) (*dto.WithTokenResponse, error)
+	Logout(*dto.RefreshRequest) error
+	LogoutAll(context.Context) error
+	JWKS() *jwtauth.JWKS
+	ForgotPassword(context.Context, *dto.ForgotPasswordRequest) error
+	ResetPassword(*dto.ResetPasswordRequest) error
+	VerifyEmail(*dto.VerifyEmailRequest) error
+	ResendVerification(context.Context) error
+	OIDCLogin(context.Context, *dto.OIDCLoginRequest) (string, error)
+	OIDCCallback(context.Context, *dto.OIDCCallbackRequest) (*dto.LoginResponse, error)
+}
+
+const (
+	// defaultRefreshTTL, defaultResetTTL and defaultVerifyTTL are used when no
+	// lifetime is configured.
+	defaultRefreshTTL = 30 * 24 * time.Hour
+	defaultResetTTL   = time.Hour
+	defaultVerifyTTL  = 24 * time.Hour
+
+	// defaultChallengeTTL is how long a login waits for the second factor.
+	defaultChallengeTTL = 5 * time.Minute
+
+	// defaultTOTPIssuer names the account in authenticator apps.
+	defaultTOTPIssuer = "mts-blog-api"
+
+	// defaultResendCooldown is the least time between two verification mails.
+	defaultResendCooldown = time.Minute
+
+	// defaultOIDCLoginTTL is how long a sign in waits for the provider.
+	defaultOIDCLoginTTL = 10 * time.Minute
+
+	// dummyPassword is checked for unknown emails, so they take as long as known ones.
+	dummyPassword = "mts-blog-api-dummy-password"
+
+	// refreshTokenSize and userTokenSize are the number of random bytes in the tokens.
+	refreshTokenSize = 32
+	userTokenSize    = 32
+)
+
+// Config holds the auth settings; zero lifetimes use the defaults.
+type Config struct {
+	RefreshTTL time.Duration
+	// ResetTTL is how long a password reset link stays valid.
+	ResetTTL time.Duration
+	// ResetURL is the page reset links point to, with the token in its query.
+	ResetURL string
+	// VerifyTTL is how long an email verification link stays valid.
+	VerifyTTL time.Duration
+	// VerifyURL is where verification links point to, with the token in its query.
+	VerifyURL string
+	// ResendCooldown is the least time between two verification mails to a user.
+	ResendCooldown time.Duration
+	// AccountLockout and IPLockout lock out an email and a client IP after
+	// failed logins.
+	AccountLockout throttle.Policy
+	IPLockout      throttle.Policy
+	// ChallengeTTL is how long a login waits for the second factor.
+	ChallengeTTL time.Duration
+	// TOTPIssuer names the account in authenticator apps.
+	TOTPIssuer string
+	// OIDCProviders are the OpenID Connect providers users can sign in with, by name.
+	OIDCProviders map[string]oidc.Config
+	// OIDCLoginTTL is how long a sign in waits for the provider.
+	OIDCLoginTTL time.Duration
 }
 
 type service struct {
-	userRepository repository.User
+	userRepository      repository.User
+	sessionRepository   repository.Session
+	userTokenRepository repository.UserToken
+	attemptRepository   repository.LoginAttempt
+	lockout             Lockout
+	codeRepository      repository.RecoveryCode
+	identityRepository  repository.Identity
+	apiKeyRepository    repository.APIKey
+	providers           map[string]*oidc.Provider
+	signer              *jwtauth.Signer
+	mailer              mailer.Mailer
+	verifier            Verifier
+	config              Config
+	dummyHash           string
 }
 
-func NewService(repository repository.User) Service {
+// NewService returns the auth service; access tokens are issued by signer,
+// mails sent through m, failed logins counted in attempts, recovery codes
+// kept in codes, provider identities in identities and API keys in keys.
+func NewService(users repository.User, sessions repository.Session, tokens repository.UserToken, attempts repository.LoginAttempt,
+	codes repository.RecoveryCode, identities repository.Identity, keys repository.APIKey, signer *jwtauth.Signer, m mailer.Mailer, cfg Config,
+) Service {
+	if cfg.RefreshTTL <= 0 {
+		cfg.RefreshTTL = defaultRefreshTTL
+	}
+
+	if cfg.ResetTTL <= 0 {
+		cfg.ResetTTL = defaultResetTTL
+	}
+
+	if cfg.ResendCooldown <= 0 {
+		cfg.ResendCooldown = defaultResendCooldown
+	}
+
+	if cfg.ChallengeTTL <= 0 {
+		cfg.ChallengeTTL = defaultChallengeTTL
+	}
+
+	if cfg.TOTPIssuer == "" {
+		cfg.TOTPIssuer = defaultTOTPIssuer
+	}
+
+	if cfg.OIDCLoginTTL <= 0 {
+		cfg.OIDCLoginTTL = defaultOIDCLoginTTL
+	}
+
+	providers := make(map[string]*oidc.Provider, len(cfg.OIDCProviders))
+	for name, pc := range cfg.OIDCProviders {
+		providers[name] = oidc.New(pc, nil)
+	}
+
+	dummyHash, err := apputils.EncryptPassword(dummyPassword)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	return &service{
-		userRepository: repository,
+		userRepository:      users,
+		sessionRepository:   sessions,
+		userTokenRepository: tokens,
+		attemptRepository:   attempts,
+		lockout:             NewLockout(attempts, cfg.AccountLockout, cfg.IPLockout),
+		codeRepository:      codes,
+		identityRepository:  identities,
+		apiKeyRepository:    keys,
+		providers:           providers,
+		signer:              signer,
+		mailer:              m,
+		verifier:            NewVerifier(tokens, m, cfg.VerifyTTL, cfg.VerifyURL),
+		config:              cfg,
+		dummyHash:           dummyHash,
 	}
 }
 
-func (s *service) Login(req *dto.LoginRequest) (*dto.WithTokenResponse, error) {
+// Login starts a session for the user with the email and password, or returns
+// a challenge to answer with a second factor when the user has 2FA on. Unknown
+// emails and wrong passwords fail alike, and too many failures lock out the
+// email and the client IP for a while.
+func (s *service) Login(req *dto.LoginRequest) (*dto.LoginResponse, error) {
+	now := time.Now()
+	account := strings.ToLower(req.Email)
+
+	if err := s.lockout.Check(account, req.IP, now); err != nil {
+		return nil, err
+	}
+
 	u, err := s.userRepository.ReadByEmail(req.Email)
 	if err != nil {
+		var apiErr *errorutils.APIError
+		if !errors.As(err, &apiErr) || apiErr.Code != errorutils.ErrCodeEmailNotFound {
+			return nil, err
+		}
+	}
+
+	hash := s.dummyHash
+	if u != nil {
+		hash = u.EncryptedPassword
+	}
+
+	if err = bcrypt.CompareHashAndPassword([]byte(hash), []byte(req.Password)); err != nil || u == nil {
+		if err = s.lockout.Fail(account, req.IP, now); err != nil {
+			return nil, err
+		}
+
+		return nil, errorutils.New(errorutils.ErrLoginFailed, nil)
+	}
+
+	if u.Suspended(now) {
+		return nil, errorutils.New(errorutils.ErrUserDisabled, nil)
+	}
+
+	if err = s.attemptRepository.Clear(types.LoginAccount, account); err != nil {
 		return nil, err
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(u.EncryptedPassword), []byte(req.Password)); err != nil {
-		return nil, errorutils.New(errorutils.ErrInvalidPassword, err)
+	return s.finishLogin(u)
+}
+
+// finishLogin returns a challenge for users with two-factor authentication on
+// and starts a session for the others.
+func (s *service) finishLogin(u *model.User) (*dto.LoginResponse, error) {
+	if u.TwoFactorEnabled() {
+		challenge, err := createUserToken(s.userTokenRepository, u, types.TwoFactorChallenge, s.config.ChallengeTTL)
+		if err != nil {
+			return nil, err
+		}
+
+		return &dto.LoginResponse{ChallengeToken: challenge}, nil
 	}
 
-	token, err := apputils.CreateJWT(u)
+	res, err := s.startSession(u)
 	if err != nil {
-		return nil, errorutils.New(errorutils.ErrUnexpected, err)
+		return nil, err
 	}
 
-	return &dto.WithTokenResponse{
-		Token: token,
-		Claims: dto.Claims{
-			UID:      u.ID,
-			Role:     u.Role,
-			Username: u.Username,
-			Email:    u.Email,
-		},
-	}, nil
+	return &dto.LoginResponse{WithTokenResponse: res}, nil
 }
 
-func (s *service) Register(req *dto.RegisterRequest) (*dto.WithTokenResponse, error) {
+// ReadsLockouts returns the emails and IPs locked out now.
+func (s *service) ReadsLockouts(p *pagination.Pageable) ([]*dto.LoginAttemptResponse, error) {
+	attempts, err := s.attemptRepository.ReadsLocked(p, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*dto.LoginAttemptResponse, 0, len(*attempts))
+	for _, a := range *attempts {
+		res = append(res, a.ToDTO())
+	}
+
+	return res, nil
+}
+
+// DeleteLockout lifts a lockout and forgets its failures.
+func (s *service) DeleteLockout(req *dto.RequestWithID) error {
+	id, err := apputils.StringToUINT64(req.ID)
+	if err != nil {
+		return errorutils.New(errorutils.ErrInvalidID, err)
+	}
+
+	return s.attemptRepository.Delete(*id)
+}
+
+// Register creates an unverified user and mails them a verification link.
+func (s *service) Register(ctx context.Context, req *dto.RegisterRequest) (*dto.WithTokenResponse, error) {
 	var u model.User
 
 	ep, err := apputils.EncryptPassword(req.Password)
@@ -75,17 +287,301 @@ func (s *service) Register(req *dto.RegisterRequest) (*dto.WithTokenResponse, er
 		return nil, err
 	}
 
-	token, err := apputils.CreateJWT(&u)
+	// The account is usable without the mail; the user can ask for another one.
+	if err = s.verifier.Send(ctx, &u); err != nil {
+		log.Printf("verification mail for user %d: %v", u.ID, err)
+	}
+
+	return s.startSession(&u)
+}
+
+// Refresh trades a refresh token for a new token pair in the same session.
+// A refresh token presented twice means it leaked, so the whole session is revoked.
+func (s *service) Refresh(req *dto.RefreshRequest) (*dto.WithTokenResponse, error) {
+	t, err := s.sessionRepository.ReadRefreshToken(apputils.HashToken(req.RefreshToken))
 	if err != nil {
-		return nil, errorutils.New(errorutils.ErrUnexpected, err)
+		return nil, err
+	}
+
+	revoked, err := s.sessionRepository.Revoked(t.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if revoked {
+		return nil, errorutils.New(errorutils.ErrRevokedToken, nil)
+	}
+
+	now := time.Now()
+
+	fresh, err := s.sessionRepository.UseRefreshToken(t.ID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if !fresh {
+		if err = s.sessionRepository.Revoke(t.SessionID, now); err != nil {
+			return nil, err
+		}
+
+		return nil, errorutils.New(errorutils.ErrRevokedToken, nil)
+	}
+
+	if now.After(t.ExpiresAt) {
+		return nil, errorutils.New(errorutils.ErrExpiredToken, nil)
+	}
+
+	u, err := s.userRepository.Read(t.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Suspended(now) {
+		return nil, errorutils.New(errorutils.ErrUserDisabled, nil)
+	}
+
+	return s.issue(u, t.SessionID, now)
+}
+
+// Logout revokes the session of the refresh token, ending its access tokens too.
+func (s *service) Logout(req *dto.RefreshRequest) error {
+	t, err := s.sessionRepository.ReadRefreshToken(apputils.HashToken(req.RefreshToken))
+	if err != nil {
+		return err
+	}
+
+	return s.sessionRepository.Revoke(t.SessionID, time.Now())
+}
+
+// LogoutAll revokes every session of the caller.
+func (s *service) LogoutAll(ctx context.Context) error {
+	u, err := appcontext.MtsBlogUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	return s.sessionRepository.RevokeAll(u.UID, time.Now())
+}
+
+// startSession opens a new session for u and issues its first token pair.
+func (s *service) startSession(u *model.User) (*dto.WithTokenResponse, error) {
+	sid, err := apputils.RandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	if err = s.sessionRepository.Create(&model.Session{ID: sid, UserID: u.ID, CreatedAt: now}); err != nil {
+		return nil, err
+	}
+
+	return s.issue(u, sid, now)
+}
+
+// issue returns a new access token and refresh token for u in session sid.
+func (s *service) issue(u *model.User, sid string, now time.Time) (*dto.WithTokenResponse, error) {
+	claims := dto.Claims{
+		UID:           u.ID,
+		Role:          u.Role,
+		Username:      u.Username,
+		Email:         u.Email,
+		SessionID:     sid,
+		EmailVerified: u.EmailVerifiedAt != nil,
+		TwoFactor:     u.TwoFactorEnabled(),
+	}
+
+	token, expiresAt, err := s.signer.Sign(&claims, now)
+	if err != nil {
+		return nil, err
+	}
+
+	refresh, err := apputils.RandomToken(refreshTokenSize)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.sessionRepository.CreateRefreshToken(&model.RefreshToken{
+		SessionID: sid,
+		UserID:    u.ID,
+		TokenHash: apputils.HashToken(refresh),
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.config.RefreshTTL),
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return &dto.WithTokenResponse{
-		Token: token,
-		Claims: dto.Claims{
-			Role:     u.Role,
-			Username: u.Username,
-			Email:    u.Email,
-		},
+		Token:        token,
+		ExpiresAt:    expiresAt,
+		RefreshToken: refresh,
+		Claims:       claims,
 	}, nil
 }
+
+// JWKS returns the public keys access tokens can be verified with.
+func (s *service) JWKS() *jwtauth.JWKS {
+	return s.signer.JWKS()
+}
+
+// ForgotPassword mails a password reset link to the user with the email. It
+// succeeds for unknown emails too, so it can not be used to probe for accounts.
+// The link is made and mailed in the background, so answering takes as long
+// for known emails as for unknown ones.
+func (s *service) ForgotPassword(ctx context.Context, req *dto.ForgotPasswordRequest) error {
+	u, err := s.userRepository.ReadByEmail(req.Email)
+	if err != nil {
+		var apiErr *errorutils.APIError
+		if errors.As(err, &apiErr) && apiErr.Code == errorutils.ErrCodeEmailNotFound {
+			return nil
+		}
+
+		return err
+	}
+
+	if u.Suspended(time.Now()) {
+		return nil
+	}
+
+	go s.sendPasswordReset(context.WithoutCancel(ctx), u)
+
+	return nil
+}
+
+// sendPasswordReset mails u a new password reset link. Failures are only
+// logged, since telling the caller would tell them the email is known.
+func (s *service) sendPasswordReset(ctx context.Context, u *model.User) {
+	token, err := createUserToken(s.userTokenRepository, u, types.PasswordReset, s.config.ResetTTL)
+	if err != nil {
+		log.Printf("password reset token for user %d: %v", u.ID, err)
+
+		return
+	}
+
+	err = s.mailer.Send(ctx, &mailer.Message{
+		To:      u.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to choose a new password. It is valid for %s.\n\n%s\n\nIf you did not ask for this, you can ignore this email.",
+			u.Username, s.config.ResetTTL, withToken(s.config.ResetURL, token)),
+	})
+	if err != nil {
+		log.Printf("password reset mail for user %d: %v", u.ID, err)
+	}
+}
+
+// ResetPassword sets a new password with a reset token, ends every session
+// of the user and revokes their API keys.
+func (s *service) ResetPassword(req *dto.ResetPasswordRequest) error {
+	t, err := s.useUserToken(req.Token, types.PasswordReset)
+	if err != nil {
+		return err
+	}
+
+	u, err := s.userRepository.Read(t.UserID)
+	if err != nil {
+		return err
+	}
+
+	ep, err := apputils.EncryptPassword(req.Password)
+	if err != nil {
+		return errorutils.New(errorutils.ErrUnexpected, err)
+	}
+
+	now := time.Now()
+
+	u.EncryptedPassword = ep
+	u.UpdatedAt = now
+	u.UpdatedBy = strconv.FormatUint(u.ID, 10)
+
+	if err = s.userRepository.UpdatePassword(u); err != nil {
+		return err
+	}
+
+	if err = s.userTokenRepository.UseAll(u.ID, types.PasswordReset, now); err != nil {
+		return err
+	}
+
+	if err = s.apiKeyRepository.RevokeAll(u.ID, now); err != nil {
+		return err
+	}
+
+	return s.sessionRepository.RevokeAll(u.ID, now)
+}
+
+// VerifyEmail marks the email of the user the verification token was sent to
+// verified.
+func (s *service) VerifyEmail(req *dto.VerifyEmailRequest) error {
+	t, err := s.useUserToken(req.Token, types.EmailVerification)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	if err = s.userRepository.VerifyEmail(t.UserID, now); err != nil {
+		return err
+	}
+
+	return s.userTokenRepository.UseAll(t.UserID, types.EmailVerification, now)
+}
+
+// ResendVerification mails the caller a new verification link, at most once
+// per cooldown.
+func (s *service) ResendVerification(ctx context.Context) error {
+	claims, err := appcontext.MtsBlogUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	u, err := s.userRepository.Read(claims.UID)
+	if err != nil {
+		return err
+	}
+
+	if u.EmailVerifiedAt != nil {
+		return errorutils.New(errorutils.ErrEmailAlreadyVerified, nil)
+	}
+
+	last, err := s.userTokenRepository.LastCreatedAt(u.ID, types.EmailVerification)
+	if err != nil {
+		return err
+	}
+
+	if last != nil && time.Since(*last) < s.config.ResendCooldown {
+		return errorutils.New(errorutils.ErrVerificationCooldown, nil)
+	}
+
+	// Only the newest link works.
+	if err = s.userTokenRepository.UseAll(u.ID, types.EmailVerification, time.Now()); err != nil {
+		return err
+	}
+
+	return s.verifier.Send(ctx, u)
+}
+
+// useUserToken spends a token issued for purpose, failing if it is unknown,
+// expired or already used.
+func (s *service) useUserToken(token string, purpose types.TokenPurpose) (*model.UserToken, error) {
+	t, err := s.userTokenRepository.Read(apputils.HashToken(token), purpose)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	if now.After(t.ExpiresAt) {
+		return nil, errorutils.New(errorutils.ErrExpiredToken, nil)
+	}
+
+	fresh, err := s.userTokenRepository.Use(t.ID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if !fresh {
+		return nil, errorutils.New(errorutils.ErrInvalidToken, nil)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,295 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/appcontext"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/totp"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/apputils"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
+)
+
+const (
+	// recoveryCodeCount is how many recovery codes a user gets at a time, and
+	// recoveryCodeSize the random bytes in each.
+	recoveryCodeCount = 10
+	recoveryCodeSize  = 5
+)
+
+// LoginTwoFactor finishes a login waiting for a second factor. Wrong codes
+// count towards the lockout of the account like wrong passwords do.
+func (s *service) LoginTwoFactor(req *dto.TwoFactorLoginRequest) (*dto.WithTokenResponse, error) {
+	t, err := s.useUserToken(req.ChallengeToken, types.TwoFactorChallenge)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := s.userRepository.Read(t.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	account := strings.ToLower(u.Email)
+
+	if err = s.lockout.Check(account, req.IP, now); err != nil {
+		return nil, err
+	}
+
+	ok, err := s.secondFactor(u, req.Code, req.RecoveryCode, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		if err = s.lockout.Fail(account, req.IP, now); err != nil {
+			return nil, err
+		}
+
+		return nil, errorutils.New(errorutils.ErrInvalidTwoFactorCode, nil)
+	}
+
+	if u.Suspended(now) {
+		return nil, errorutils.New(errorutils.ErrUserDisabled, nil)
+	}
+
+	if err = s.attemptRepository.Clear(types.LoginAccount, account); err != nil {
+		return nil, err
+	}
+
+	return s.startSession(u)
+}
+
+// EnrollTwoFactor gives the caller a new TOTP secret. It takes effect once
+// confirmed with a code from it.
+func (s *service) EnrollTwoFactor(ctx context.Context) (*dto.TwoFactorEnrollResponse, error) {
+	u, _, err := s.caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.TwoFactorEnabled() {
+		return nil, errorutils.New(errorutils.ErrTwoFactorEnabled, nil)
+	}
+
+	secret, err := totp.NewSecret()
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrUnexpected, err)
+	}
+
+	u.TOTPSecret = secret
+
+	if err = s.userRepository.SetTOTPSecret(u); err != nil {
+		return nil, err
+	}
+
+	return &dto.TwoFactorEnrollResponse{Secret: secret, URI: totp.URI(s.config.TOTPIssuer, u.Email, secret)}, nil
+}
+
+// ConfirmTwoFactor turns on two-factor authentication for the caller once
+// code matches the enrolled secret, ends their other sessions and returns
+// their recovery codes.
+func (s *service) ConfirmTwoFactor(ctx context.Context, req *dto.TwoFactorCodeRequest) (*dto.RecoveryCodesResponse, error) {
+	u, claims, err := s.caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.TwoFactorEnabled() {
+		return nil, errorutils.New(errorutils.ErrTwoFactorEnabled, nil)
+	}
+
+	if u.TOTPSecret == "" {
+		return nil, errorutils.New(errorutils.ErrTwoFactorNotEnrolled, nil)
+	}
+
+	now := time.Now()
+
+	ok, err := s.useTOTP(u, req.Code, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, errorutils.New(errorutils.ErrInvalidTwoFactorCode, nil)
+	}
+
+	if err = s.userRepository.EnableTOTP(u.ID, now); err != nil {
+		return nil, err
+	}
+
+	codes, err := s.newRecoveryCodes(u.ID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.sessionRepository.RevokeOthers(u.ID, claims.SessionID, now); err != nil {
+		return nil, err
+	}
+
+	return &dto.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// DisableTwoFactor turns off two-factor authentication for the caller, who
+// proves it is them with their password and a second factor. Wrong ones count
+// towards the lockout of the account like failed logins do.
+func (s *service) DisableTwoFactor(ctx context.Context, req *dto.TwoFactorDisableRequest) error {
+	u, _, err := s.caller(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !u.TwoFactorEnabled() {
+		return errorutils.New(errorutils.ErrTwoFactorNotEnrolled, nil)
+	}
+
+	now := time.Now()
+	account := strings.ToLower(u.Email)
+
+	if err = s.lockout.Check(account, req.IP, now); err != nil {
+		return err
+	}
+
+	if err = bcrypt.CompareHashAndPassword([]byte(u.EncryptedPassword), []byte(req.Password)); err != nil {
+		if err := s.lockout.Fail(account, req.IP, now); err != nil {
+			return err
+		}
+
+		return errorutils.New(errorutils.ErrInvalidPassword, err)
+	}
+
+	if err = s.proveSecondFactor(u, req.Code, req.RecoveryCode, req.IP, now); err != nil {
+		return err
+	}
+
+	if err = s.userRepository.DisableTOTP(u.ID); err != nil {
+		return err
+	}
+
+	return s.codeRepository.DeleteAll(u.ID)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the caller. Wrong
+// codes count towards the lockout of the account like failed logins do.
+func (s *service) RegenerateRecoveryCodes(ctx context.Context, req *dto.TwoFactorCodeRequest) (*dto.RecoveryCodesResponse, error) {
+	u, _, err := s.caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !u.TwoFactorEnabled() {
+		return nil, errorutils.New(errorutils.ErrTwoFactorNotEnrolled, nil)
+	}
+
+	now := time.Now()
+
+	if err = s.lockout.Check(strings.ToLower(u.Email), req.IP, now); err != nil {
+		return nil, err
+	}
+
+	if err = s.proveSecondFactor(u, req.Code, "", req.IP, now); err != nil {
+		return nil, err
+	}
+
+	codes, err := s.newRecoveryCodes(u.ID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// caller returns the user of ctx.
+func (s *service) caller(ctx context.Context) (*model.User, *dto.Claims, error) {
+	claims, err := appcontext.MtsBlogUser(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	u, err := s.userRepository.Read(claims.UID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return u, claims, nil
+}
+
+// secondFactor checks the TOTP code, or the recovery code when there is no
+// code, of a user with two-factor authentication on. Both work only once.
+func (s *service) secondFactor(u *model.User, code, recoveryCode string, now time.Time) (bool, error) {
+	if !u.TwoFactorEnabled() {
+		return false, nil
+	}
+
+	if code != "" {
+		return s.useTOTP(u, code, now)
+	}
+
+	return s.codeRepository.Use(u.ID, hashRecoveryCode(recoveryCode), now)
+}
+
+// proveSecondFactor checks the second factor of a signed in user, counting a
+// wrong one as a failed login of their account and ip.
+func (s *service) proveSecondFactor(u *model.User, code, recoveryCode, ip string, now time.Time) error {
+	ok, err := s.secondFactor(u, code, recoveryCode, now)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		if err = s.lockout.Fail(strings.ToLower(u.Email), ip, now); err != nil {
+			return err
+		}
+
+		return errorutils.New(errorutils.ErrInvalidTwoFactorCode, nil)
+	}
+
+	return nil
+}
+
+// useTOTP checks code against the secret of u and spends its time step.
+func (s *service) useTOTP(u *model.User, code string, now time.Time) (bool, error) {
+	step, ok := totp.Validate(u.TOTPSecret, code, now)
+	if !ok {
+		return false, nil
+	}
+
+	return s.userRepository.UseTOTPStep(u.ID, step)
+}
+
+// newRecoveryCodes replaces the recovery codes of the user and returns the new ones.
+func (s *service) newRecoveryCodes(userID uint64, now time.Time) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+
+	for i := range codes {
+		b := make([]byte, recoveryCodeSize)
+		if _, err := rand.Read(b); err != nil {
+			return nil, errorutils.New(errorutils.ErrUnexpected, err)
+		}
+
+		c := strings.ToLower(base32.StdEncoding.EncodeToString(b))
+		codes[i] = c[:4] + "-" + c[4:]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+
+	if err := s.codeRepository.Replace(userID, hashes, now); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// hashRecoveryCode hashes code regardless of its case and dashes.
+func hashRecoveryCode(code string) string {
+	return apputils.HashToken(strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", "")))
+}
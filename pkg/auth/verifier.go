@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/repository"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/mailer"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/apputils"
+)
+
+// Verifier mails users a link to verify their email.
+type Verifier interface {
+	Send(ctx context.Context, u *model.User) error
+}
+
+type verifier struct {
+	tokens repository.UserToken
+	mailer mailer.Mailer
+	ttl    time.Duration
+	link   string
+}
+
+// NewVerifier returns a Verifier whose links point to link and stay valid for
+// ttl; zero uses the default.
+func NewVerifier(tokens repository.UserToken, m mailer.Mailer, ttl time.Duration, link string) Verifier {
+	if ttl <= 0 {
+		ttl = defaultVerifyTTL
+	}
+
+	return &verifier{
+		tokens: tokens,
+		mailer: m,
+		ttl:    ttl,
+		link:   link,
+	}
+}
+
+// Send mails u a new email verification link.
+func (v *verifier) Send(ctx context.Context, u *model.User) error {
+	token, err := createUserToken(v.tokens, u, types.EmailVerification, v.ttl)
+	if err != nil {
+		return err
+	}
+
+	return v.mailer.Send(ctx, &mailer.Message{
+		To:      u.Email,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to verify your email. It is valid for %s.\n\n%s",
+			u.Username, v.ttl, withToken(v.link, token)),
+	})
+}
+
+// createUserToken stores a new token for purpose valid for ttl and returns it.
+func createUserToken(tokens repository.UserToken, u *model.User, purpose types.TokenPurpose, ttl time.Duration) (string, error) {
+	token, err := apputils.RandomToken(userTokenSize)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+
+	err = tokens.Create(&model.UserToken{
+		UserID:    u.ID,
+		Purpose:   purpose,
+		TokenHash: apputils.HashToken(token),
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// withToken returns link with token added to its query.
+func withToken(link, token string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return link + "?token=" + url.QueryEscape(token)
+	}
+
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+
+	return u.String()
+}
@@ -0,0 +1,29 @@
+package category
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/rbac"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/repository"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+)
+
+type Router struct {
+	Authenticate       echo.MiddlewareFunc
+	RBAC               rbac.RBAC
+	RouterGroup        *echo.Group
+	CategoryRepository repository.Category
+}
+
+func (r *Router) New() {
+	cs := NewService(r.CategoryRepository)
+	ch := NewHandler(cs)
+
+	cgr := r.RouterGroup.Group("/categories")
+
+	cgr.POST("", ch.Create(), r.Authenticate, r.RBAC.RequirePermission(types.CategoryManage))
+	cgr.GET("/:id", ch.Read())
+	cgr.GET("", ch.Reads())
+	cgr.PUT("/:id", ch.Update(), r.Authenticate, r.RBAC.RequirePermission(types.CategoryManage))
+	cgr.DELETE("/:id", ch.Delete(), r.Authenticate, r.RBAC.RequirePermission(types.CategoryManage))
+}
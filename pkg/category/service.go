@@ -0,0 +1,166 @@
+package category
+
+import (
+	"context"
+	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/appcontext"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/repository"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/pagination"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/slug"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/apputils"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
+)
+
+type Service interface {
+	Create(context.Context, *dto.CategoryCreateRequest) (*dto.CategoryResponse, error)
+	Read(*dto.RequestWithID) (*dto.CategoryResponse, error)
+	Reads(*pagination.Pageable) ([]*dto.CategoryResponse, error)
+	Update(context.Context, *dto.CategoryUpdateRequest) (*dto.CategoryResponse, error)
+	Delete(*dto.RequestWithID) (*dto.ResponseWithID, error)
+}
+
+type service struct {
+	repository repository.Category
+}
+
+func NewService(repository repository.Category) Service {
+	return &service{
+		repository: repository,
+	}
+}
+
+func (s *service) Create(ctx context.Context, req *dto.CategoryCreateRequest) (*dto.CategoryResponse, error) {
+	actor, err := appcontext.MtsBlogActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var c model.Category
+
+	if c.Slug = slug.Make(req.Name); c.Slug == "" {
+		return nil, errorutils.New(errorutils.ErrBadRequest, nil)
+	}
+
+	c.Name = req.Name
+	c.ParentID = req.ParentID
+	c.CreatedAt = time.Now()
+	c.CreatedBy = actor
+	c.UpdatedAt = c.CreatedAt
+	c.UpdatedBy = actor
+
+	if err = s.repository.Create(&c); err != nil {
+		return nil, err
+	}
+
+	return c.ToDTO(), nil
+}
+
+func (s *service) Read(req *dto.RequestWithID) (*dto.CategoryResponse, error) {
+	cid, err := apputils.StringToUINT64(req.ID)
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrInvalidID, err)
+	}
+
+	c, err := s.repository.Read(*cid)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.ToDTO(), nil
+}
+
+func (s *service) Reads(p *pagination.Pageable) ([]*dto.CategoryResponse, error) {
+	categories, err := s.repository.Reads(p)
+	if err != nil {
+		return nil, err
+	}
+
+	var crs []*dto.CategoryResponse
+
+	for _, c := range *categories {
+		crs = append(crs, c.ToDTO())
+	}
+
+	return crs, nil
+}
+
+func (s *service) Update(ctx context.Context, req *dto.CategoryUpdateRequest) (*dto.CategoryResponse, error) {
+	actor, err := appcontext.MtsBlogActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cid, err := apputils.StringToUINT64(req.ID)
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrInvalidID, err)
+	}
+
+	c, err := s.repository.Read(*cid)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Slug = slug.Make(req.Name); c.Slug == "" {
+		return nil, errorutils.New(errorutils.ErrBadRequest, nil)
+	}
+
+	if err = s.checkParent(c.ID, req.ParentID); err != nil {
+		return nil, err
+	}
+
+	c.Name = req.Name
+	c.ParentID = req.ParentID
+	c.UpdatedAt = time.Now()
+	c.UpdatedBy = actor
+
+	if err = s.repository.Update(c); err != nil {
+		return nil, err
+	}
+
+	return c.ToDTO(), nil
+}
+
+// checkParent keeps the hierarchy a tree: a category can't become a child of
+// itself or of one of its own descendants.
+func (s *service) checkParent(id uint64, parentID *uint64) error {
+	if parentID == nil {
+		return nil
+	}
+
+	if *parentID == id {
+		return errorutils.New(errorutils.ErrCategoryParent, nil)
+	}
+
+	if _, err := s.repository.Read(*parentID); err != nil {
+		return err
+	}
+
+	ancestors, err := s.repository.AncestorIDs(*parentID)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range ancestors {
+		if a == id {
+			return errorutils.New(errorutils.ErrCategoryParent, nil)
+		}
+	}
+
+	return nil
+}
+
+func (s *service) Delete(req *dto.RequestWithID) (*dto.ResponseWithID, error) {
+	cid, err := apputils.StringToUINT64(req.ID)
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrInvalidID, err)
+	}
+
+	if err = s.repository.Delete(*cid); err != nil {
+		return nil, err
+	}
+
+	return &dto.ResponseWithID{ID: req.ID}, nil
+}
@@ -2,10 +2,12 @@ package comment
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/labstack/echo/v4"
 
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/etag"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/pagination"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/echoutils"
 )
@@ -13,7 +15,11 @@ import (
 type Handler interface {
 	Create() echo.HandlerFunc
 	ReadsByPostID() echo.HandlerFunc
+	Update() echo.HandlerFunc
+	Revisions() echo.HandlerFunc
 	Delete() echo.HandlerFunc
+	Restore() echo.HandlerFunc
+	Purge() echo.HandlerFunc
 }
 
 type handler struct {
@@ -54,24 +60,62 @@ func (h *handler) ReadsByPostID() echo.HandlerFunc {
 			return err
 		}
 
-		res, err := h.service.ReadsByPostID(p, r.PostID)
+		res, err := h.service.ReadsByPostID(c.Request().Context(), p, r)
 		if err != nil {
 			return err
 		}
 
 		p.PaginationHeader(c)
 
+		return echoutils.JSONWithETag(c, http.StatusOK, listETag(p, res), res)
+	}
+}
+
+func (h *handler) Update() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := new(dto.CommentUpdateRequest)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		r.IfMatch = echoutils.IfMatch(c)
+
+		res, err := h.service.Update(c.Request().Context(), r)
+		if err != nil {
+			return err
+		}
+
+		c.Response().Header().Set(etag.HeaderETag, etag.Make(res.ID, res.UpdatedAt))
+
 		return c.JSON(http.StatusOK, res)
 	}
 }
 
-func (h *handler) Delete() echo.HandlerFunc {
+func (h *handler) Revisions() echo.HandlerFunc {
 	return func(c echo.Context) error {
 		r := new(dto.RequestWithID)
 		if err := echoutils.BindAndValidate(c, r); err != nil {
 			return err
 		}
 
+		res, err := h.service.Revisions(r)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+func (h *handler) Delete() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := new(dto.DeleteRequest)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		r.IfMatch = echoutils.IfMatch(c)
+
 		res, err := h.service.Delete(c.Request().Context(), r)
 		if err != nil {
 			return err
@@ -80,3 +124,52 @@ func (h *handler) Delete() echo.HandlerFunc {
 		return c.JSON(http.StatusOK, res)
 	}
 }
+
+func (h *handler) Restore() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := new(dto.RequestWithID)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		res, err := h.service.Restore(c.Request().Context(), r)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+func (h *handler) Purge() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := new(dto.RequestWithID)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		res, err := h.service.Purge(r)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+// listETag tags a page of comments by the version of each comment and reply on it.
+func listETag(p *pagination.Pageable, comments []*dto.CommentResponse) string {
+	parts := []string{strconv.FormatInt(p.TotalCount, 10)}
+
+	var add func([]*dto.CommentResponse)
+	add = func(cs []*dto.CommentResponse) {
+		for _, c := range cs {
+			parts = append(parts, etag.Make(c.ID, c.UpdatedAt))
+			add(c.Replies)
+		}
+	}
+
+	add(comments)
+
+	return etag.Weak(parts...)
+}
@@ -9,19 +9,39 @@ import (
 )
 
 type Router struct {
-	Authenticate      echo.MiddlewareFunc
-	RBAC              rbac.RBAC
-	RouterGroup       *echo.Group
-	CommentRepository repository.Comment
+	Authenticate         echo.MiddlewareFunc
+	OptionalAuthenticate echo.MiddlewareFunc
+	RBAC                 rbac.RBAC
+	RouterGroup          *echo.Group
+	CommentRepository    repository.Comment
+	// PostRepository is used to keep comments of hidden posts hidden.
+	PostRepository repository.Post
+	// MaxDepth is how deep replies may be nested; zero uses the default.
+	MaxDepth int
+	// Moderation decides which new comments wait for a mod; empty approves all.
+	Moderation types.ModerationPolicy
+	// TrustAfter is how many approved comments skip the queue under types.ModerateTrusted.
+	TrustAfter int
+	// RequireVerified keeps users who have not verified their email from commenting.
+	RequireVerified bool
 }
 
 func (r *Router) New() {
-	cs := NewService(r.RBAC, r.CommentRepository)
+	cs := NewService(r.RBAC, r.CommentRepository, r.PostRepository, r.MaxDepth, r.Moderation, r.TrustAfter)
 	ch := NewHandler(cs)
 
 	cgr := r.RouterGroup.Group("/comments")
 
-	cgr.POST("", ch.Create(), r.Authenticate, r.RBAC.HasRole(types.Registered))
-	cgr.GET("/:pid", ch.ReadsByPostID())
-	cgr.DELETE("/:id", ch.Delete(), r.Authenticate, r.RBAC.HasRole(types.Registered))
+	create := []echo.MiddlewareFunc{r.Authenticate, r.RBAC.RequirePermission(types.CommentCreate)}
+	if r.RequireVerified {
+		create = append(create, r.RBAC.Verified())
+	}
+
+	cgr.POST("", ch.Create(), create...)
+	cgr.GET("/:pid", ch.ReadsByPostID(), r.OptionalAuthenticate)
+	cgr.PUT("/:id", ch.Update(), r.Authenticate, r.RBAC.SignedIn())
+	cgr.GET("/:id/revisions", ch.Revisions(), r.Authenticate, r.RBAC.RequirePermission(types.CommentModerate))
+	cgr.DELETE("/:id", ch.Delete(), r.Authenticate, r.RBAC.SignedIn())
+	cgr.POST("/:id/restore", ch.Restore(), r.Authenticate, r.RBAC.RequirePermission(types.CommentDelete))
+	cgr.DELETE("/:id/purge", ch.Purge(), r.Authenticate, r.RBAC.RequirePermission(types.CommentDelete))
 }
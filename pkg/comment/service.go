@@ -9,6 +9,7 @@ import (
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/rbac"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/repository"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/etag"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/pagination"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/apputils"
@@ -17,19 +18,48 @@ import (
 
 type Service interface {
 	Create(context.Context, *dto.CommentCreateRequest) error
-	ReadsByPostID(p *pagination.Pageable, pid string) ([]*dto.CommentResponse, error)
-	Delete(context.Context, *dto.RequestWithID) (*dto.ResponseWithID, error)
+	ReadsByPostID(context.Context, *pagination.Pageable, *dto.ByPostIDRequest) ([]*dto.CommentResponse, error)
+	Update(context.Context, *dto.CommentUpdateRequest) (*dto.CommentResponse, error)
+	Revisions(*dto.RequestWithID) ([]*dto.CommentRevisionResponse, error)
+	Delete(context.Context, *dto.DeleteRequest) (*dto.ResponseWithID, error)
+	Restore(context.Context, *dto.RequestWithID) (*dto.ResponseWithID, error)
+	Purge(*dto.RequestWithID) (*dto.ResponseWithID, error)
 }
 
+// defaultMaxDepth is used when no max reply depth is configured.
+const defaultMaxDepth = 5
+
 type service struct {
-	repository repository.Comment
-	rbac       rbac.RBAC
+	repository     repository.Comment
+	postRepository repository.Post
+	rbac           rbac.RBAC
+	maxDepth       int
+	policy         types.ModerationPolicy
+	trustAfter     int
 }
 
-func NewService(rbac rbac.RBAC, repository repository.Comment) Service {
+// NewService returns the comment service; replies may be nested maxDepth levels deep.
+// New comments are approved or held for moderation according to policy; under
+// types.ModerateTrusted a user needs trustAfter approved comments to skip the queue.
+// Comments are only read and written on the posts in posts the caller may see.
+func NewService(rbac rbac.RBAC, repository repository.Comment, posts repository.Post, maxDepth int, policy types.ModerationPolicy,
+	trustAfter int,
+) Service {
+	if maxDepth <= 0 {
+		maxDepth = defaultMaxDepth
+	}
+
+	if policy == "" {
+		policy = types.ModerateNone
+	}
+
 	return &service{
-		repository: repository,
-		rbac:       rbac,
+		repository:     repository,
+		postRepository: posts,
+		rbac:           rbac,
+		maxDepth:       maxDepth,
+		policy:         policy,
+		trustAfter:     trustAfter,
 	}
 }
 
@@ -44,14 +74,52 @@ func (s *service) Create(ctx context.Context, req *dto.CommentCreateRequest) err
 		return err
 	}
 
+	actor, err := appcontext.MtsBlogActor(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err = s.visiblePost(ctx, *pid); err != nil {
+		return err
+	}
+
 	var c model.Comment
 
+	if req.ParentID != nil {
+		parent, err := s.repository.Read(*req.ParentID)
+		if err != nil {
+			return err
+		}
+
+		if parent.State != types.Approved {
+			return errorutils.New(errorutils.ErrCommentNotFound, nil)
+		}
+
+		if parent.PostID != *pid {
+			return errorutils.New(errorutils.ErrCommentParent, nil)
+		}
+
+		if parent.Depth+1 > s.maxDepth {
+			return errorutils.New(errorutils.ErrCommentDepth, nil)
+		}
+
+		c.ParentID = &parent.ID
+		c.Depth = parent.Depth + 1
+	}
+
+	if c.State, err = s.initialState(ctx, u.UID, 0); err != nil {
+		return err
+	}
+
 	c.Author = u.Username
 	c.CreatedAt = time.Now()
+	c.UpdatedAt = c.CreatedAt
+	c.CreatedBy = actor
 	c.Status = types.Active
 	c.PostID = *pid
 	c.UserID = u.UID
 	c.Text = req.Text
+	c.UpdatedBy = actor
 
 	err = s.repository.Create(&c)
 	if err != nil {
@@ -61,12 +129,62 @@ func (s *service) Create(ctx context.Context, req *dto.CommentCreateRequest) err
 	return nil
 }
 
-func (s *service) ReadsByPostID(p *pagination.Pageable, pid string) ([]*dto.CommentResponse, error) {
-	comments, err := s.repository.ReadsByPostID(p, pid)
+// initialState returns the moderation state a new comment of the user starts
+// in. own approved comments are left out of the trust count, so an edited
+// comment does not vouch for itself.
+func (s *service) initialState(ctx context.Context, uid uint64, own int) (types.CommentState, error) {
+	if s.rbac.Can(ctx, types.CommentModerate) {
+		return types.Approved, nil
+	}
+
+	switch s.policy {
+	case types.ModerateAll:
+		return types.Pending, nil
+	case types.ModerateTrusted:
+		n, err := s.repository.CountApproved(uid)
+		if err != nil {
+			return "", err
+		}
+
+		if n-own < s.trustAfter {
+			return types.Pending, nil
+		}
+	}
+
+	return types.Approved, nil
+}
+
+func (s *service) ReadsByPostID(ctx context.Context, p *pagination.Pageable, req *dto.ByPostIDRequest) ([]*dto.CommentResponse, error) {
+	if req.IncludeDeleted && !s.rbac.Can(ctx, types.CommentDelete) {
+		return nil, errorutils.New(errorutils.ErrUnauthorized, nil)
+	}
+
+	pid, err := apputils.StringToUINT64(req.PostID)
 	if err != nil {
+		return nil, errorutils.New(errorutils.ErrInvalidID, err)
+	}
+
+	if err = s.visiblePost(ctx, *pid); err != nil {
 		return nil, err
 	}
 
+	var f repository.CommentFilter
+
+	f.IncludeDeleted = req.IncludeDeleted
+	f.TopLevel = req.Tree
+	f.State = types.Approved
+
+	comments, err := s.repository.ReadsByPostID(p, req.PostID, f)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Tree {
+		if err = s.attachReplies(*comments, f); err != nil {
+			return nil, err
+		}
+	}
+
 	var crs []*dto.CommentResponse
 
 	for _, c := range *comments {
@@ -76,7 +194,57 @@ func (s *service) ReadsByPostID(p *pagination.Pageable, pid string) ([]*dto.Comm
 	return crs, nil
 }
 
-func (s *service) Delete(ctx context.Context, req *dto.RequestWithID) (*dto.ResponseWithID, error) {
+// visiblePost fails with not found unless the post exists and the caller may
+// read it, as the post endpoints decide.
+func (s *service) visiblePost(ctx context.Context, id uint64) error {
+	p, err := s.postRepository.Read(id)
+	if err != nil {
+		return err
+	}
+
+	if p.State != types.Published && !s.rbac.Can(ctx, types.PostReadUnpublished) {
+		return errorutils.New(errorutils.ErrPostNotFound, nil)
+	}
+
+	return nil
+}
+
+// attachReplies loads the replies below roots and nests them under their parents.
+func (s *service) attachReplies(roots []model.Comment, f repository.CommentFilter) error {
+	if len(roots) == 0 {
+		return nil
+	}
+
+	byID := make(map[uint64]*model.Comment, len(roots))
+	ids := make([]uint64, 0, len(roots))
+
+	for i := range roots {
+		byID[roots[i].ID] = &roots[i]
+		ids = append(ids, roots[i].ID)
+	}
+
+	replies, err := s.repository.ReadReplies(ids, f)
+	if err != nil {
+		return err
+	}
+
+	// Replies come ordered by depth, so a parent is always indexed before its children.
+	for i := range replies {
+		r := &replies[i]
+		byID[r.ID] = r
+
+		if parent, ok := byID[*r.ParentID]; ok {
+			parent.Replies = append(parent.Replies, r)
+		}
+	}
+
+	return nil
+}
+
+// Update lets the author or a mod change a comment's text; the old text is kept
+// as a revision. An approved comment edited by its author goes back to the
+// queue when the policy would hold it as a new comment.
+func (s *service) Update(ctx context.Context, req *dto.CommentUpdateRequest) (*dto.CommentResponse, error) {
 	cid, err := apputils.StringToUINT64(req.ID)
 	if err != nil {
 		return nil, errorutils.New(errorutils.ErrInvalidID, err)
@@ -87,11 +255,131 @@ func (s *service) Delete(ctx context.Context, req *dto.RequestWithID) (*dto.Resp
 		return nil, err
 	}
 
-	if !s.rbac.IsMe(ctx, c.UserID) && !s.rbac.IsModAuthorized(ctx) {
-		return nil, errorutils.New(errorutils.ErrUnauthorized, nil)
+	if _, err = s.rbac.CheckOwnerOrPermission(ctx, c.UserID, types.CommentModerate); err != nil {
+		return nil, err
+	}
+
+	if err = etag.Check(req.IfMatch, etag.Make(c.ID, c.UpdatedAt)); err != nil {
+		return nil, err
+	}
+
+	if c.Text == req.Text {
+		return c.ToDTO(), nil
+	}
+
+	actor, err := appcontext.MtsBlogActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, version := c.Text, c.UpdatedAt
+
+	if c.State == types.Approved {
+		if c.State, err = s.initialState(ctx, c.UserID, 1); err != nil {
+			return nil, err
+		}
+	}
+
+	c.Text = req.Text
+	c.UpdatedAt = time.Now()
+	c.UpdatedBy = actor
+
+	if err = s.repository.Update(c, previous, version); err != nil {
+		return nil, err
+	}
+
+	return c.ToDTO(), nil
+}
+
+func (s *service) Revisions(req *dto.RequestWithID) ([]*dto.CommentRevisionResponse, error) {
+	cid, err := apputils.StringToUINT64(req.ID)
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrInvalidID, err)
+	}
+
+	if _, err = s.repository.Read(*cid); err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.repository.ReadRevisions(*cid)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*dto.CommentRevisionResponse, 0, len(revisions))
+	for _, rv := range revisions {
+		res = append(res, rv.ToDTO())
+	}
+
+	return res, nil
+}
+
+func (s *service) Delete(ctx context.Context, req *dto.DeleteRequest) (*dto.ResponseWithID, error) {
+	cid, err := apputils.StringToUINT64(req.ID)
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrInvalidID, err)
+	}
+
+	c, err := s.repository.Read(*cid)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = s.rbac.CheckOwnerOrPermission(ctx, c.UserID, types.CommentModerate); err != nil {
+		return nil, err
+	}
+
+	if err = etag.Check(req.IfMatch, etag.Make(c.ID, c.UpdatedAt)); err != nil {
+		return nil, err
+	}
+
+	actor, err := appcontext.MtsBlogActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	c.DeletedAt = &now
+	c.DeletedBy = actor
+
+	if err = s.repository.Delete(c, c.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	return &dto.ResponseWithID{ID: req.ID}, nil
+}
+
+func (s *service) Restore(ctx context.Context, req *dto.RequestWithID) (*dto.ResponseWithID, error) {
+	actor, err := appcontext.MtsBlogActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cid, err := apputils.StringToUINT64(req.ID)
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrInvalidID, err)
+	}
+
+	var c model.Comment
+
+	c.ID = *cid
+	c.UpdatedAt = time.Now()
+	c.UpdatedBy = actor
+
+	if err = s.repository.Restore(&c); err != nil {
+		return nil, err
+	}
+
+	return &dto.ResponseWithID{ID: req.ID}, nil
+}
+
+func (s *service) Purge(req *dto.RequestWithID) (*dto.ResponseWithID, error) {
+	cid, err := apputils.StringToUINT64(req.ID)
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrInvalidID, err)
 	}
 
-	if err = s.repository.Delete(*cid); err != nil {
+	if err = s.repository.Purge(*cid); err != nil {
 		return nil, err
 	}
 
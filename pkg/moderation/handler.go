@@ -0,0 +1,70 @@
+package moderation
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/pagination"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/echoutils"
+)
+
+type Handler interface {
+	Queue() echo.HandlerFunc
+	Moderate(types.CommentState) echo.HandlerFunc
+}
+
+type handler struct {
+	service Service
+}
+
+func NewHandler(service Service) Handler {
+	return &handler{
+		service: service,
+	}
+}
+
+func (h *handler) Queue() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		// Oldest first, so the queue is worked through in order.
+		p := pagination.NewPagination()
+		p.Sort = "createdAt,asc"
+
+		if err := echoutils.BindAndValidate(c, p); err != nil {
+			return err
+		}
+
+		r := new(dto.ModerationQueueRequest)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		res, err := h.service.Queue(p, r)
+		if err != nil {
+			return err
+		}
+
+		p.PaginationHeader(c)
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+// Moderate returns a handler moving the requested comments to state.
+func (h *handler) Moderate(state types.CommentState) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := new(dto.ModerationRequest)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		res, err := h.service.Moderate(c.Request().Context(), state, r)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
@@ -0,0 +1,28 @@
+package moderation
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/rbac"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/repository"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+)
+
+type Router struct {
+	Authenticate      echo.MiddlewareFunc
+	RBAC              rbac.RBAC
+	RouterGroup       *echo.Group
+	CommentRepository repository.Comment
+}
+
+func (r *Router) New() {
+	ms := NewService(r.CommentRepository)
+	mh := NewHandler(ms)
+
+	mgr := r.RouterGroup.Group("/moderation")
+
+	mgr.GET("/comments", mh.Queue(), r.Authenticate, r.RBAC.RequirePermission(types.CommentModerate))
+	mgr.POST("/comments/approve", mh.Moderate(types.Approved), r.Authenticate, r.RBAC.RequirePermission(types.CommentModerate))
+	mgr.POST("/comments/reject", mh.Moderate(types.Rejected), r.Authenticate, r.RBAC.RequirePermission(types.CommentModerate))
+	mgr.POST("/comments/spam", mh.Moderate(types.Spam), r.Authenticate, r.RBAC.RequirePermission(types.CommentModerate))
+}
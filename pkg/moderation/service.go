@@ -0,0 +1,63 @@
+package moderation
+
+import (
+	"context"
+	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/appcontext"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/repository"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/pagination"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+)
+
+type Service interface {
+	Queue(*pagination.Pageable, *dto.ModerationQueueRequest) ([]*dto.CommentResponse, error)
+	Moderate(context.Context, types.CommentState, *dto.ModerationRequest) (*dto.ModerationResponse, error)
+}
+
+type service struct {
+	comments repository.Comment
+}
+
+func NewService(comments repository.Comment) Service {
+	return &service{
+		comments: comments,
+	}
+}
+
+// Queue lists the comments in a moderation state, pending ones by default.
+func (s *service) Queue(p *pagination.Pageable, req *dto.ModerationQueueRequest) ([]*dto.CommentResponse, error) {
+	var f repository.CommentFilter
+
+	f.State = types.Pending
+	if req.State != "" {
+		f.State = types.CommentState(req.State)
+	}
+
+	comments, err := s.comments.Reads(p, f)
+	if err != nil {
+		return nil, err
+	}
+
+	crs := make([]*dto.CommentResponse, 0, len(*comments))
+	for _, c := range *comments {
+		crs = append(crs, c.ToDTO())
+	}
+
+	return crs, nil
+}
+
+func (s *service) Moderate(ctx context.Context, state types.CommentState, req *dto.ModerationRequest) (*dto.ModerationResponse, error) {
+	actor, err := appcontext.MtsBlogActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := s.comments.Moderate(req.IDs, state, actor, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ModerationResponse{State: state, IDs: ids}, nil
+}
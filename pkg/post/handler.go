@@ -2,10 +2,12 @@ package post
 
 import (
 	"net/http"
+	"path"
 
 	"github.com/labstack/echo/v4"
 
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/etag"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/pagination"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/echoutils"
 )
@@ -13,9 +15,19 @@ import (
 type Handler interface {
 	Create() echo.HandlerFunc
 	Read() echo.HandlerFunc
+	ReadBySlug() echo.HandlerFunc
 	Reads() echo.HandlerFunc
+	ReadsByUserID() echo.HandlerFunc
 	Update() echo.HandlerFunc
 	Delete() echo.HandlerFunc
+	Restore() echo.HandlerFunc
+	Purge() echo.HandlerFunc
+	Publish() echo.HandlerFunc
+	Unpublish() echo.HandlerFunc
+	Archive() echo.HandlerFunc
+	Revisions() echo.HandlerFunc
+	Diff() echo.HandlerFunc
+	RestoreRevision() echo.HandlerFunc
 }
 
 type handler struct {
@@ -35,7 +47,7 @@ func (h *handler) Create() echo.HandlerFunc {
 			return err
 		}
 
-		err := h.service.Create(r)
+		err := h.service.Create(c.Request().Context(), r)
 		if err != nil {
 			return err
 		}
@@ -51,12 +63,35 @@ func (h *handler) Read() echo.HandlerFunc {
 			return err
 		}
 
-		p, err := h.service.Read(r)
+		p, err := h.service.Read(c.Request().Context(), r)
 		if err != nil {
 			return err
 		}
 
-		return c.JSON(http.StatusOK, p)
+		return echoutils.JSONWithETag(c, http.StatusOK, etag.Make(p.ID, p.UpdatedAt), p)
+	}
+}
+
+func (h *handler) ReadBySlug() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := new(dto.PostSlugRequest)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		p, err := h.service.ReadBySlug(c.Request().Context(), r)
+		if err != nil {
+			return err
+		}
+
+		// An old slug still resolves, but points clients at the current one.
+		if p.Slug != r.Slug {
+			c.Response().Header().Set(echo.HeaderLocation, path.Join(path.Dir(c.Request().URL.Path), p.Slug))
+
+			return c.JSON(http.StatusMovedPermanently, p)
+		}
+
+		return echoutils.JSONWithETag(c, http.StatusOK, etag.Make(p.ID, p.UpdatedAt), p)
 	}
 }
 
@@ -67,7 +102,35 @@ func (h *handler) Reads() echo.HandlerFunc {
 			return err
 		}
 
-		res, err := h.service.Reads(p)
+		r := new(dto.PostReadsRequest)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		res, err := h.service.Reads(c.Request().Context(), p, r)
+		if err != nil {
+			return err
+		}
+
+		p.PaginationHeader(c)
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+func (h *handler) ReadsByUserID() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		p := pagination.NewPagination()
+		if err := echoutils.BindAndValidate(c, p); err != nil {
+			return err
+		}
+
+		r := new(dto.ByUserIDRequest)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		res, err := h.service.ReadsByUserID(c.Request().Context(), p, r)
 		if err != nil {
 			return err
 		}
@@ -85,23 +148,160 @@ func (h *handler) Update() echo.HandlerFunc {
 			return err
 		}
 
-		res, err := h.service.Update(r)
+		r.IfMatch = echoutils.IfMatch(c)
+
+		res, err := h.service.Update(c.Request().Context(), r)
 		if err != nil {
 			return err
 		}
 
+		// Nothing changed when res is nil.
+		if res != nil {
+			c.Response().Header().Set(etag.HeaderETag, etag.Make(res.ID, res.UpdatedAt))
+		}
+
 		return c.JSON(http.StatusOK, res)
 	}
 }
 
 func (h *handler) Delete() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := new(dto.DeleteRequest)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		r.IfMatch = echoutils.IfMatch(c)
+
+		res, err := h.service.Delete(c.Request().Context(), r)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+func (h *handler) Restore() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := new(dto.RequestWithID)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		res, err := h.service.Restore(c.Request().Context(), r)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+func (h *handler) Purge() echo.HandlerFunc {
 	return func(c echo.Context) error {
 		r := new(dto.RequestWithID)
 		if err := echoutils.BindAndValidate(c, r); err != nil {
 			return err
 		}
 
-		res, err := h.service.Delete(r)
+		res, err := h.service.Purge(r)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+func (h *handler) Publish() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := new(dto.PostPublishRequest)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		res, err := h.service.Publish(c.Request().Context(), r)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+func (h *handler) Unpublish() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := new(dto.RequestWithID)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		res, err := h.service.Unpublish(c.Request().Context(), r)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+func (h *handler) Archive() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := new(dto.RequestWithID)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		res, err := h.service.Archive(c.Request().Context(), r)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+func (h *handler) Revisions() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := new(dto.RequestWithID)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		res, err := h.service.Revisions(c.Request().Context(), r)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+func (h *handler) Diff() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := new(dto.PostDiffRequest)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		res, err := h.service.Diff(c.Request().Context(), r)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+func (h *handler) RestoreRevision() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := new(dto.PostRevisionRequest)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		res, err := h.service.RestoreRevision(c.Request().Context(), r)
 		if err != nil {
 			return err
 		}
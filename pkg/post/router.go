@@ -9,21 +9,36 @@ import (
 )
 
 type Router struct {
-	Authenticate   echo.MiddlewareFunc
-	RBAC           rbac.RBAC
-	RouterGroup    *echo.Group
-	PostRepository repository.Post
+	Authenticate         echo.MiddlewareFunc
+	OptionalAuthenticate echo.MiddlewareFunc
+	RBAC                 rbac.RBAC
+	RouterGroup          *echo.Group
+	PostRepository       repository.Post
+	TagRepository        repository.Tag
+	CategoryRepository   repository.Category
 }
 
 func (r *Router) New() {
-	ps := NewService(r.PostRepository)
+	ps := NewService(r.RBAC, r.PostRepository, r.TagRepository, r.CategoryRepository)
 	ph := NewHandler(ps)
 
 	pgr := r.RouterGroup.Group("/posts")
 
-	pgr.POST("", ph.Create(), r.Authenticate, r.RBAC.HasRole(types.Mod))
-	pgr.GET("/:id", ph.Read())
-	pgr.GET("", ph.Reads())
-	pgr.PUT("/:id", ph.Update(), r.Authenticate, r.RBAC.HasRole(types.Mod))
-	pgr.DELETE("/:id", ph.Delete(), r.Authenticate, r.RBAC.HasRole(types.Admin))
+	pgr.POST("", ph.Create(), r.Authenticate, r.RBAC.RequirePermission(types.PostCreate))
+	pgr.GET("/:id", ph.Read(), r.OptionalAuthenticate)
+	pgr.GET("/slug/:slug", ph.ReadBySlug(), r.OptionalAuthenticate)
+	pgr.GET("", ph.Reads(), r.OptionalAuthenticate)
+	pgr.PUT("/:id", ph.Update(), r.Authenticate, r.RBAC.RequirePermission(types.PostUpdate))
+	pgr.DELETE("/:id", ph.Delete(), r.Authenticate, r.RBAC.RequirePermission(types.PostDelete))
+	pgr.POST("/:id/restore", ph.Restore(), r.Authenticate, r.RBAC.RequirePermission(types.PostDelete))
+	pgr.DELETE("/:id/purge", ph.Purge(), r.Authenticate, r.RBAC.RequirePermission(types.PostDelete))
+	pgr.POST("/:id/publish", ph.Publish(), r.Authenticate, r.RBAC.RequirePermission(types.PostUpdate))
+	pgr.POST("/:id/unpublish", ph.Unpublish(), r.Authenticate, r.RBAC.RequirePermission(types.PostUpdate))
+	pgr.POST("/:id/archive", ph.Archive(), r.Authenticate, r.RBAC.RequirePermission(types.PostUpdate))
+	pgr.GET("/:id/revisions", ph.Revisions(), r.Authenticate, r.RBAC.RequirePermission(types.PostUpdate))
+	pgr.GET("/:id/revisions/diff", ph.Diff(), r.Authenticate, r.RBAC.RequirePermission(types.PostUpdate))
+	pgr.POST("/:id/revisions/:rev/restore", ph.RestoreRevision(), r.Authenticate, r.RBAC.RequirePermission(types.PostUpdate))
+
+	// An author's posts, registered on the root group so it stays public.
+	r.RouterGroup.GET("/users/:id/posts", ph.ReadsByUserID(), r.OptionalAuthenticate)
 }
@@ -1,53 +1,175 @@
 package post
 
 import (
+	"context"
+	"strconv"
 	"time"
 
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/appcontext"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/rbac"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/repository"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/diff"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/etag"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/pagination"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/slug"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/apputils"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
 )
 
 type Service interface {
-	Create(*dto.PostCreateRequest) error
-	Read(*dto.RequestWithID) (*dto.PostResponse, error)
-	Reads(*pagination.Pageable) ([]*dto.PostResponse, error)
-	Update(*dto.PostUpdateRequest) (*dto.PostResponse, error)
-	Delete(*dto.RequestWithID) (*dto.ResponseWithID, error)
+	Create(context.Context, *dto.PostCreateRequest) error
+	Read(context.Context, *dto.RequestWithID) (*dto.PostResponse, error)
+	ReadBySlug(context.Context, *dto.PostSlugRequest) (*dto.PostResponse, error)
+	Reads(context.Context, *pagination.Pageable, *dto.PostReadsRequest) ([]*dto.PostResponse, error)
+	ReadsByUserID(context.Context, *pagination.Pageable, *dto.ByUserIDRequest) ([]*dto.PostResponse, error)
+	Update(context.Context, *dto.PostUpdateRequest) (*dto.PostResponse, error)
+	Delete(context.Context, *dto.DeleteRequest) (*dto.ResponseWithID, error)
+	Restore(context.Context, *dto.RequestWithID) (*dto.ResponseWithID, error)
+	Purge(*dto.RequestWithID) (*dto.ResponseWithID, error)
+	Publish(context.Context, *dto.PostPublishRequest) (*dto.PostResponse, error)
+	Unpublish(context.Context, *dto.RequestWithID) (*dto.PostResponse, error)
+	Archive(context.Context, *dto.RequestWithID) (*dto.PostResponse, error)
+	Revisions(context.Context, *dto.RequestWithID) ([]*dto.PostRevisionResponse, error)
+	Diff(context.Context, *dto.PostDiffRequest) (*dto.PostDiffResponse, error)
+	RestoreRevision(context.Context, *dto.PostRevisionRequest) (*dto.PostResponse, error)
 }
 
 type service struct {
 	repository repository.Post
+	tags       repository.Tag
+	categories repository.Category
+	rbac       rbac.RBAC
 }
 
-func NewService(repository repository.Post) Service {
+func NewService(rbac rbac.RBAC, repository repository.Post, tags repository.Tag, categories repository.Category) Service {
 	return &service{
 		repository: repository,
+		tags:       tags,
+		categories: categories,
+		rbac:       rbac,
 	}
 }
 
-func (s *service) Create(req *dto.PostCreateRequest) error {
+func (s *service) Create(ctx context.Context, req *dto.PostCreateRequest) error {
+	claims, err := appcontext.MtsBlogUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	actor, err := appcontext.MtsBlogActor(ctx)
+	if err != nil {
+		return err
+	}
+
+	state := types.Draft
+	if req.State != "" {
+		state = types.PostState(req.State)
+	}
+
 	var u model.Post
 
+	if err = u.SetState(state, req.PublishAt, time.Now()); err != nil {
+		return err
+	}
+
+	if u.Slug, err = s.uniqueSlug(req.Title, 0); err != nil {
+		return err
+	}
+
+	tagIDs, err := s.tagIDs(req.Tags)
+	if err != nil {
+		return err
+	}
+
+	categoryIDs, err := s.categoryIDs(req.Categories)
+	if err != nil {
+		return err
+	}
+
+	u.Author = claims.Username
 	u.Body = req.Body
+	u.CreatedBy = actor
 	u.CreatedAt = time.Now()
 	u.Status = types.Active
 	u.UpdatedAt = time.Now()
 	u.Title = req.Title
+	u.UpdatedBy = actor
+	u.UserID = claims.UID
+
+	return s.repository.Create(&u, tagIDs, categoryIDs)
+}
 
-	err := s.repository.Create(&u)
+// tagIDs resolves tag names or slugs to the ids of existing tags.
+func (s *service) tagIDs(names []string) ([]uint64, error) {
+	slugs := slugs(names)
+	if len(slugs) == 0 {
+		return nil, nil
+	}
+
+	tags, err := s.tags.ReadsBySlugs(slugs)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if len(tags) != len(slugs) {
+		return nil, errorutils.New(errorutils.ErrTagNotFound, nil)
+	}
+
+	ids := make([]uint64, 0, len(tags))
+	for _, t := range tags {
+		ids = append(ids, t.ID)
 	}
 
-	return nil
+	return ids, nil
 }
 
-func (s *service) Read(req *dto.RequestWithID) (*dto.PostResponse, error) {
+// categoryIDs resolves category names or slugs to the ids of existing categories.
+func (s *service) categoryIDs(names []string) ([]uint64, error) {
+	slugs := slugs(names)
+	if len(slugs) == 0 {
+		return nil, nil
+	}
+
+	categories, err := s.categories.ReadsBySlugs(slugs)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(categories) != len(slugs) {
+		return nil, errorutils.New(errorutils.ErrCategoryNotFound, nil)
+	}
+
+	ids := make([]uint64, 0, len(categories))
+	for _, c := range categories {
+		ids = append(ids, c.ID)
+	}
+
+	return ids, nil
+}
+
+// slugs normalises names to unique slugs.
+func slugs(names []string) []string {
+	seen := make(map[string]bool, len(names))
+
+	var out []string
+
+	for _, n := range names {
+		s := slug.Make(n)
+		if s == "" || seen[s] {
+			continue
+		}
+
+		seen[s] = true
+		out = append(out, s)
+	}
+
+	return out
+}
+
+func (s *service) Read(ctx context.Context, req *dto.RequestWithID) (*dto.PostResponse, error) {
 	pid, err := apputils.StringToUINT64(req.ID)
 	if err != nil {
 		return nil, errorutils.New(errorutils.ErrInvalidID, err)
@@ -58,11 +180,104 @@ func (s *service) Read(req *dto.RequestWithID) (*dto.PostResponse, error) {
 		return nil, err
 	}
 
+	return s.visible(ctx, p)
+}
+
+// ReadBySlug also resolves slugs the post used to have; the response then
+// carries the current slug so the caller can redirect.
+func (s *service) ReadBySlug(ctx context.Context, req *dto.PostSlugRequest) (*dto.PostResponse, error) {
+	p, err := s.repository.ReadBySlug(req.Slug)
+	if err != nil {
+		if p, err = s.repository.ReadByOldSlug(req.Slug); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.visible(ctx, p)
+}
+
+// visible hides posts that aren't published yet or anymore from those who may not read them.
+func (s *service) visible(ctx context.Context, p *model.Post) (*dto.PostResponse, error) {
+	if p.State != types.Published && !s.rbac.Can(ctx, types.PostReadUnpublished) {
+		return nil, errorutils.New(errorutils.ErrPostNotFound, nil)
+	}
+
 	return p.ToDTO(), nil
 }
 
-func (s *service) Reads(p *pagination.Pageable) ([]*dto.PostResponse, error) {
-	posts, err := s.repository.Reads(p)
+// uniqueSlug derives a slug from title, adding a numeric suffix until it no
+// longer collides with another post's current or old slug.
+func (s *service) uniqueSlug(title string, exceptID uint64) (string, error) {
+	base := slug.Make(title)
+	if base == "" {
+		base = "post"
+	}
+
+	for n := 1; ; n++ {
+		candidate := slug.WithSuffix(base, n)
+
+		taken, err := s.repository.SlugTaken(candidate, exceptID)
+		if err != nil {
+			return "", err
+		}
+
+		if !taken {
+			return candidate, nil
+		}
+	}
+}
+
+func (s *service) Reads(ctx context.Context, p *pagination.Pageable, req *dto.PostReadsRequest) ([]*dto.PostResponse, error) {
+	f, err := s.postFilter(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.reads(p, *f)
+}
+
+func (s *service) ReadsByUserID(ctx context.Context, p *pagination.Pageable, req *dto.ByUserIDRequest) ([]*dto.PostResponse, error) {
+	uid, err := apputils.StringToUINT64(req.UserID)
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrInvalidID, err)
+	}
+
+	f, err := s.postFilter(ctx, &req.PostReadsRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	f.UserID = *uid
+
+	return s.reads(p, *f)
+}
+
+// postFilter builds the list filter; those who may not read unpublished posts only see published ones.
+func (s *service) postFilter(ctx context.Context, req *dto.PostReadsRequest) (*repository.PostFilter, error) {
+	if req.IncludeDeleted && !s.rbac.Can(ctx, types.PostDelete) {
+		return nil, errorutils.New(errorutils.ErrUnauthorized, nil)
+	}
+
+	var f repository.PostFilter
+
+	f.IncludeDeleted = req.IncludeDeleted
+	f.State = types.PostState(req.State)
+	f.Tag = req.Tag
+	f.Category = req.Category
+
+	if !s.rbac.Can(ctx, types.PostReadUnpublished) {
+		if f.State != "" && f.State != types.Published {
+			return nil, errorutils.New(errorutils.ErrUnauthorized, nil)
+		}
+
+		f.State = types.Published
+	}
+
+	return &f, nil
+}
+
+func (s *service) reads(p *pagination.Pageable, f repository.PostFilter) ([]*dto.PostResponse, error) {
+	posts, err := s.repository.Reads(p, f)
 	if err != nil {
 		return nil, err
 	}
@@ -76,7 +291,12 @@ func (s *service) Reads(p *pagination.Pageable) ([]*dto.PostResponse, error) {
 	return psr, nil
 }
 
-func (s *service) Update(req *dto.PostUpdateRequest) (*dto.PostResponse, error) {
+func (s *service) Update(ctx context.Context, req *dto.PostUpdateRequest) (*dto.PostResponse, error) {
+	actor, err := appcontext.MtsBlogActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	uid, err := apputils.StringToUINT64(req.ID)
 	if err != nil {
 		return nil, errorutils.New(errorutils.ErrInvalidID, err)
@@ -87,38 +307,336 @@ func (s *service) Update(req *dto.PostUpdateRequest) (*dto.PostResponse, error)
 		return nil, err
 	}
 
-	if p.Title == req.Title && req.Body == "" {
+	// Authors may edit their own posts; editing others' takes post:update-any.
+	if _, err = s.rbac.CheckOwnerOrPermission(ctx, p.UserID, types.PostUpdateAny); err != nil {
+		return nil, err
+	}
+
+	if err = etag.Check(req.IfMatch, etag.Make(p.ID, p.UpdatedAt)); err != nil {
+		return nil, err
+	}
+
+	if p.Title == req.Title && req.Body == "" && req.Slug == "" && req.Tags == nil && req.Categories == nil {
 		return nil, nil
 	}
 
+	// Tags and categories are resolved up front so unknown ones reject the whole update.
+	tagIDs, err := s.tagIDs(req.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	categoryIDs, err := s.categoryIDs(req.Categories)
+	if err != nil {
+		return nil, err
+	}
+
+	oldSlug := p.Slug
+
+	if req.Slug != "" {
+		if p.Slug = slug.Make(req.Slug); p.Slug == "" {
+			return nil, errorutils.New(errorutils.ErrPostSlug, nil)
+		}
+
+		if p.Slug != oldSlug {
+			taken, err := s.repository.SlugTaken(p.Slug, p.ID)
+			if err != nil {
+				return nil, err
+			}
+
+			if taken {
+				return nil, errorutils.New(errorutils.ErrPostSlugUsed, nil)
+			}
+		}
+	}
+
+	now := time.Now()
+	previous := *p
+
 	if req.Body != "" {
 		p.Body = req.Body
 	}
 
 	p.Title = req.Title
-	p.UpdatedAt = time.Now()
+	p.UpdatedAt = now
+	p.UpdatedBy = actor
+
+	change := repository.PostChange{OldSlug: oldSlug}
 
-	if err = s.repository.Update(p); err != nil {
+	if p.Title != previous.Title || p.Body != previous.Body {
+		change.Revision = snapshot(&previous, actor, now)
+	}
+
+	if req.Tags != nil {
+		change.Tags = &tagIDs
+	}
+
+	if req.Categories != nil {
+		change.Categories = &categoryIDs
+	}
+
+	if err = s.repository.Update(p, previous.UpdatedAt, change); err != nil {
 		return nil, err
 	}
 
+	if req.Tags != nil || req.Categories != nil {
+		// Re-read so the response carries the tag and category names.
+		if p, err = s.repository.Read(p.ID); err != nil {
+			return nil, err
+		}
+	}
+
 	return p.ToDTO(), nil
 }
 
-func (s *service) Delete(req *dto.RequestWithID) (*dto.ResponseWithID, error) {
+func (s *service) Delete(ctx context.Context, req *dto.DeleteRequest) (*dto.ResponseWithID, error) {
+	actor, err := appcontext.MtsBlogActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	uid, err := apputils.StringToUINT64(req.ID)
 	if err != nil {
 		return nil, errorutils.New(errorutils.ErrInvalidID, err)
 	}
 
-	_, err = s.repository.Read(*uid)
+	p, err := s.repository.Read(*uid)
 	if err != nil {
 		return nil, err
 	}
 
-	if err = s.repository.Delete(*uid); err != nil {
+	if err = etag.Check(req.IfMatch, etag.Make(p.ID, p.UpdatedAt)); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	p.DeletedAt = &now
+	p.DeletedBy = actor
+
+	if err = s.repository.Delete(p, p.UpdatedAt); err != nil {
 		return nil, err
 	}
 
 	return &dto.ResponseWithID{ID: req.ID}, nil
 }
+
+func (s *service) Restore(ctx context.Context, req *dto.RequestWithID) (*dto.ResponseWithID, error) {
+	actor, err := appcontext.MtsBlogActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pid, err := apputils.StringToUINT64(req.ID)
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrInvalidID, err)
+	}
+
+	var p model.Post
+
+	p.ID = *pid
+	p.UpdatedAt = time.Now()
+	p.UpdatedBy = actor
+
+	if err = s.repository.Restore(&p); err != nil {
+		return nil, err
+	}
+
+	return &dto.ResponseWithID{ID: req.ID}, nil
+}
+
+func (s *service) Purge(req *dto.RequestWithID) (*dto.ResponseWithID, error) {
+	pid, err := apputils.StringToUINT64(req.ID)
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrInvalidID, err)
+	}
+
+	if err = s.repository.Purge(*pid); err != nil {
+		return nil, err
+	}
+
+	return &dto.ResponseWithID{ID: req.ID}, nil
+}
+
+func (s *service) Publish(ctx context.Context, req *dto.PostPublishRequest) (*dto.PostResponse, error) {
+	state := types.Published
+	if req.PublishAt != nil && req.PublishAt.After(time.Now()) {
+		state = types.Scheduled
+	}
+
+	return s.setState(ctx, req.ID, state, req.PublishAt)
+}
+
+func (s *service) Unpublish(ctx context.Context, req *dto.RequestWithID) (*dto.PostResponse, error) {
+	return s.setState(ctx, req.ID, types.Draft, nil)
+}
+
+func (s *service) Archive(ctx context.Context, req *dto.RequestWithID) (*dto.PostResponse, error) {
+	return s.setState(ctx, req.ID, types.Archived, nil)
+}
+
+func (s *service) setState(ctx context.Context, id string, state types.PostState, publishAt *time.Time) (*dto.PostResponse, error) {
+	actor, err := appcontext.MtsBlogActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pid, err := apputils.StringToUINT64(id)
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrInvalidID, err)
+	}
+
+	p, err := s.repository.Read(*pid)
+	if err != nil {
+		return nil, err
+	}
+
+	// Authors may publish their own posts; publishing others' takes post:update-any.
+	if _, err = s.rbac.CheckOwnerOrPermission(ctx, p.UserID, types.PostUpdateAny); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	version := p.UpdatedAt
+
+	if err = p.SetState(state, publishAt, now); err != nil {
+		return nil, err
+	}
+
+	p.UpdatedAt = now
+	p.UpdatedBy = actor
+
+	if err = s.repository.Update(p, version, repository.PostChange{}); err != nil {
+		return nil, err
+	}
+
+	return p.ToDTO(), nil
+}
+
+// snapshot returns p's current title and body as its next revision, made by actor.
+func snapshot(p *model.Post, actor string, at time.Time) *model.PostRevision {
+	return &model.PostRevision{
+		PostID:    p.ID,
+		Title:     p.Title,
+		Body:      p.Body,
+		CreatedAt: at,
+		CreatedBy: actor,
+	}
+}
+
+// editable reads the post with the given id if the caller may edit it.
+func (s *service) editable(ctx context.Context, id string) (*model.Post, error) {
+	pid, err := apputils.StringToUINT64(id)
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrInvalidID, err)
+	}
+
+	p, err := s.repository.Read(*pid)
+	if err != nil {
+		return nil, err
+	}
+
+	// Authors may edit their own posts; editing others' takes post:update-any.
+	if _, err = s.rbac.CheckOwnerOrPermission(ctx, p.UserID, types.PostUpdateAny); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (s *service) Revisions(ctx context.Context, req *dto.RequestWithID) ([]*dto.PostRevisionResponse, error) {
+	p, err := s.editable(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.repository.ReadRevisions(p.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*dto.PostRevisionResponse, 0, len(revisions))
+	for _, rv := range revisions {
+		res = append(res, rv.ToDTO())
+	}
+
+	return res, nil
+}
+
+// Diff returns the unified diff from one revision to another, or to the current version.
+func (s *service) Diff(ctx context.Context, req *dto.PostDiffRequest) (*dto.PostDiffResponse, error) {
+	p, err := s.editable(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := s.repository.ReadRevision(p.ID, req.From)
+	if err != nil {
+		return nil, err
+	}
+
+	toName, toTitle, toBody := "current", p.Title, p.Body
+
+	if req.To != 0 {
+		to, err := s.repository.ReadRevision(p.ID, req.To)
+		if err != nil {
+			return nil, err
+		}
+
+		toName, toTitle, toBody = "revision/"+strconv.Itoa(to.Number), to.Title, to.Body
+	}
+
+	d, err := diff.Unified("revision/"+strconv.Itoa(from.Number), toName, document(from.Title, from.Body), document(toTitle, toBody))
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrPostDiffTooLong, err)
+	}
+
+	return &dto.PostDiffResponse{
+		From: req.From,
+		To:   req.To,
+		Diff: d,
+	}, nil
+}
+
+// document renders a post version as the text revisions are diffed on.
+func document(title, body string) string {
+	return title + "\n\n" + body
+}
+
+// RestoreRevision rolls the post back to a revision; the version it replaces
+// becomes a revision itself, so a restore can be undone.
+func (s *service) RestoreRevision(ctx context.Context, req *dto.PostRevisionRequest) (*dto.PostResponse, error) {
+	actor, err := appcontext.MtsBlogActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := s.editable(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	rv, err := s.repository.ReadRevision(p.ID, req.Rev)
+	if err != nil {
+		return nil, err
+	}
+
+	if rv.Title == p.Title && rv.Body == p.Body {
+		return p.ToDTO(), nil
+	}
+
+	now := time.Now()
+	previous := *p
+
+	p.Title = rv.Title
+	p.Body = rv.Body
+	p.UpdatedAt = now
+	p.UpdatedBy = actor
+
+	change := repository.PostChange{Revision: snapshot(&previous, actor, now)}
+
+	if err = s.repository.Update(p, previous.UpdatedAt, change); err != nil {
+		return nil, err
+	}
+
+	return p.ToDTO(), nil
+}
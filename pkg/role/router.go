@@ -0,0 +1,29 @@
+package role
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/rbac"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/repository"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+)
+
+type Router struct {
+	Authenticate   echo.MiddlewareFunc
+	RBAC           rbac.RBAC
+	RouterGroup    *echo.Group
+	RoleRepository repository.Role
+}
+
+func (r *Router) New() {
+	rs := NewService(r.RBAC, r.RoleRepository)
+	rh := NewHandler(rs)
+
+	rgr := r.RouterGroup.Group("/roles", r.Authenticate, r.RBAC.RequirePermission(types.RoleManage))
+
+	rgr.POST("", rh.Create())
+	rgr.GET("/:name", rh.Read())
+	rgr.GET("", rh.Reads())
+	rgr.PUT("/:name", rh.Update())
+	rgr.DELETE("/:name", rh.Delete())
+}
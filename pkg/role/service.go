@@ -0,0 +1,153 @@
+package role
+
+import (
+	"context"
+	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/appcontext"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/rbac"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/repository"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/slug"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
+)
+
+type Service interface {
+	Create(context.Context, *dto.RoleCreateRequest) (*dto.RoleResponse, error)
+	Read(*dto.RoleRequest) (*dto.RoleResponse, error)
+	Reads() ([]*dto.RoleResponse, error)
+	Update(context.Context, *dto.RoleUpdateRequest) (*dto.RoleResponse, error)
+	Delete(*dto.RoleRequest) (*dto.ResponseWithID, error)
+}
+
+type service struct {
+	rbac       rbac.RBAC
+	repository repository.Role
+}
+
+func NewService(rbac rbac.RBAC, repository repository.Role) Service {
+	return &service{
+		rbac:       rbac,
+		repository: repository,
+	}
+}
+
+// Create adds a role; its name must be a slug. Callers can only give it
+// permissions their own role has.
+func (s *service) Create(ctx context.Context, req *dto.RoleCreateRequest) (*dto.RoleResponse, error) {
+	actor, err := appcontext.MtsBlogActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.rbac.CanGrantPermissions(ctx, req.Permissions); err != nil {
+		return nil, err
+	}
+
+	if slug.Make(string(req.Name)) != string(req.Name) {
+		return nil, errorutils.New(errorutils.ErrBadRequest, nil)
+	}
+
+	r := model.Role{Name: req.Name, Permissions: dedupe(req.Permissions), CreatedBy: actor, UpdatedBy: actor}
+	r.CreatedAt = time.Now()
+	r.UpdatedAt = r.CreatedAt
+
+	if err = s.repository.Create(&r); err != nil {
+		return nil, err
+	}
+
+	return r.ToDTO(), nil
+}
+
+func (s *service) Read(req *dto.RoleRequest) (*dto.RoleResponse, error) {
+	r, err := s.repository.Read(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.ToDTO(), nil
+}
+
+func (s *service) Reads() ([]*dto.RoleResponse, error) {
+	roles, err := s.repository.Reads()
+	if err != nil {
+		return nil, err
+	}
+
+	var rrs []*dto.RoleResponse
+
+	for _, r := range *roles {
+		rrs = append(rrs, r.ToDTO())
+	}
+
+	return rrs, nil
+}
+
+// Update replaces the permissions of a role. Admins keep every permission, so
+// someone is always left who can manage roles. Callers can only edit roles
+// their own covers, before and after the edit.
+func (s *service) Update(ctx context.Context, req *dto.RoleUpdateRequest) (*dto.RoleResponse, error) {
+	actor, err := appcontext.MtsBlogActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name == types.Admin {
+		return nil, errorutils.New(errorutils.ErrRoleBuiltIn, nil)
+	}
+
+	r, err := s.repository.Read(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, perms := range [][]types.Permission{r.Permissions, req.Permissions} {
+		if err = s.rbac.CanGrantPermissions(ctx, perms); err != nil {
+			return nil, err
+		}
+	}
+
+	r.Permissions = dedupe(req.Permissions)
+	r.UpdatedAt = time.Now()
+	r.UpdatedBy = actor
+
+	if err = s.repository.Update(r); err != nil {
+		return nil, err
+	}
+
+	s.rbac.Reload()
+
+	return r.ToDTO(), nil
+}
+
+// Delete removes a role no user has. The built-in roles stay.
+func (s *service) Delete(req *dto.RoleRequest) (*dto.ResponseWithID, error) {
+	if _, builtIn := rbac.DefaultRoles[req.Name]; builtIn {
+		return nil, errorutils.New(errorutils.ErrRoleBuiltIn, nil)
+	}
+
+	if err := s.repository.Delete(req.Name); err != nil {
+		return nil, err
+	}
+
+	s.rbac.Reload()
+
+	return &dto.ResponseWithID{ID: string(req.Name)}, nil
+}
+
+// dedupe drops repeated permissions, keeping the first of each.
+func dedupe(perms []types.Permission) []types.Permission {
+	seen := make(map[types.Permission]bool, len(perms))
+	out := make([]types.Permission, 0, len(perms))
+
+	for _, p := range perms {
+		if !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,51 @@
+package search
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/pagination"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/echoutils"
+)
+
+type Handler interface {
+	Search() echo.HandlerFunc
+}
+
+type handler struct {
+	service Service
+}
+
+func NewHandler(service Service) Handler {
+	return &handler{
+		service: service,
+	}
+}
+
+func (h *handler) Search() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		// Best matches first unless the client asks for another order.
+		p := pagination.NewPagination()
+		p.Sort = "rank,desc"
+
+		if err := echoutils.BindAndValidate(c, p); err != nil {
+			return err
+		}
+
+		r := new(dto.SearchRequest)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		res, err := h.service.Search(p, r)
+		if err != nil {
+			return err
+		}
+
+		p.PaginationHeader(c)
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
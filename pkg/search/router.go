@@ -0,0 +1,19 @@
+package search
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/repository"
+)
+
+type Router struct {
+	RouterGroup      *echo.Group
+	SearchRepository repository.Search
+}
+
+func (r *Router) New() {
+	ss := NewService(r.SearchRepository)
+	sh := NewHandler(ss)
+
+	r.RouterGroup.GET("/search", sh.Search())
+}
@@ -0,0 +1,70 @@
+package search
+
+import (
+	"strings"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/repository"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/pagination"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
+)
+
+type Service interface {
+	Search(*pagination.Pageable, *dto.SearchRequest) ([]*dto.SearchResultResponse, error)
+}
+
+type service struct {
+	repository repository.Search
+}
+
+func NewService(repository repository.Search) Service {
+	return &service{
+		repository: repository,
+	}
+}
+
+func (s *service) Search(p *pagination.Pageable, req *dto.SearchRequest) ([]*dto.SearchResultResponse, error) {
+	st, err := searchTypes(req.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := s.repository.Search(p, repository.SearchFilter{Query: req.Q, Types: st})
+	if err != nil {
+		return nil, err
+	}
+
+	var srs []*dto.SearchResultResponse
+
+	for _, r := range *results {
+		srs = append(srs, r.ToDTO())
+	}
+
+	return srs, nil
+}
+
+// searchTypes parses the comma separated type query; empty means every type.
+func searchTypes(raw string) ([]types.SearchType, error) {
+	if raw == "" {
+		return []types.SearchType{types.SearchPost, types.SearchComment}, nil
+	}
+
+	var st []types.SearchType
+
+	seen := make(map[types.SearchType]bool)
+
+	for _, part := range strings.Split(raw, ",") {
+		t := types.SearchType(strings.TrimSpace(part))
+		if t != types.SearchPost && t != types.SearchComment {
+			return nil, errorutils.New(errorutils.ErrBadRequest, nil)
+		}
+
+		if !seen[t] {
+			seen[t] = true
+			st = append(st, t)
+		}
+	}
+
+	return st, nil
+}
@@ -0,0 +1,29 @@
+package tag
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/rbac"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/repository"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+)
+
+type Router struct {
+	Authenticate  echo.MiddlewareFunc
+	RBAC          rbac.RBAC
+	RouterGroup   *echo.Group
+	TagRepository repository.Tag
+}
+
+func (r *Router) New() {
+	ts := NewService(r.TagRepository)
+	th := NewHandler(ts)
+
+	tgr := r.RouterGroup.Group("/tags")
+
+	tgr.POST("", th.Create(), r.Authenticate, r.RBAC.RequirePermission(types.TagManage))
+	tgr.GET("/:id", th.Read())
+	tgr.GET("", th.Reads())
+	tgr.PUT("/:id", th.Update(), r.Authenticate, r.RBAC.RequirePermission(types.TagManage))
+	tgr.DELETE("/:id", th.Delete(), r.Authenticate, r.RBAC.RequirePermission(types.TagManage))
+}
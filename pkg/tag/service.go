@@ -0,0 +1,131 @@
+package tag
+
+import (
+	"context"
+	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/appcontext"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/repository"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/pagination"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/slug"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/apputils"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
+)
+
+type Service interface {
+	Create(context.Context, *dto.TagCreateRequest) (*dto.TagResponse, error)
+	Read(*dto.RequestWithID) (*dto.TagResponse, error)
+	Reads(*pagination.Pageable) ([]*dto.TagResponse, error)
+	Update(context.Context, *dto.TagUpdateRequest) (*dto.TagResponse, error)
+	Delete(*dto.RequestWithID) (*dto.ResponseWithID, error)
+}
+
+type service struct {
+	repository repository.Tag
+}
+
+func NewService(repository repository.Tag) Service {
+	return &service{
+		repository: repository,
+	}
+}
+
+func (s *service) Create(ctx context.Context, req *dto.TagCreateRequest) (*dto.TagResponse, error) {
+	actor, err := appcontext.MtsBlogActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var t model.Tag
+
+	if t.Slug = slug.Make(req.Name); t.Slug == "" {
+		return nil, errorutils.New(errorutils.ErrBadRequest, nil)
+	}
+
+	t.Name = req.Name
+	t.CreatedAt = time.Now()
+	t.CreatedBy = actor
+	t.UpdatedAt = t.CreatedAt
+	t.UpdatedBy = actor
+
+	if err = s.repository.Create(&t); err != nil {
+		return nil, err
+	}
+
+	return t.ToDTO(), nil
+}
+
+func (s *service) Read(req *dto.RequestWithID) (*dto.TagResponse, error) {
+	tid, err := apputils.StringToUINT64(req.ID)
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrInvalidID, err)
+	}
+
+	t, err := s.repository.Read(*tid)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.ToDTO(), nil
+}
+
+func (s *service) Reads(p *pagination.Pageable) ([]*dto.TagResponse, error) {
+	tags, err := s.repository.Reads(p)
+	if err != nil {
+		return nil, err
+	}
+
+	var trs []*dto.TagResponse
+
+	for _, t := range *tags {
+		trs = append(trs, t.ToDTO())
+	}
+
+	return trs, nil
+}
+
+func (s *service) Update(ctx context.Context, req *dto.TagUpdateRequest) (*dto.TagResponse, error) {
+	actor, err := appcontext.MtsBlogActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tid, err := apputils.StringToUINT64(req.ID)
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrInvalidID, err)
+	}
+
+	t, err := s.repository.Read(*tid)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.Slug = slug.Make(req.Name); t.Slug == "" {
+		return nil, errorutils.New(errorutils.ErrBadRequest, nil)
+	}
+
+	t.Name = req.Name
+	t.UpdatedAt = time.Now()
+	t.UpdatedBy = actor
+
+	if err = s.repository.Update(t); err != nil {
+		return nil, err
+	}
+
+	return t.ToDTO(), nil
+}
+
+func (s *service) Delete(req *dto.RequestWithID) (*dto.ResponseWithID, error) {
+	tid, err := apputils.StringToUINT64(req.ID)
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrInvalidID, err)
+	}
+
+	if err = s.repository.Delete(*tid); err != nil {
+		return nil, err
+	}
+
+	return &dto.ResponseWithID{ID: req.ID}, nil
+}
@@ -0,0 +1,83 @@
+package user
+
+import (
+	"context"
+	"time"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/appcontext"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/apikey"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/apputils"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
+)
+
+// CreateAPIKey creates an api key for the caller. The key is returned once and
+// only its hash is kept.
+func (s *service) CreateAPIKey(ctx context.Context, req *dto.APIKeyCreateRequest) (*dto.APIKeyCreateResponse, error) {
+	claims, err := appcontext.MtsBlogUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !req.ExpiresAt.After(now) {
+		return nil, errorutils.New(errorutils.ErrBadRequest, nil)
+	}
+
+	key, prefix, err := apikey.Generate()
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrUnexpected, err)
+	}
+
+	k := &model.APIKey{
+		UserID:    claims.UID,
+		Name:      req.Name,
+		Prefix:    prefix,
+		KeyHash:   apputils.HashToken(key),
+		Scopes:    req.Scopes,
+		CreatedAt: now,
+		ExpiresAt: req.ExpiresAt,
+	}
+
+	if err = s.apiKeyRepository.Create(k); err != nil {
+		return nil, err
+	}
+
+	return &dto.APIKeyCreateResponse{APIKeyResponse: *k.ToDTO(), Key: key}, nil
+}
+
+// ReadAPIKeys returns the api keys of the caller that are not revoked.
+func (s *service) ReadAPIKeys(ctx context.Context) ([]*dto.APIKeyResponse, error) {
+	claims, err := appcontext.MtsBlogUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := s.apiKeyRepository.ReadsByUserID(claims.UID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*dto.APIKeyResponse, 0, len(*keys))
+	for i := range *keys {
+		res = append(res, (*keys)[i].ToDTO())
+	}
+
+	return res, nil
+}
+
+// RevokeAPIKey revokes an api key of the caller.
+func (s *service) RevokeAPIKey(ctx context.Context, req *dto.RequestWithID) error {
+	claims, err := appcontext.MtsBlogUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	id, err := apputils.StringToUINT64(req.ID)
+	if err != nil {
+		return errorutils.New(errorutils.ErrInvalidID, err)
+	}
+
+	return s.apiKeyRepository.Revoke(*id, claims.UID, time.Now())
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/labstack/echo/v4"
 
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/etag"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/pagination"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/echoutils"
 )
@@ -16,6 +17,18 @@ type Handler interface {
 	Reads() echo.HandlerFunc
 	Update() echo.HandlerFunc
 	Delete() echo.HandlerFunc
+	Restore() echo.HandlerFunc
+	Purge() echo.HandlerFunc
+	ChangePassword() echo.HandlerFunc
+	ChangeEmail() echo.HandlerFunc
+	ReadMe() echo.HandlerFunc
+	UpdateMe() echo.HandlerFunc
+	DeleteMe() echo.HandlerFunc
+	UpdateRole() echo.HandlerFunc
+	UpdateStatus() echo.HandlerFunc
+	CreateAPIKey() echo.HandlerFunc
+	ReadAPIKeys() echo.HandlerFunc
+	RevokeAPIKey() echo.HandlerFunc
 }
 
 type handler struct {
@@ -35,7 +48,7 @@ func (h *handler) Create() echo.HandlerFunc {
 			return err
 		}
 
-		err := h.service.Create(r)
+		err := h.service.Create(c.Request().Context(), r)
 		if err != nil {
 			return err
 		}
@@ -56,7 +69,7 @@ func (h *handler) Read() echo.HandlerFunc {
 			return err
 		}
 
-		return c.JSON(http.StatusOK, u)
+		return echoutils.JSONWithETag(c, http.StatusOK, etag.Make(u.ID, u.UpdatedAt), u)
 	}
 }
 
@@ -67,7 +80,12 @@ func (h *handler) Reads() echo.HandlerFunc {
 			return err
 		}
 
-		res, err := h.service.Reads(p)
+		r := new(dto.ReadsRequest)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		res, err := h.service.Reads(c.Request().Context(), p, r)
 		if err != nil {
 			return err
 		}
@@ -85,27 +103,227 @@ func (h *handler) Update() echo.HandlerFunc {
 			return err
 		}
 
+		r.IfMatch = echoutils.IfMatch(c)
+
 		res, err := h.service.Update(c.Request().Context(), r)
 		if err != nil {
 			return err
 		}
 
+		c.Response().Header().Set(etag.HeaderETag, etag.Make(res.ID, res.UpdatedAt))
+
 		return c.JSON(http.StatusOK, res)
 	}
 }
 
 func (h *handler) Delete() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := new(dto.DeleteRequest)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		r.IfMatch = echoutils.IfMatch(c)
+
+		res, err := h.service.Delete(c.Request().Context(), r)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+func (h *handler) Restore() echo.HandlerFunc {
 	return func(c echo.Context) error {
 		r := new(dto.RequestWithID)
 		if err := echoutils.BindAndValidate(c, r); err != nil {
 			return err
 		}
 
-		res, err := h.service.Delete(r)
+		res, err := h.service.Restore(c.Request().Context(), r)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+func (h *handler) Purge() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := new(dto.RequestWithID)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		res, err := h.service.Purge(c.Request().Context(), r)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+func (h *handler) ChangePassword() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := new(dto.ChangePasswordRequest)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		r.IP = c.RealIP()
+
+		if err := h.service.ChangePassword(c.Request().Context(), r); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+func (h *handler) ChangeEmail() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := new(dto.ChangeEmailRequest)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		r.IP = c.RealIP()
+
+		res, err := h.service.ChangeEmail(c.Request().Context(), r)
 		if err != nil {
 			return err
 		}
 
+		c.Response().Header().Set(etag.HeaderETag, etag.Make(res.ID, res.UpdatedAt))
+
 		return c.JSON(http.StatusOK, res)
 	}
 }
+
+func (h *handler) ReadMe() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		res, err := h.service.ReadMe(c.Request().Context())
+		if err != nil {
+			return err
+		}
+
+		return echoutils.JSONWithETag(c, http.StatusOK, etag.Make(res.ID, res.UpdatedAt), res)
+	}
+}
+
+func (h *handler) UpdateMe() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := new(dto.MeUpdateRequest)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		r.IfMatch = echoutils.IfMatch(c)
+
+		res, err := h.service.UpdateMe(c.Request().Context(), r)
+		if err != nil {
+			return err
+		}
+
+		c.Response().Header().Set(etag.HeaderETag, etag.Make(res.ID, res.UpdatedAt))
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+func (h *handler) DeleteMe() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := &dto.Precondition{IfMatch: echoutils.IfMatch(c)}
+
+		if err := h.service.DeleteMe(c.Request().Context(), r); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+func (h *handler) UpdateRole() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := new(dto.UserRoleRequest)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		r.IfMatch = echoutils.IfMatch(c)
+
+		res, err := h.service.UpdateRole(c.Request().Context(), r)
+		if err != nil {
+			return err
+		}
+
+		c.Response().Header().Set(etag.HeaderETag, etag.Make(res.ID, res.UpdatedAt))
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+func (h *handler) UpdateStatus() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := new(dto.UserStatusRequest)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		r.IfMatch = echoutils.IfMatch(c)
+
+		res, err := h.service.UpdateStatus(c.Request().Context(), r)
+		if err != nil {
+			return err
+		}
+
+		c.Response().Header().Set(etag.HeaderETag, etag.Make(res.ID, res.UpdatedAt))
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+func (h *handler) CreateAPIKey() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := new(dto.APIKeyCreateRequest)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		res, err := h.service.CreateAPIKey(c.Request().Context(), r)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusCreated, res)
+	}
+}
+
+func (h *handler) ReadAPIKeys() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		res, err := h.service.ReadAPIKeys(c.Request().Context())
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+func (h *handler) RevokeAPIKey() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		r := new(dto.RequestWithID)
+		if err := echoutils.BindAndValidate(c, r); err != nil {
+			return err
+		}
+
+		if err := h.service.RevokeAPIKey(c.Request().Context(), r); err != nil {
+			return err
+		}
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
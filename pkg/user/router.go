@@ -6,24 +6,43 @@ import (
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/rbac"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/repository"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
+	"github.com/MehmetTalhaSeker/mts-blog-api/pkg/auth"
 )
 
 type Router struct {
-	Authenticate   echo.MiddlewareFunc
-	RBAC           rbac.RBAC
-	RouterGroup    *echo.Group
-	UserRepository repository.User
+	Authenticate      echo.MiddlewareFunc
+	RBAC              rbac.RBAC
+	RouterGroup       *echo.Group
+	UserRepository    repository.User
+	SessionRepository repository.Session
+	APIKeyRepository  repository.APIKey
+	// Verifier mails the link to confirm a changed email.
+	Verifier auth.Verifier
+	// Lockout counts wrong passwords on password and email changes.
+	Lockout auth.Lockout
 }
 
 func (r *Router) New() {
-	us := NewService(r.RBAC, r.UserRepository)
+	us := NewService(r.RBAC, r.UserRepository, r.SessionRepository, r.APIKeyRepository, r.Verifier, r.Lockout)
 	uh := NewHandler(us)
 
 	ugr := r.RouterGroup.Group("/users", r.Authenticate)
 
-	ugr.POST("", uh.Create(), r.RBAC.HasRole(types.Admin))
-	ugr.GET("/:id", uh.Read(), r.RBAC.HasRole(types.Mod))
-	ugr.GET("", uh.Reads(), r.RBAC.HasRole(types.Mod))
-	ugr.PUT("/:id", uh.Update(), r.RBAC.HasRole(types.Registered))
-	ugr.DELETE("/:id", uh.Delete(), r.RBAC.HasRole(types.Admin))
+	ugr.POST("", uh.Create(), r.RBAC.RequirePermission(types.UserManage))
+	ugr.GET("/me", uh.ReadMe(), r.RBAC.SignedIn())
+	ugr.PUT("/me", uh.UpdateMe(), r.RBAC.SignedIn())
+	ugr.DELETE("/me", uh.DeleteMe(), r.RBAC.SignedIn())
+	ugr.PUT("/me/password", uh.ChangePassword(), r.RBAC.SignedIn())
+	ugr.PUT("/me/email", uh.ChangeEmail(), r.RBAC.SignedIn())
+	ugr.POST("/me/api-keys", uh.CreateAPIKey(), r.RBAC.SignedIn())
+	ugr.GET("/me/api-keys", uh.ReadAPIKeys(), r.RBAC.SignedIn())
+	ugr.DELETE("/me/api-keys/:id", uh.RevokeAPIKey(), r.RBAC.SignedIn())
+	ugr.GET("/:id", uh.Read(), r.RBAC.RequirePermission(types.UserRead))
+	ugr.GET("", uh.Reads(), r.RBAC.RequirePermission(types.UserRead))
+	ugr.PUT("/:id", uh.Update(), r.RBAC.SignedIn())
+	ugr.DELETE("/:id", uh.Delete(), r.RBAC.RequirePermission(types.UserManage))
+	ugr.PUT("/:id/role", uh.UpdateRole(), r.RBAC.RequirePermission(types.UserManage))
+	ugr.PUT("/:id/status", uh.UpdateStatus(), r.RBAC.RequirePermission(types.UserManage))
+	ugr.POST("/:id/restore", uh.Restore(), r.RBAC.RequirePermission(types.UserManage))
+	ugr.DELETE("/:id/purge", uh.Purge(), r.RBAC.RequirePermission(types.UserManage))
 }
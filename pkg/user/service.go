@@ -2,39 +2,76 @@ package user
 
 import (
 	"context"
+	"log"
+	"strconv"
+	"strings"
 	"time"
 
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/appcontext"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/dto"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/model"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/rbac"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/repository"
+	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/etag"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/shared/pagination"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/types"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/apputils"
 	"github.com/MehmetTalhaSeker/mts-blog-api/internal/utils/errorutils"
+	"github.com/MehmetTalhaSeker/mts-blog-api/pkg/auth"
 )
 
 type Service interface {
-	Create(*dto.UserCreateRequest) error
+	Create(context.Context, *dto.UserCreateRequest) error
 	Read(*dto.RequestWithID) (*dto.UserResponse, error)
-	Reads(*pagination.Pageable) ([]*dto.UserResponse, error)
+	Reads(context.Context, *pagination.Pageable, *dto.ReadsRequest) ([]*dto.UserResponse, error)
 	Update(context.Context, *dto.UserUpdateRequest) (*dto.UserResponse, error)
-	Delete(*dto.RequestWithID) (*dto.ResponseWithID, error)
+	Delete(context.Context, *dto.DeleteRequest) (*dto.ResponseWithID, error)
+	Restore(context.Context, *dto.RequestWithID) (*dto.ResponseWithID, error)
+	Purge(context.Context, *dto.RequestWithID) (*dto.ResponseWithID, error)
+	ChangePassword(context.Context, *dto.ChangePasswordRequest) error
+	ChangeEmail(context.Context, *dto.ChangeEmailRequest) (*dto.UserResponse, error)
+	ReadMe(context.Context) (*dto.MeResponse, error)
+	UpdateMe(context.Context, *dto.MeUpdateRequest) (*dto.MeResponse, error)
+	DeleteMe(context.Context, *dto.Precondition) error
+	UpdateRole(context.Context, *dto.UserRoleRequest) (*dto.UserResponse, error)
+	UpdateStatus(context.Context, *dto.UserStatusRequest) (*dto.UserResponse, error)
+	CreateAPIKey(context.Context, *dto.APIKeyCreateRequest) (*dto.APIKeyCreateResponse, error)
+	ReadAPIKeys(context.Context) ([]*dto.APIKeyResponse, error)
+	RevokeAPIKey(context.Context, *dto.RequestWithID) error
 }
 
 type service struct {
-	repository repository.User
-	rbac       rbac.RBAC
+	repository        repository.User
+	sessionRepository repository.Session
+	apiKeyRepository  repository.APIKey
+	verifier          auth.Verifier
+	lockout           auth.Lockout
+	rbac              rbac.RBAC
 }
 
-func NewService(rbac rbac.RBAC, repository repository.User) Service {
+// NewService returns the user service; verifier mails the link to confirm a
+// changed email and lockout counts wrong passwords like failed logins.
+func NewService(rbac rbac.RBAC, repository repository.User, sessions repository.Session, apiKeys repository.APIKey, verifier auth.Verifier,
+	lockout auth.Lockout,
+) Service {
 	return &service{
-		repository: repository,
-		rbac:       rbac,
+		repository:        repository,
+		sessionRepository: sessions,
+		apiKeyRepository:  apiKeys,
+		verifier:          verifier,
+		lockout:           lockout,
+		rbac:              rbac,
 	}
 }
 
-func (s *service) Create(req *dto.UserCreateRequest) error {
+func (s *service) Create(ctx context.Context, req *dto.UserCreateRequest) error {
+	actor, err := appcontext.MtsBlogActor(ctx)
+	if err != nil {
+		return err
+	}
+
 	var u model.User
 
 	ep, err := apputils.EncryptPassword(req.Password)
@@ -43,11 +80,13 @@ func (s *service) Create(req *dto.UserCreateRequest) error {
 	}
 
 	u.CreatedAt = time.Now()
+	u.CreatedBy = actor
 	u.EncryptedPassword = ep
 	u.Email = req.Email
 	u.Role = types.Registered
 	u.Status = types.Active
 	u.UpdatedAt = time.Now()
+	u.UpdatedBy = actor
 	u.Username = req.Username
 
 	err = s.repository.Create(&u)
@@ -72,8 +111,12 @@ func (s *service) Read(req *dto.RequestWithID) (*dto.UserResponse, error) {
 	return u.ToDTO(), nil
 }
 
-func (s *service) Reads(p *pagination.Pageable) ([]*dto.UserResponse, error) {
-	users, err := s.repository.Reads(p)
+func (s *service) Reads(ctx context.Context, p *pagination.Pageable, req *dto.ReadsRequest) ([]*dto.UserResponse, error) {
+	if req.IncludeDeleted && !s.rbac.Can(ctx, types.UserManage) {
+		return nil, errorutils.New(errorutils.ErrUnauthorized, nil)
+	}
+
+	users, err := s.repository.Reads(p, repository.ReadsFilter{IncludeDeleted: req.IncludeDeleted})
 	if err != nil {
 		return nil, err
 	}
@@ -93,8 +136,13 @@ func (s *service) Update(ctx context.Context, req *dto.UserUpdateRequest) (*dto.
 		return nil, errorutils.New(errorutils.ErrInvalidID, err)
 	}
 
-	if !s.rbac.IsMe(ctx, *uid) && !s.rbac.IsAdminAuthorized(ctx) {
-		return nil, errorutils.New(errorutils.ErrUnauthorized, nil)
+	if _, err = s.rbac.CheckOwnerOrPermission(ctx, *uid, types.UserManage); err != nil {
+		return nil, err
+	}
+
+	actor, err := appcontext.MtsBlogActor(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	u, err := s.repository.Read(*uid)
@@ -102,30 +150,355 @@ func (s *service) Update(ctx context.Context, req *dto.UserUpdateRequest) (*dto.
 		return nil, err
 	}
 
+	if err = etag.Check(req.IfMatch, etag.Make(u.ID, u.UpdatedAt)); err != nil {
+		return nil, err
+	}
+
+	version := u.UpdatedAt
 	u.UpdatedAt = time.Now()
+	u.UpdatedBy = actor
 	u.Username = req.Username
 
-	if err = s.repository.Update(u); err != nil {
+	if err = s.repository.Update(u, version); err != nil {
 		return nil, err
 	}
 
 	return u.ToDTO(), nil
 }
 
-func (s *service) Delete(req *dto.RequestWithID) (*dto.ResponseWithID, error) {
+func (s *service) Delete(ctx context.Context, req *dto.DeleteRequest) (*dto.ResponseWithID, error) {
+	actor, err := appcontext.MtsBlogActor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	uid, err := apputils.StringToUINT64(req.ID)
 	if err != nil {
 		return nil, errorutils.New(errorutils.ErrInvalidID, err)
 	}
 
-	_, err = s.repository.Read(*uid)
+	u, err := s.repository.Read(*uid)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = etag.Check(req.IfMatch, etag.Make(u.ID, u.UpdatedAt)); err != nil {
+		return nil, err
+	}
+
+	// Acting on others takes a role covering theirs.
+	if err = s.rbac.CanGrant(ctx, u.Role); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	u.DeletedAt = &now
+	u.DeletedBy = actor
+
+	if err = s.repository.Delete(u, u.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	return &dto.ResponseWithID{ID: req.ID}, nil
+}
+
+func (s *service) Restore(ctx context.Context, req *dto.RequestWithID) (*dto.ResponseWithID, error) {
+	actor, err := appcontext.MtsBlogActor(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	if err = s.repository.Delete(*uid); err != nil {
+	uid, err := apputils.StringToUINT64(req.ID)
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrInvalidID, err)
+	}
+
+	var u model.User
+
+	u.ID = *uid
+	u.UpdatedAt = time.Now()
+	u.UpdatedBy = actor
+
+	if err = s.repository.Restore(&u); err != nil {
 		return nil, err
 	}
 
 	return &dto.ResponseWithID{ID: req.ID}, nil
 }
+
+// Purge removes a user for good. Callers can only purge users whose role
+// theirs covers.
+func (s *service) Purge(ctx context.Context, req *dto.RequestWithID) (*dto.ResponseWithID, error) {
+	uid, err := apputils.StringToUINT64(req.ID)
+	if err != nil {
+		return nil, errorutils.New(errorutils.ErrInvalidID, err)
+	}
+
+	u, err := s.repository.ReadAny(*uid)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.rbac.CanGrant(ctx, u.Role); err != nil {
+		return nil, err
+	}
+
+	if err = s.repository.Purge(*uid); err != nil {
+		return nil, err
+	}
+
+	return &dto.ResponseWithID{ID: req.ID}, nil
+}
+
+// ChangePassword sets a new password for the caller, ends their other sessions
+// and revokes their API keys.
+func (s *service) ChangePassword(ctx context.Context, req *dto.ChangePasswordRequest) error {
+	u, claims, err := s.me(ctx, req.CurrentPassword, req.IP)
+	if err != nil {
+		return err
+	}
+
+	ep, err := apputils.EncryptPassword(req.NewPassword)
+	if err != nil {
+		return errorutils.New(errorutils.ErrUnexpected, err)
+	}
+
+	now := time.Now()
+
+	u.EncryptedPassword = ep
+	u.UpdatedAt = now
+	u.UpdatedBy = strconv.FormatUint(u.ID, 10)
+
+	if err = s.repository.UpdatePassword(u); err != nil {
+		return err
+	}
+
+	if err = s.apiKeyRepository.RevokeAll(u.ID, now); err != nil {
+		return err
+	}
+
+	return s.sessionRepository.RevokeOthers(u.ID, claims.SessionID, now)
+}
+
+// ChangeEmail moves the caller to a new email, which has to be verified again,
+// and ends their other sessions.
+func (s *service) ChangeEmail(ctx context.Context, req *dto.ChangeEmailRequest) (*dto.UserResponse, error) {
+	u, claims, err := s.me(ctx, req.Password, req.IP)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Email == req.Email {
+		return u.ToDTO(), nil
+	}
+
+	now := time.Now()
+
+	u.Email = req.Email
+	u.UpdatedAt = now
+	u.UpdatedBy = strconv.FormatUint(u.ID, 10)
+
+	if err = s.repository.UpdateEmail(u); err != nil {
+		return nil, err
+	}
+
+	// The change stands without the mail; the user can ask for another one.
+	if err = s.verifier.Send(ctx, u); err != nil {
+		log.Printf("verification mail for user %d: %v", u.ID, err)
+	}
+
+	if err = s.sessionRepository.RevokeOthers(u.ID, claims.SessionID, now); err != nil {
+		return nil, err
+	}
+
+	return u.ToDTO(), nil
+}
+
+// ReadMe returns the caller with their post and comment counts.
+func (s *service) ReadMe(ctx context.Context) (*dto.MeResponse, error) {
+	claims, err := appcontext.MtsBlogUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := s.repository.Read(claims.UID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withStats(u.ToDTO())
+}
+
+// UpdateMe updates the profile of the caller.
+func (s *service) UpdateMe(ctx context.Context, req *dto.MeUpdateRequest) (*dto.MeResponse, error) {
+	claims, err := appcontext.MtsBlogUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := s.Update(ctx, &dto.UserUpdateRequest{
+		Precondition: req.Precondition,
+		ID:           strconv.FormatUint(claims.UID, 10),
+		Username:     req.Username,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withStats(u)
+}
+
+// DeleteMe deletes the account of the caller and ends all of their sessions.
+// The last active admin can not delete theirs.
+func (s *service) DeleteMe(ctx context.Context, req *dto.Precondition) error {
+	claims, err := appcontext.MtsBlogUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Delete(ctx, &dto.DeleteRequest{
+		RequestWithID: dto.RequestWithID{ID: strconv.FormatUint(claims.UID, 10)},
+		Precondition:  *req,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.sessionRepository.RevokeAll(claims.UID, time.Now())
+}
+
+// UpdateRole changes the role of a user. The last active admin keeps theirs,
+// and callers can only move users between roles their own role covers.
+func (s *service) UpdateRole(ctx context.Context, req *dto.UserRoleRequest) (*dto.UserResponse, error) {
+	u, actor, err := s.readForUpdate(ctx, req.ID, req.IfMatch)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, role := range []types.Role{u.Role, req.Role} {
+		if err = s.rbac.CanGrant(ctx, role); err != nil {
+			return nil, err
+		}
+	}
+
+	now, version := time.Now(), u.UpdatedAt
+
+	u.Role = req.Role
+	u.UpdatedAt = now
+	u.UpdatedBy = actor
+
+	if err = s.repository.UpdateRole(u, version); err != nil {
+		return nil, err
+	}
+
+	return u.ToDTO(), nil
+}
+
+// UpdateStatus suspends a user, ending their sessions, or lifts the
+// suspension. The last active admin can not be suspended, and callers can only
+// change users whose role theirs covers.
+func (s *service) UpdateStatus(ctx context.Context, req *dto.UserStatusRequest) (*dto.UserResponse, error) {
+	u, actor, err := s.readForUpdate(ctx, req.ID, req.IfMatch)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.rbac.CanGrant(ctx, u.Role); err != nil {
+		return nil, err
+	}
+
+	now, version := time.Now(), u.UpdatedAt
+
+	u.Status = req.Status
+	u.SuspendedReason = ""
+	u.SuspendedUntil = nil
+
+	if req.Status == types.Passive {
+		if req.Until != nil && !req.Until.After(now) {
+			return nil, errorutils.New(errorutils.ErrBadRequest, nil)
+		}
+
+		u.SuspendedReason = req.Reason
+		u.SuspendedUntil = req.Until
+	}
+
+	u.UpdatedAt = now
+	u.UpdatedBy = actor
+
+	if err = s.repository.UpdateStatus(u, version); err != nil {
+		return nil, err
+	}
+
+	if u.Suspended(now) {
+		if err = s.sessionRepository.RevokeAll(u.ID, now); err != nil {
+			return nil, err
+		}
+	}
+
+	return u.ToDTO(), nil
+}
+
+// readForUpdate reads the user with id for the actor of ctx, checking ifMatch.
+func (s *service) readForUpdate(ctx context.Context, id, ifMatch string) (*model.User, string, error) {
+	actor, err := appcontext.MtsBlogActor(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	uid, err := apputils.StringToUINT64(id)
+	if err != nil {
+		return nil, "", errorutils.New(errorutils.ErrInvalidID, err)
+	}
+
+	u, err := s.repository.Read(*uid)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err = etag.Check(ifMatch, etag.Make(u.ID, u.UpdatedAt)); err != nil {
+		return nil, "", err
+	}
+
+	return u, actor, nil
+}
+
+func (s *service) withStats(u *dto.UserResponse) (*dto.MeResponse, error) {
+	st, err := s.repository.ReadStats(u.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.MeResponse{UserResponse: *u, PostCount: st.Posts, CommentCount: st.Comments}, nil
+}
+
+// me returns the caller after checking password is theirs. Wrong passwords
+// count towards the lockout of the account and ip like failed logins do.
+func (s *service) me(ctx context.Context, password, ip string) (*model.User, *dto.Claims, error) {
+	claims, err := appcontext.MtsBlogUser(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	u, err := s.repository.Read(claims.UID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	account := strings.ToLower(u.Email)
+
+	if err = s.lockout.Check(account, ip, now); err != nil {
+		return nil, nil, err
+	}
+
+	if err = bcrypt.CompareHashAndPassword([]byte(u.EncryptedPassword), []byte(password)); err != nil {
+		if err := s.lockout.Fail(account, ip, now); err != nil {
+			return nil, nil, err
+		}
+
+		return nil, nil, errorutils.New(errorutils.ErrInvalidPassword, err)
+	}
+
+	return u, claims, nil
+}